@@ -3,7 +3,7 @@ export PATH=$PATH:$GOPATH/bin
 
 protoc --go_out=. --go-grpc_out=. ./server/proto/metrics.proto
 
-go build -o ./build/quanti-tea-steep ./server/main.go
+go build -o ./build/quanti-tea-steep ./server
 go build -o ./build/quanti-tea ./tui/main.go
 
 # Exit immediately if a command exits with a non-zero status
@@ -8,6 +8,8 @@ require (
 	github.com/charmbracelet/lipgloss v0.13.0
 	github.com/gin-gonic/gin v1.10.0
 	github.com/prometheus/client_golang v1.20.5
+	github.com/robfig/cron/v3 v3.0.1
+	go.etcd.io/bbolt v1.3.11
 	google.golang.org/grpc v1.67.1
 	google.golang.org/protobuf v1.34.2
 	modernc.org/sqlite v1.33.1
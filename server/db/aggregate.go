@@ -0,0 +1,297 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Fields an aggregate is computed over
+const (
+	FieldAmount  = "amount"  // Amount of each entry: the change of an increment or decrement, the new value of an update
+	FieldValue   = "value"   // Value of the metric after each entry
+	FieldClosing = "closing" // Value each archived period of the daily history closed at
+)
+
+// Time buckets an aggregate can be grouped into
+const (
+	BucketHour  = "hour"
+	BucketDay   = "day"
+	BucketWeek  = "week" // Weeks start on Monday
+	BucketMonth = "month"
+)
+
+// Groupings of an aggregate besides the time bucket
+const (
+	GroupMetric = "metric"
+	GroupType   = "type"
+	GroupTag    = "tag:" // Prefix of a grouping by the value of a tag key, e.g. "tag:person"
+)
+
+// AggregateQuery selects the history of metrics and how it is summarized
+type AggregateQuery struct {
+	MetricNames []string  // Metrics to aggregate; empty for every live metric
+	Type        string    // Only metrics of this type; empty for any
+	Tags        Tags      // Only metrics with all these tags, as in Tags.Matches
+	Start, End  time.Time // Half-open range of the history; zero leaves it open
+	Field       string    // FieldAmount, FieldValue or FieldClosing; empty for FieldAmount
+	GroupBy     []string  // GroupMetric, GroupType or GroupTag followed by a key
+	Bucket      string    // BucketHour, BucketDay, BucketWeek or BucketMonth; empty for the whole range
+	Percentiles []float64 // Percentiles to compute, from 0 to 100
+}
+
+// AggregateGroup holds the statistics of one group of an aggregate. Only the
+// keys the query groups by are set.
+type AggregateGroup struct {
+	MetricName  string
+	Type        string
+	Tags        Tags   // Values of the grouped tag keys; empty for metrics without the tag
+	Bucket      string // Start of the time bucket: 2024-10-14T21:00+02:00 for hours, the date of the day or of the Monday of the week, 2024-10 for months
+	Count       int
+	Sum         float64
+	Avg         float64
+	Min         float64
+	Max         float64
+	Percentiles []float64 // In the order of AggregateQuery.Percentiles
+}
+
+// observation is one number of the history of a metric
+type observation struct {
+	metric DBMetric
+	at     time.Time
+	value  float64
+}
+
+// Aggregate summarizes the history of the metrics of store selected by query,
+// so questions like the average sleep over the last 30 days or the spending
+// of each type this month are answered from stored entries. Groups are
+// ordered by metric, type, tags and bucket; groups without observations are
+// left out. Derived metrics keep no history of their own and yield no groups.
+func Aggregate(store Store, query AggregateQuery) ([]AggregateGroup, error) {
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+
+	metrics, err := selectMetrics(store, query)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*AggregateGroup)
+	values := make(map[string][]float64)
+	var keys []string
+	observe := func(o observation) {
+		g := query.group(o)
+		key := g.MetricName + "\x00" + g.Type + "\x00" + g.Tags.String() + "\x00" + g.Bucket
+		if _, ok := groups[key]; !ok {
+			groups[key] = &g
+			keys = append(keys, key)
+		}
+		values[key] = append(values[key], o.value)
+	}
+	for _, metric := range metrics {
+		if err := collect(store, query, metric, observe); err != nil {
+			return nil, err
+		}
+	}
+	slices.Sort(keys)
+
+	result := make([]AggregateGroup, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+		g.summarize(values[key], query.Percentiles)
+		result = append(result, *g)
+	}
+	return result, nil
+}
+
+// validate rejects a query with an unknown field, grouping or bucket
+func (q *AggregateQuery) validate() error {
+	switch q.Field {
+	case "":
+		q.Field = FieldAmount
+	case FieldAmount, FieldValue, FieldClosing:
+	default:
+		return fmt.Errorf("unknown field %q, use %s, %s or %s", q.Field, FieldAmount, FieldValue, FieldClosing)
+	}
+
+	for _, g := range q.GroupBy {
+		switch {
+		case g == GroupMetric, g == GroupType:
+		case strings.HasPrefix(g, GroupTag):
+			if err := CheckTagKey(strings.TrimPrefix(g, GroupTag)); err != nil {
+				return fmt.Errorf("invalid grouping %q: %w", g, err)
+			}
+		default:
+			return fmt.Errorf("unknown grouping %q, use %s, %s or %s<key>", g, GroupMetric, GroupType, GroupTag)
+		}
+	}
+
+	switch q.Bucket {
+	case "", BucketHour, BucketDay, BucketWeek, BucketMonth:
+	default:
+		return fmt.Errorf("unknown bucket %q, use %s, %s, %s or %s", q.Bucket, BucketHour, BucketDay, BucketWeek, BucketMonth)
+	}
+
+	for _, p := range q.Percentiles {
+		if !(p >= 0 && p <= 100) {
+			return fmt.Errorf("percentile %g is not between 0 and 100", p)
+		}
+	}
+
+	if !q.Start.IsZero() && !q.End.IsZero() && !q.Start.Before(q.End) {
+		return fmt.Errorf("start %s is not before end %s", q.Start.Format(time.RFC3339), q.End.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// selectMetrics returns the live metrics of store picked by the name, type
+// and tag filters of query, keyed by name
+func selectMetrics(store Store, query AggregateQuery) (map[string]DBMetric, error) {
+	all, err := store.GetMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	metrics := make(map[string]DBMetric)
+	for _, m := range all {
+		if len(query.MetricNames) > 0 && !slices.Contains(query.MetricNames, m.MetricName) {
+			continue
+		}
+		if query.Type != "" && m.Type != query.Type || !m.Tags.Matches(query.Tags) {
+			continue
+		}
+		metrics[m.MetricName] = m
+	}
+	for _, name := range query.MetricNames {
+		if _, ok := metrics[name]; !ok && !slices.ContainsFunc(all, func(m DBMetric) bool { return m.MetricName == name }) {
+			return nil, fmt.Errorf("metric %s does not exist", name)
+		}
+	}
+	return metrics, nil
+}
+
+// collect reads the observations of the query's field for metric within
+// its time range and passes each to observe, so only the history of one
+// metric is held at a time
+func collect(store Store, query AggregateQuery, metric DBMetric, observe func(observation)) error {
+	if metric.Kind == KindDerived {
+		return nil
+	}
+
+	if query.Field == FieldClosing {
+		var startDate, endDate string
+		if !query.Start.IsZero() {
+			startDate = metric.Day.Date(query.Start)
+		}
+		if !query.End.IsZero() {
+			endDate = metric.Day.Date(query.End)
+		}
+		rollups, err := store.GetDailyRollups(metric.MetricName, startDate, endDate)
+		if err != nil {
+			return fmt.Errorf("failed to read daily rollups of metric %s: %w", metric.MetricName, err)
+		}
+		for _, r := range rollups {
+			date, err := time.Parse(DateFormat, r.Date)
+			if err != nil {
+				return fmt.Errorf("invalid date %q of metric %s: %w", r.Date, r.MetricName, err)
+			}
+			at := metric.Day.on(date.Date())
+			if !query.Start.IsZero() && at.Before(query.Start) || !query.End.IsZero() && !at.Before(query.End) {
+				continue
+			}
+			observe(observation{metric: metric, at: at, value: r.FinalValue})
+		}
+		return nil
+	}
+
+	events, err := store.GetMetricHistory(metric.MetricName, query.Start, query.End, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read history of metric %s: %w", metric.MetricName, err)
+	}
+	for _, e := range events {
+		if e.Reverted {
+			continue
+		}
+		o := observation{metric: metric, at: e.OccurredAt, value: e.Value}
+		switch e.Operation {
+		case OpIncrement, OpDecrement:
+			if query.Field == FieldAmount {
+				o.value = e.Delta
+			}
+		case OpUpdate:
+		default:
+			continue
+		}
+		observe(o)
+	}
+	return nil
+}
+
+// group returns the empty group an observation falls into
+func (q AggregateQuery) group(o observation) AggregateGroup {
+	var g AggregateGroup
+	for _, by := range q.GroupBy {
+		switch {
+		case by == GroupMetric:
+			g.MetricName = o.metric.MetricName
+		case by == GroupType:
+			g.Type = o.metric.Type
+		default:
+			if g.Tags == nil {
+				g.Tags = make(Tags)
+			}
+			key := strings.TrimPrefix(by, GroupTag)
+			g.Tags[key] = o.metric.Tags[key]
+		}
+	}
+	g.Bucket = bucketOf(q.Bucket, o.at, o.metric.Day)
+	return g
+}
+
+// bucketOf labels the time bucket t falls into. Days, weeks and months follow
+// the day boundary of the metric; hours are read on the clock of its zone.
+func bucketOf(bucket string, t time.Time, day DayBoundary) string {
+	switch bucket {
+	case BucketHour:
+		local := t.In(day.location())
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, local.Location()).Format("2006-01-02T15:04Z07:00")
+	case BucketDay:
+		return day.Date(t)
+	case BucketWeek:
+		year, month, d := day.date(t)
+		date := time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7).Format(DateFormat)
+	case BucketMonth:
+		year, month, _ := day.date(t)
+		return fmt.Sprintf("%04d-%02d", year, month)
+	}
+	return ""
+}
+
+// summarize computes the statistics of the values of a group
+func (g *AggregateGroup) summarize(values []float64, percentiles []float64) {
+	slices.Sort(values)
+	g.Count = len(values)
+	g.Min, g.Max = values[0], values[len(values)-1]
+	for _, v := range values {
+		g.Sum += v
+	}
+	g.Avg = g.Sum / float64(g.Count)
+	for _, p := range percentiles {
+		g.Percentiles = append(g.Percentiles, percentile(values, p))
+	}
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating
+// linearly between the two closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	utc := WithDayBoundary(DayBoundary{Location: time.UTC})
+	sqlite, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"), utc)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer sqlite.Close()
+
+	at := func(s string) time.Time {
+		t.Helper()
+		ts, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatalf("bad time %q: %v", s, err)
+		}
+		return ts
+	}
+
+	for _, store := range []Store{sqlite, NewMemoryStore(utc)} {
+		must := func(step string, err error) {
+			t.Helper()
+			if err != nil {
+				t.Fatalf("%T: %s failed: %v", store, step, err)
+			}
+		}
+		aggregate := func(query AggregateQuery) []string {
+			t.Helper()
+			groups, err := Aggregate(store, query)
+			must("aggregate", err)
+			var got []string
+			for _, g := range groups {
+				line := fmt.Sprintf("%s|%s|%s|%s %d %g %g %g %g", g.MetricName, g.Type, g.Tags, g.Bucket, g.Count, g.Sum, g.Avg, g.Min, g.Max)
+				for _, p := range g.Percentiles {
+					line += fmt.Sprintf(" %g", p)
+				}
+				got = append(got, line)
+			}
+			return got
+		}
+		check := func(name string, query AggregateQuery, want ...string) {
+			t.Helper()
+			if got := aggregate(query); !slices.Equal(got, want) {
+				t.Errorf("%T: %s = %q, want %q", store, name, got, want)
+			}
+		}
+
+		must("add coffee", store.AddMetric(DBMetric{MetricName: "coffee", Type: "Money", Unit: "eur", Kind: KindCounter, Tags: Tags{"person": "alex"}}))
+		must("add groceries", store.AddMetric(DBMetric{MetricName: "groceries", Type: "Money", Unit: "eur", Kind: KindCounter, Tags: Tags{"person": "sam"}}))
+		must("add sleep", store.AddMetric(DBMetric{MetricName: "sleep", Type: "Health", Unit: "h", Kind: KindGauge}))
+		must("add water", store.AddMetric(DBMetric{MetricName: "water", Type: "Health", Unit: "glasses", Kind: KindCounter,
+			Reset: ResetPolicy{Kind: ResetDaily}}))
+
+		must("coffee", store.IncrementMetric("coffee", 3, at("2024-10-14 08:10"), ""))
+		must("coffee", store.IncrementMetric("coffee", 4, at("2024-10-15 08:20"), ""))
+		// An undone entry stays in the history but is left out
+		must("typo", store.IncrementMetric("coffee", 40, at("2024-10-15 08:30"), ""))
+		typo, err := store.GetMetricHistory("coffee", at("2024-10-15 08:30"), at("2024-10-15 08:31"), 1)
+		must("read typo", err)
+		must("undo typo", store.RevertEntries([]int64{typo[0].ID}))
+		if err := store.RevertEntries([]int64{typo[0].ID}); err == nil || !strings.Contains(err.Error(), "already undone") {
+			t.Errorf("%T: undoing the typo twice = %v, want an error", store, err)
+		}
+		must("groceries", store.IncrementMetric("groceries", 50, at("2024-10-15 18:00"), ""))
+		must("groceries", store.IncrementMetric("groceries", 30, at("2024-11-02 11:00"), ""))
+		for i, hours := range []float64{7, 8, 6, 9} {
+			must("sleep", store.UpdateMetric("sleep", hours, at("2024-10-14 07:00").AddDate(0, 0, i), ""))
+		}
+		// Backdated entries of a daily metric land in its daily history
+		must("water", store.IncrementMetric("water", 2, at("2024-10-14 10:00"), ""))
+		must("water", store.IncrementMetric("water", 1, at("2024-10-15 10:00"), ""))
+		must("water", store.IncrementMetric("water", 3, at("2024-10-15 16:00"), ""))
+
+		check("average sleep", AggregateQuery{MetricNames: []string{"sleep"}, Start: at("2024-10-14 00:00"), End: at("2024-10-18 00:00"),
+			Percentiles: []float64{50, 90}}, "||| 4 30 7.5 6 9 7.5 8.7")
+		check("spending by type and month", AggregateQuery{Type: "Money", GroupBy: []string{GroupType}, Bucket: BucketMonth},
+			"|Money||2024-10 3 57 19 3 50", "|Money||2024-11 1 30 30 30 30")
+		check("spending by person and week", AggregateQuery{Type: "Money", GroupBy: []string{"tag:person"}, Bucket: BucketWeek},
+			"||person=alex|2024-10-14 2 7 3.5 3 4", "||person=sam|2024-10-14 1 50 50 50 50", "||person=sam|2024-10-28 1 30 30 30 30")
+		check("coffee values by hour", AggregateQuery{MetricNames: []string{"coffee"}, Field: FieldValue, GroupBy: []string{GroupMetric}, Bucket: BucketHour},
+			"coffee|||2024-10-14T08:00Z 1 3 3 3 3", "coffee|||2024-10-15T08:00Z 1 7 7 7 7")
+		check("water without a person", AggregateQuery{Tags: Tags{"person": ""}, MetricNames: []string{"water"}, Field: FieldClosing})
+		check("daily water", AggregateQuery{MetricNames: []string{"water"}, Field: FieldClosing, Start: at("2024-10-15 00:00"), Bucket: BucketDay},
+			"|||2024-10-15 1 4 4 4 4")
+		check("water by metric", AggregateQuery{GroupBy: []string{GroupMetric}, Field: FieldClosing, Percentiles: []float64{0, 100}},
+			"water||| 2 6 3 2 4 2 4")
+
+		for _, tt := range []struct {
+			query AggregateQuery
+			want  string
+		}{
+			{AggregateQuery{Field: "median"}, `unknown field "median"`},
+			{AggregateQuery{GroupBy: []string{"person"}}, `unknown grouping "person"`},
+			{AggregateQuery{GroupBy: []string{"tag:unit"}}, "reserved"},
+			{AggregateQuery{Bucket: "year"}, `unknown bucket "year"`},
+			{AggregateQuery{Percentiles: []float64{101}}, "percentile 101 is not between 0 and 100"},
+			{AggregateQuery{Start: at("2024-10-15 00:00"), End: at("2024-10-14 00:00")}, "is not before end"},
+			{AggregateQuery{MetricNames: []string{"tea"}}, "metric tea does not exist"},
+		} {
+			if _, err := Aggregate(store, tt.query); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("%T: Aggregate(%+v) = %v, want an error containing %q", store, tt.query, err, tt.want)
+			}
+		}
+	}
+}
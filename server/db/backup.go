@@ -0,0 +1,294 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Snapshotter is a Store that can write a consistent copy of itself to a new
+// file while it keeps serving reads and writes
+type Snapshotter interface {
+	Backup(path string) error
+}
+
+// Backup writes a consistent snapshot of the database to path with VACUUM
+// INTO, which reads the database in a single transaction and produces a
+// compacted copy. path must not exist yet.
+func (db *Database) Backup(path string) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if _, err := db.conn.Exec(`VACUUM INTO ?;`, path); err != nil {
+		return fmt.Errorf("failed to back up database to %s: %w", path, err)
+	}
+	return nil
+}
+
+// Backup writes a consistent snapshot of the bbolt file to path from a read
+// transaction, so writers are not blocked while it is copied
+func (s *BoltStore) Backup(path string) error {
+	backend, ok := s.backend.(*boltBackend)
+	if !ok {
+		return fmt.Errorf("bolt store has an unexpected backend %T", s.backend)
+	}
+	err := backend.conn.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(path, 0o600)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to back up database to %s: %w", path, err)
+	}
+	return nil
+}
+
+// Backup file names are backupPrefix, the UTC time of the snapshot in
+// backupTimeLayout and backupSuffix, so they sort by age
+const (
+	backupPrefix     = "kettle-"
+	backupSuffix     = ".db"
+	backupTimeLayout = "20060102-150405"
+)
+
+// Backups writes timestamped snapshots of a store into Dir and prunes old
+// ones. The newest snapshot of each of the last KeepDaily days and of each of
+// the last KeepWeekly weeks is kept; every other snapshot is removed.
+type Backups struct {
+	Dir        string
+	KeepDaily  int
+	KeepWeekly int
+
+	mu sync.Mutex // Serializes scheduled and requested backups
+}
+
+// Create writes a snapshot of store taken at now into the backup directory,
+// prunes the snapshots that fall out of the retention and returns the path of
+// the new one. The snapshot is written under a temporary name first so a
+// failed backup never leaves a partial file that looks like a snapshot.
+func (b *Backups) Create(store Store, now time.Time) (string, error) {
+	snapshotter, ok := store.(Snapshotter)
+	if !ok {
+		return "", fmt.Errorf("the %T store does not support backups", store)
+	}
+	if b.Dir == "" {
+		return "", fmt.Errorf("no backup directory configured")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := os.MkdirAll(b.Dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(b.Dir, backupPrefix+now.UTC().Format(backupTimeLayout)+backupSuffix)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("backup %s already exists", path)
+	}
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := snapshotter.Backup(tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to move backup into place: %w", err)
+	}
+
+	pruned, err := b.prune()
+	if err != nil {
+		return path, err
+	}
+	for _, old := range pruned {
+		log.Printf("Removed backup %s that fell out of the retention", old)
+	}
+	return path, nil
+}
+
+// List returns the snapshots in the backup directory with the newest first
+func (b *Backups) List() ([]string, error) {
+	entries, err := os.ReadDir(b.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if _, ok := backupTime(entry.Name()); ok && !entry.IsDir() {
+			paths = append(paths, filepath.Join(b.Dir, entry.Name()))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	return paths, nil
+}
+
+// prune removes the snapshots outside the retention and returns their paths
+func (b *Backups) prune() ([]string, error) {
+	paths, err := b.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := keepBackups(paths, b.KeepDaily, b.KeepWeekly)
+	var removed []string
+	for _, path := range paths {
+		if keep[path] {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove backup: %w", err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// keepBackups returns the snapshots of paths, sorted newest first, that are
+// the newest of one of the keepDaily most recent days or of one of the
+// keepWeekly most recent ISO weeks that have snapshots. The newest snapshot
+// is always kept, so a backup never removes the file it just wrote.
+func keepBackups(paths []string, keepDaily, keepWeekly int) map[string]bool {
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	keep := make(map[string]bool)
+	if len(paths) > 0 {
+		keep[paths[0]] = true
+	}
+	for _, path := range paths {
+		taken, _ := backupTime(filepath.Base(path))
+		day := taken.Format(time.DateOnly)
+		year, week := taken.ISOWeek()
+		weekKey := fmt.Sprintf("%d-W%02d", year, week)
+
+		if !days[day] && len(days) < keepDaily {
+			days[day] = true
+			keep[path] = true
+		}
+		if !weeks[weekKey] && len(weeks) < keepWeekly {
+			weeks[weekKey] = true
+			keep[path] = true
+		}
+	}
+	return keep
+}
+
+// backupTime parses the time a snapshot was taken from its file name
+func backupTime(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, backupPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp, ok = strings.CutSuffix(stamp, backupSuffix)
+	if !ok {
+		return time.Time{}, false
+	}
+	taken, err := time.Parse(backupTimeLayout, stamp)
+	return taken, err == nil
+}
+
+// StartBackupScheduler writes a snapshot of store every interval until
+// stopChan is closed. An interval of 0 or an empty backup directory turns
+// scheduled backups off.
+func StartBackupScheduler(store Store, backups *Backups, interval time.Duration, stopChan chan bool) {
+	if interval <= 0 || backups.Dir == "" {
+		return
+	}
+
+	backup := func() {
+		path, err := backups.Create(store, time.Now())
+		if err != nil {
+			log.Printf("Error backing up the database: %v", err)
+			return
+		}
+		log.Printf("Backed up the database to %s", path)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopChan:
+			log.Println("Stopping the backup scheduler.")
+			return
+		case <-ticker.C:
+			backup()
+		}
+	}
+}
+
+// CheckSnapshot verifies that the SQLite file at path is intact and holds a
+// schema this binary can migrate, and returns its schema version
+func CheckSnapshot(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("cannot read snapshot: %w", err)
+	}
+	snapshot, err := OpenDatabase(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer snapshot.Close()
+
+	var integrity string
+	if err := snapshot.conn.QueryRow(`PRAGMA integrity_check;`).Scan(&integrity); err != nil {
+		return 0, fmt.Errorf("snapshot is not a SQLite database: %w", err)
+	}
+	if integrity != "ok" {
+		return 0, fmt.Errorf("snapshot failed the integrity check: %s", integrity)
+	}
+
+	version, err := snapshot.SchemaVersion()
+	if err != nil {
+		return 0, err
+	}
+	latest, err := LatestVersion()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case version == 0:
+		return 0, fmt.Errorf("snapshot has no schema version, it is not a quanti-tea database")
+	case version > latest:
+		return 0, fmt.Errorf("snapshot schema version %d is newer than the %d supported by this binary", version, latest)
+	}
+	return version, nil
+}
+
+// CheckBoltSnapshot verifies that the bbolt file at path is intact and has
+// the buckets of a bolt store
+func CheckBoltSnapshot(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot read snapshot: %w", err)
+	}
+	conn, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.View(func(tx *bolt.Tx) error {
+		for _, bucket := range kvBuckets {
+			if tx.Bucket([]byte(bucket)) == nil {
+				return fmt.Errorf("snapshot has no %s bucket, it is not a quanti-tea database", bucket)
+			}
+		}
+		// Drain every error so the check is done before the transaction ends
+		var checkErr error
+		for err := range tx.Check() {
+			if checkErr == nil {
+				checkErr = fmt.Errorf("snapshot failed the consistency check: %w", err)
+			}
+		}
+		return checkErr
+	})
+}
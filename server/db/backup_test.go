@@ -0,0 +1,167 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackupSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	sqlite, err := NewDatabase(filepath.Join(dir, "kettle.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer sqlite.Close()
+	bolt, err := NewBoltStore(filepath.Join(dir, "kettle.bolt"))
+	if err != nil {
+		t.Fatalf("NewBoltStore failed: %v", err)
+	}
+	defer bolt.Close()
+
+	now := time.Date(2024, 10, 16, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name  string
+		store Store
+		check func(path string) error
+	}{
+		{"sqlite", sqlite, func(path string) error { _, err := CheckSnapshot(path); return err }},
+		{"bolt", bolt, CheckBoltSnapshot},
+	} {
+		if err := tt.store.AddMetric(DBMetric{MetricName: "Tea", Type: "Drink", Unit: "cups"}); err != nil {
+			t.Fatalf("%s: AddMetric failed: %v", tt.name, err)
+		}
+		if err := tt.store.IncrementMetric("Tea", 2, now, ""); err != nil {
+			t.Fatalf("%s: IncrementMetric failed: %v", tt.name, err)
+		}
+
+		backups := &Backups{Dir: filepath.Join(dir, tt.name), KeepDaily: 1}
+		path, err := backups.Create(tt.store, now)
+		if err != nil {
+			t.Fatalf("%s: Create failed: %v", tt.name, err)
+		}
+		if filepath.Base(path) != "kettle-20241016-120000.db" {
+			t.Errorf("%s: snapshot written to %s", tt.name, path)
+		}
+		if err := tt.check(path); err != nil {
+			t.Errorf("%s: snapshot check failed: %v", tt.name, err)
+		}
+		if _, err := backups.Create(tt.store, now); err == nil {
+			t.Errorf("%s: overwrote an existing snapshot", tt.name)
+		}
+	}
+
+	restored, err := NewDatabase(filepath.Join(dir, "sqlite", "kettle-20241016-120000.db"))
+	if err != nil {
+		t.Fatalf("opening the snapshot failed: %v", err)
+	}
+	defer restored.Close()
+	metric, err := restored.GetMetric("Tea")
+	if err != nil || metric.Value != 2 {
+		t.Errorf("snapshot holds %+v, %v", metric, err)
+	}
+
+	if _, err := (&Backups{Dir: dir}).Create(NewMemoryStore(), now); err == nil {
+		t.Error("backed up the memory store")
+	}
+}
+
+func TestCheckSnapshotRejectsForeignFiles(t *testing.T) {
+	db, path := openTestDatabase(t)
+	if _, err := db.conn.Exec(`CREATE TABLE notes (body TEXT);`); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := CheckSnapshot(path); err == nil || !strings.Contains(err.Error(), "no schema version") {
+		t.Errorf("CheckSnapshot of an unversioned database = %v", err)
+	}
+
+	latest := latestVersion(t)
+	if err := db.MigrateTo(latest); err != nil {
+		t.Fatalf("MigrateTo failed: %v", err)
+	}
+	if _, err := db.conn.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'future', 0);`, latest+1); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if _, err := CheckSnapshot(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("CheckSnapshot of a newer database = %v", err)
+	}
+
+	text := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(text, []byte(strings.Repeat("not a database\n", 100)), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := CheckSnapshot(text); err == nil {
+		t.Error("CheckSnapshot accepted a text file")
+	}
+}
+
+func TestBackupRetention(t *testing.T) {
+	dir := t.TempDir()
+	// Two snapshots a day from Monday 2024-09-30 to Wednesday 2024-10-16
+	start := time.Date(2024, 9, 30, 6, 0, 0, 0, time.UTC)
+	for i := 0; i < 34; i++ {
+		taken := start.Add(time.Duration(i) * 12 * time.Hour)
+		name := "kettle-" + taken.Format(backupTimeLayout) + ".db"
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	backups := &Backups{Dir: dir, KeepDaily: 3, KeepWeekly: 3}
+	if _, err := backups.prune(); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+
+	paths, err := backups.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var got []string
+	for _, path := range paths {
+		got = append(got, filepath.Base(path))
+	}
+	want := []string{
+		"kettle-20241016-180000.db", // Today and this week
+		"kettle-20241015-180000.db",
+		"kettle-20241014-180000.db",
+		"kettle-20241013-180000.db", // Newest of the week before
+		"kettle-20241006-180000.db", // Newest of the week before that
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("kept %v, want %v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("pruning removed an unrelated file: %v", err)
+	}
+}
+
+func TestBackupRetentionKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	sqlite, err := NewDatabase(filepath.Join(dir, "kettle.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer sqlite.Close()
+
+	// Keeping no days and no weeks still leaves the snapshot just written
+	backups := &Backups{Dir: filepath.Join(dir, "backups")}
+	taken := time.Date(2024, 10, 14, 6, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if _, err := backups.Create(sqlite, taken.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	paths, err := backups.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(paths) != 1 || filepath.Base(paths[0]) != "kettle-20241014-070000.db" {
+		t.Errorf("kept %v, want only the newest snapshot", paths)
+	}
+}
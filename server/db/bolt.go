@@ -0,0 +1,117 @@
+// bolt.go
+package db
+
+import (
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltStore keeps metrics in a single bbolt file. Unlike SQLite it never
+// creates journal files next to the database, which suits devices with a
+// read-only root where only the data file itself is writable.
+type BoltStore struct {
+	kvStore
+}
+
+// NewBoltStore opens or creates the bbolt database at path
+func NewBoltStore(path string, opts ...Option) (*BoltStore, error) {
+	conn, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt database: %w", err)
+	}
+
+	err = conn.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range kvBuckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &BoltStore{kvStore{backend: &boltBackend{conn: conn}, cfg: newConfig(opts)}}, nil
+}
+
+// boltBackend adapts a bbolt database to kvBackend
+type boltBackend struct {
+	conn *bolt.DB
+}
+
+func (b *boltBackend) view(fn func(tx kvTx) error) error {
+	return b.conn.View(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	})
+}
+
+func (b *boltBackend) update(fn func(tx kvTx) error) error {
+	return b.conn.Update(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	})
+}
+
+func (b *boltBackend) close() error {
+	return b.conn.Close()
+}
+
+// boltTx maps buckets and keys of kvTx onto a bbolt transaction. Returned
+// values are only valid until the transaction ends.
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) bucket(name string) (*bolt.Bucket, error) {
+	bucket := t.tx.Bucket([]byte(name))
+	if bucket == nil {
+		return nil, fmt.Errorf("unknown bucket %s", name)
+	}
+	return bucket, nil
+}
+
+func (t boltTx) get(bucket, key string) []byte {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return nil
+	}
+	return b.Get([]byte(key))
+}
+
+func (t boltTx) put(bucket, key string, value []byte) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), value)
+}
+
+func (t boltTx) delete(bucket, key string) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete([]byte(key))
+}
+
+func (t boltTx) forEach(bucket string, fn func(key string, value []byte) error) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return b.ForEach(func(k, v []byte) error {
+		return fn(string(k), v)
+	})
+}
+
+func (t boltTx) nextID(bucket string) (int64, error) {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return 0, err
+	}
+	seq, err := b.NextSequence()
+	return int64(seq), err
+}
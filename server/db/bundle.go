@@ -0,0 +1,428 @@
+package db
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BundleVersion is the version of the export format written by this binary.
+// Bundles of a newer version are refused. Version 2 added tags, version 3
+// the notes of entries, version 4 the formulas of derived metrics, version 5
+// the link rules and version 6 the IDs of events, which undo events point at.
+const BundleVersion = 6
+
+// Bundle is everything a store holds, as written by ExportData
+type Bundle struct {
+	Version    int
+	ExportedAt time.Time
+	Metrics    []MetricData
+	Links      []Link // Imported after the metrics, since they link two of them
+}
+
+// BundleFormat is the encoding of a bundle
+type BundleFormat string
+
+// Bundle formats understood by ParseBundleFormat
+const (
+	FormatJSON BundleFormat = "json" // One JSON document
+	FormatCSV  BundleFormat = "csv"  // One row per metric, event, rollup and link, see csvColumns
+)
+
+// ParseBundleFormat parses the name of a bundle format. An empty name is JSON.
+func ParseBundleFormat(s string) (BundleFormat, error) {
+	format := BundleFormat(strings.ToLower(strings.TrimSpace(s)))
+	switch format {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatCSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected json or csv", s)
+	}
+}
+
+// NewBundle exports every metric of store into a bundle
+func NewBundle(store Store, now time.Time) (Bundle, error) {
+	metrics, err := ExportMetrics(store)
+	if err != nil {
+		return Bundle{}, err
+	}
+	links, err := store.GetLinks("")
+	if err != nil {
+		return Bundle{}, fmt.Errorf("failed to read links: %w", err)
+	}
+	return Bundle{Version: BundleVersion, ExportedAt: now, Metrics: metrics, Links: links}, nil
+}
+
+// Encode writes the bundle to w in format
+func (b Bundle) Encode(w io.Writer, format BundleFormat) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b)
+	case FormatCSV:
+		return b.encodeCSV(w)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// DecodeBundle reads a bundle written by Encode in format
+func DecodeBundle(r io.Reader, format BundleFormat) (Bundle, error) {
+	var b Bundle
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.NewDecoder(r).Decode(&b)
+	case FormatCSV:
+		b, err = decodeCSV(r)
+	default:
+		return b, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return b, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+
+	switch {
+	case b.Version == 0:
+		return b, fmt.Errorf("bundle has no version, it was not written by quanti-tea")
+	case b.Version > BundleVersion:
+		return b, fmt.Errorf("bundle version %d is newer than the %d supported by this binary", b.Version, BundleVersion)
+	}
+	return b, nil
+}
+
+// csvColumns is the header of the CSV form. Every row starts with the record
+// it holds: one "bundle" row with the version in value and the export time in
+// occurred_at, then for each metric a "metric" row followed by its "event"
+// and "rollup" rows, and last a "link" row per link rule. Event rows hold the
+// value after the event in value and, for undo events, the id of the event
+// they took back in reverts; rollup rows hold the final value of their day and
+// link rows hold the source in metric_name and the multiplier in value.
+var csvColumns = []string{
+	"record", "metric_name", "type", "unit", "kind", "value", "reset_policy", "day_start", "timezone",
+	"min", "max", "integer", "step", "allow_negative", "goal_direction", "goal_target", "rest_days",
+	"last_reset", "deleted_at", "aliases", "tags", "formula", "id", "operation", "delta", "occurred_at", "note", "reverts",
+	"day", "min_value", "max_value", "update_count", "target",
+}
+
+// csvAddedColumns maps the columns added after version 1 to the version that
+// added them
+var csvAddedColumns = map[string]int{"tags": 2, "note": 3, "formula": 4, "target": 5, "id": 6, "reverts": 6}
+
+// csvHeader returns the header of bundles of the given version
+func csvHeader(version int) []string {
+	return slices.DeleteFunc(slices.Clone(csvColumns), func(column string) bool {
+		added, ok := csvAddedColumns[column]
+		return ok && added > version
+	})
+}
+
+// CSV record kinds
+const (
+	csvBundle = "bundle"
+	csvMetric = "metric"
+	csvEvent  = "event"
+	csvRollup = "rollup"
+	csvLink   = "link"
+)
+
+// csvAliasSeparator joins the aliases of a metric in the aliases column
+const csvAliasSeparator = ";"
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return formatFloat(*v)
+}
+
+// formatCSVTime writes t as RFC3339 in UTC, leaving the zero time empty
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (b Bundle) encodeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+
+	// row returns an empty row with the given record kind and metric name
+	row := func(record, metricName string) map[string]string {
+		return map[string]string{"record": record, "metric_name": metricName}
+	}
+	write := func(fields map[string]string) error {
+		line := make([]string, len(csvColumns))
+		for i, column := range csvColumns {
+			line[i] = fields[column]
+		}
+		return cw.Write(line)
+	}
+
+	header := row(csvBundle, "")
+	header["value"] = strconv.Itoa(b.Version)
+	header["occurred_at"] = formatCSVTime(b.ExportedAt)
+	if err := write(header); err != nil {
+		return err
+	}
+
+	for _, data := range b.Metrics {
+		m := data.Metric
+		fields := row(csvMetric, m.MetricName)
+		fields["type"] = m.Type
+		fields["unit"] = m.Unit
+		fields["kind"] = string(m.Kind)
+		fields["value"] = formatFloat(m.Value)
+		fields["reset_policy"] = m.Reset.String()
+		fields["day_start"] = m.DayStart
+		fields["timezone"] = m.Timezone
+		fields["min"] = formatOptionalFloat(m.Constraints.Min)
+		fields["max"] = formatOptionalFloat(m.Constraints.Max)
+		fields["integer"] = strconv.FormatBool(m.Constraints.Integer)
+		fields["step"] = formatFloat(m.Constraints.Step)
+		fields["allow_negative"] = strconv.FormatBool(m.Constraints.AllowNegative)
+		fields["goal_direction"] = string(m.Goal.Direction)
+		fields["goal_target"] = formatFloat(m.Goal.Target)
+		fields["rest_days"] = m.RestDays.String()
+		fields["last_reset"] = formatCSVTime(m.LastReset)
+		fields["deleted_at"] = formatCSVTime(m.DeletedAt)
+		fields["aliases"] = strings.Join(m.Aliases, csvAliasSeparator)
+		fields["tags"] = m.Tags.String()
+		fields["formula"] = m.Formula
+		if err := write(fields); err != nil {
+			return err
+		}
+
+		for _, e := range data.Events {
+			fields := row(csvEvent, m.MetricName)
+			fields["id"] = strconv.FormatInt(e.ID, 10)
+			fields["operation"] = e.Operation
+			fields["delta"] = formatFloat(e.Delta)
+			fields["value"] = formatFloat(e.Value)
+			fields["occurred_at"] = formatCSVTime(e.OccurredAt)
+			fields["note"] = e.Note
+			if e.Reverts != 0 {
+				fields["reverts"] = strconv.FormatInt(e.Reverts, 10)
+			}
+			if err := write(fields); err != nil {
+				return err
+			}
+		}
+		for _, r := range data.Rollups {
+			fields := row(csvRollup, m.MetricName)
+			fields["day"] = r.Date
+			fields["value"] = formatFloat(r.FinalValue)
+			fields["min_value"] = formatFloat(r.MinValue)
+			fields["max_value"] = formatFloat(r.MaxValue)
+			fields["update_count"] = strconv.Itoa(r.UpdateCount)
+			if err := write(fields); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, link := range b.Links {
+		fields := row(csvLink, link.Source)
+		fields["target"] = link.Target
+		fields["value"] = formatFloat(link.Multiplier)
+		if err := write(fields); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRow reads the fields of one row by column name and keeps the first
+// error, so a row can be decoded field by field and checked once
+type csvRow struct {
+	fields map[string]string
+	err    error
+}
+
+func (r *csvRow) text(column string) string {
+	return r.fields[column]
+}
+
+func (r *csvRow) float(column string) float64 {
+	s := r.fields[column]
+	if s == "" || r.err != nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		r.err = fmt.Errorf("column %s: %w", column, err)
+	}
+	return v
+}
+
+func (r *csvRow) floatPtr(column string) *float64 {
+	if r.fields[column] == "" {
+		return nil
+	}
+	v := r.float(column)
+	return &v
+}
+
+func (r *csvRow) integer(column string) int {
+	s := r.fields[column]
+	if s == "" || r.err != nil {
+		return 0
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		r.err = fmt.Errorf("column %s: %w", column, err)
+	}
+	return v
+}
+
+func (r *csvRow) boolean(column string) bool {
+	s := r.fields[column]
+	if s == "" || r.err != nil {
+		return false
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		r.err = fmt.Errorf("column %s: %w", column, err)
+	}
+	return v
+}
+
+func (r *csvRow) timestamp(column string) time.Time {
+	s := r.fields[column]
+	if s == "" || r.err != nil {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		r.err = fmt.Errorf("column %s: %w", column, err)
+	}
+	return t
+}
+
+func decodeCSV(r io.Reader) (Bundle, error) {
+	var b Bundle
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return b, fmt.Errorf("failed to read header: %w", err)
+	}
+	known := false
+	for version := 1; version <= BundleVersion; version++ {
+		known = known || slices.Equal(header, csvHeader(version))
+	}
+	if !known {
+		return b, fmt.Errorf("unexpected header, expected %s", strings.Join(csvColumns, ","))
+	}
+
+	for line := 2; ; line++ {
+		values, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return b, err
+		}
+		row := &csvRow{fields: make(map[string]string, len(header))}
+		for i, column := range header {
+			row.fields[column] = values[i]
+		}
+
+		record := row.text("record")
+		var current *MetricData
+		if len(b.Metrics) > 0 {
+			current = &b.Metrics[len(b.Metrics)-1]
+		}
+		if (record == csvEvent || record == csvRollup) && (current == nil || current.Metric.MetricName != row.text("metric_name")) {
+			return b, fmt.Errorf("line %d: %s row does not follow the row of metric %s", line, record, row.text("metric_name"))
+		}
+
+		switch record {
+		case csvBundle:
+			b.Version = row.integer("value")
+			b.ExportedAt = row.timestamp("occurred_at")
+		case csvMetric:
+			m := DBMetric{
+				MetricName: row.text("metric_name"),
+				Type:       row.text("type"),
+				Unit:       row.text("unit"),
+				Kind:       MetricKind(row.text("kind")),
+				Value:      row.float("value"),
+				DayStart:   row.text("day_start"),
+				Timezone:   row.text("timezone"),
+				Constraints: Constraints{
+					Min:           row.floatPtr("min"),
+					Max:           row.floatPtr("max"),
+					Integer:       row.boolean("integer"),
+					Step:          row.float("step"),
+					AllowNegative: row.boolean("allow_negative"),
+				},
+				Goal:      Goal{Direction: GoalDirection(row.text("goal_direction")), Target: row.float("goal_target")},
+				LastReset: row.timestamp("last_reset"),
+				DeletedAt: row.timestamp("deleted_at"),
+				Formula:   row.text("formula"),
+			}
+			if aliases := row.text("aliases"); aliases != "" {
+				m.Aliases = strings.Split(aliases, csvAliasSeparator)
+			}
+			if row.err == nil {
+				m.Reset, row.err = ParseResetPolicy(row.text("reset_policy"))
+			}
+			if row.err == nil {
+				m.RestDays, row.err = ParseWeekdays(row.text("rest_days"))
+			}
+			if row.err == nil {
+				m.Tags, row.err = ParseTags(row.text("tags"))
+			}
+			b.Metrics = append(b.Metrics, MetricData{Metric: m})
+		case csvEvent:
+			current.Events = append(current.Events, DBEvent{
+				ID:         int64(row.integer("id")),
+				MetricName: current.Metric.MetricName,
+				Operation:  row.text("operation"),
+				Delta:      row.float("delta"),
+				Value:      row.float("value"),
+				OccurredAt: row.timestamp("occurred_at"),
+				Note:       row.text("note"),
+				Reverts:    int64(row.integer("reverts")),
+			})
+		case csvRollup:
+			current.Rollups = append(current.Rollups, DBRollup{
+				MetricName:  current.Metric.MetricName,
+				Date:        row.text("day"),
+				FinalValue:  row.float("value"),
+				MinValue:    row.float("min_value"),
+				MaxValue:    row.float("max_value"),
+				UpdateCount: row.integer("update_count"),
+			})
+		case csvLink:
+			b.Links = append(b.Links, Link{
+				Source:     row.text("metric_name"),
+				Target:     row.text("target"),
+				Multiplier: row.float("value"),
+			})
+		default:
+			return b, fmt.Errorf("line %d: unknown record %q", line, record)
+		}
+		if row.err != nil {
+			return b, fmt.Errorf("line %d: %w", line, row.err)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"fmt"
+	"math"
+)
+
+// Constraints limit the values a metric can take. Every add, increment,
+// decrement and update is checked against them and rejected if the value it
+// produces breaks them. The zero value only rejects negative values.
+//
+// A metric starts at 0 and goes back to 0 on every reset, so 0 is accepted
+// as its starting value even when it lies outside Min and Max.
+type Constraints struct {
+	Min           *float64 // Lowest allowed value; nil leaves it open
+	Max           *float64 // Highest allowed value; nil leaves it open
+	Integer       bool     // Only whole numbers are allowed
+	Step          float64  // Values must be Min (or 0) plus a multiple of Step; 0 allows any value
+	AllowNegative bool     // Values below 0 are allowed
+}
+
+// stepTolerance absorbs the rounding errors of adding up decimal steps such as 0.1
+const stepTolerance = 1e-9
+
+// Validate rejects constraints that no value other than 0 could satisfy
+func (c Constraints) Validate() error {
+	if c.Min != nil && c.Max != nil && *c.Min > *c.Max {
+		return fmt.Errorf("minimum %g is above the maximum %g", *c.Min, *c.Max)
+	}
+	if !c.AllowNegative && (c.Min != nil && *c.Min < 0 || c.Max != nil && *c.Max < 0) {
+		return fmt.Errorf("a negative minimum or maximum requires allowing negative values")
+	}
+	if c.Step < 0 || math.IsNaN(c.Step) || math.IsInf(c.Step, 0) {
+		return fmt.Errorf("step %g must be a positive number or 0", c.Step)
+	}
+	if c.Integer && c.Step != math.Trunc(c.Step) {
+		return fmt.Errorf("step %g of a whole number metric must be a whole number", c.Step)
+	}
+	for _, bound := range []*float64{c.Min, c.Max} {
+		if bound != nil && (math.IsNaN(*bound) || math.IsInf(*bound, 0)) {
+			return fmt.Errorf("bound %g is not a finite number", *bound)
+		}
+	}
+	return nil
+}
+
+// Check returns an error describing how value breaks the constraints
+func (c Constraints) Check(value float64) error {
+	switch {
+	case math.IsNaN(value) || math.IsInf(value, 0):
+		return fmt.Errorf("value %g is not a finite number", value)
+	case value < 0 && !c.AllowNegative:
+		return fmt.Errorf("value cannot be negative")
+	case c.Min != nil && value < *c.Min:
+		return fmt.Errorf("value %g is below the minimum of %g", value, *c.Min)
+	case c.Max != nil && value > *c.Max:
+		return fmt.Errorf("value %g is above the maximum of %g", value, *c.Max)
+	case c.Integer && math.Abs(value-math.Round(value)) > stepTolerance:
+		return fmt.Errorf("value %g is not a whole number", value)
+	}
+
+	if c.Step > 0 {
+		var base float64
+		if c.Min != nil {
+			base = *c.Min
+		}
+		steps := (value - base) / c.Step
+		if math.Abs(steps-math.Round(steps)) > stepTolerance {
+			if base == 0 {
+				return fmt.Errorf("value %g is not a multiple of %g", value, c.Step)
+			}
+			return fmt.Errorf("value %g is not %g plus a multiple of %g", value, base, c.Step)
+		}
+	}
+	return nil
+}
+
+// checkStart checks the value a metric is added or edited with, which may
+// always be 0
+func (c Constraints) checkStart(value float64) error {
+	if value == 0 {
+		return nil
+	}
+	return c.Check(value)
+}
+
+// validateConstraints rejects contradicting constraints and a value that
+// breaks them
+func validateConstraints(metric DBMetric) error {
+	if err := metric.Constraints.Validate(); err != nil {
+		return fmt.Errorf("invalid constraints: %w", err)
+	}
+	if err := metric.Constraints.checkStart(metric.Value); err != nil {
+		return fmt.Errorf("metric %s %w", metric.MetricName, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import "testing"
+
+func TestConstraintsCheck(t *testing.T) {
+	one, ten, minusFive := 1.0, 10.0, -5.0
+
+	tests := []struct {
+		name        string
+		constraints Constraints
+		valid       []float64
+		invalid     []float64
+	}{
+		{"default", Constraints{}, []float64{0, 0.5, 1e6}, []float64{-0.1}},
+		{"negative", Constraints{AllowNegative: true, Min: &minusFive}, []float64{-5, 0, 3}, []float64{-5.5}},
+		{"range", Constraints{Min: &one, Max: &ten}, []float64{1, 5.5, 10}, []float64{0.9, 10.1, -1}},
+		{"integer", Constraints{Integer: true}, []float64{0, 3, 42}, []float64{1.5}},
+		{"step", Constraints{Step: 0.25}, []float64{0.25, 0.1 + 0.2 + 0.2 + 0.25 + 0.25, 7.75}, []float64{0.3}},
+		{"step from min", Constraints{Min: &one, Step: 2}, []float64{1, 3, 11}, []float64{2, 4}},
+	}
+	for _, tt := range tests {
+		if err := tt.constraints.Validate(); err != nil {
+			t.Errorf("%s: Validate failed: %v", tt.name, err)
+		}
+		for _, v := range tt.valid {
+			if err := tt.constraints.Check(v); err != nil {
+				t.Errorf("%s: Check(%v) failed: %v", tt.name, v, err)
+			}
+		}
+		for _, v := range tt.invalid {
+			if err := tt.constraints.Check(v); err == nil {
+				t.Errorf("%s: Check(%v) succeeded, want an error", tt.name, v)
+			}
+		}
+	}
+
+	for name, c := range map[string]Constraints{
+		"min above max":         {Min: &ten, Max: &one},
+		"negative min":          {Min: &minusFive},
+		"negative step":         {Step: -1},
+		"fractional whole step": {Integer: true, Step: 0.5},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: Validate succeeded, want an error", name)
+		}
+	}
+}
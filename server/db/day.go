@@ -0,0 +1,133 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DayBoundary is the wall clock time a day starts at in a time zone. Daily,
+// weekly and monthly resets happen at that time, and per-day reports label
+// an instant with the day it belongs to, so a snack at 01:30 counts toward
+// the previous day when days start at 02:00.
+type DayBoundary struct {
+	Start    time.Duration  // Offset of the day start from midnight, below 24h
+	Location *time.Location // Zone the wall clock is read in; nil means time.Local
+}
+
+// ParseDayBoundary parses a day start in "HH:MM" form and an IANA time zone
+// name such as "Europe/Berlin". Empty values keep those of defaults.
+func ParseDayBoundary(start, zone string, defaults DayBoundary) (DayBoundary, error) {
+	day := defaults
+	if start = strings.TrimSpace(start); start != "" {
+		offset, err := parseDayStart(start)
+		if err != nil {
+			return DayBoundary{}, err
+		}
+		day.Start = offset
+	}
+	if zone = strings.TrimSpace(zone); zone != "" {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return DayBoundary{}, fmt.Errorf("unknown time zone %q: %w", zone, err)
+		}
+		day.Location = loc
+	}
+	return day, nil
+}
+
+// parseDayStart parses an "HH:MM" wall clock time into an offset from midnight
+func parseDayStart(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid day start %q, use HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String returns the day start and zone, e.g. "02:00 Europe/Berlin"
+func (b DayBoundary) String() string {
+	return fmt.Sprintf("%02d:%02d %s", int(b.Start/time.Hour), int(b.Start%time.Hour/time.Minute), b.location())
+}
+
+func (b DayBoundary) location() *time.Location {
+	if b.Location == nil {
+		return time.Local
+	}
+	return b.Location
+}
+
+// on returns the start of the given day. A start that falls into a daylight
+// saving gap is moved forward by the length of the gap, so the day starts
+// when the clocks go forward.
+func (b DayBoundary) on(year int, month time.Month, day int) time.Time {
+	hour, minute := int(b.Start/time.Hour), int(b.Start%time.Hour/time.Minute)
+	t := time.Date(year, month, day, hour, minute, 0, 0, b.location())
+
+	// time.Date may resolve a wall clock time in a gap to before the gap
+	want := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	got := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	if gap := want.Sub(got); gap > 0 {
+		t = t.Add(gap)
+	}
+	return t
+}
+
+// date returns the day t belongs to
+func (b DayBoundary) date(t time.Time) (int, time.Month, int) {
+	year, month, day := t.In(b.location()).Date()
+	if b.on(year, month, day).After(t) {
+		year, month, day = time.Date(year, month, day-1, 12, 0, 0, 0, time.UTC).Date()
+	}
+	return year, month, day
+}
+
+// StartOfDay returns the start of the day containing t
+func (b DayBoundary) StartOfDay(t time.Time) time.Time {
+	return b.on(b.date(t))
+}
+
+// Date returns the day t belongs to in DateFormat
+func (b DayBoundary) Date(t time.Time) string {
+	year, month, day := b.date(t)
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format(DateFormat)
+}
+
+// config holds the settings shared by every Store implementation
+type config struct {
+	now func() time.Time
+	day DayBoundary // Server-wide day boundary
+}
+
+// Option configures a Store when it is created
+type Option func(*config)
+
+// WithClock makes the store read the current time from now instead of time.Now
+func WithClock(now func() time.Time) Option {
+	return func(c *config) { c.now = now }
+}
+
+// WithDayBoundary sets the day boundary of metrics that do not have their own.
+// It defaults to local midnight.
+func WithDayBoundary(day DayBoundary) Option {
+	return func(c *config) { c.day = day }
+}
+
+func newConfig(opts []Option) config {
+	c := config{now: time.Now, day: DayBoundary{Location: time.Local}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// dayOf returns the day boundary of a metric: its own day start and zone,
+// falling back to the server-wide ones
+func (c config) dayOf(metric DBMetric) DayBoundary {
+	day, err := ParseDayBoundary(metric.DayStart, metric.Timezone, c.day)
+	if err != nil {
+		// Both are validated when they are stored
+		return c.day
+	}
+	return day
+}
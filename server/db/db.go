@@ -4,295 +4,678 @@ package db
 import (
 	"database/sql"
 	"fmt"
-	"log"
+	"strings"
 	"sync"
 	"time"
 
 	_ "modernc.org/sqlite" // SQLite driver
 )
 
-// Database encapsulates the SQLite connection and a mutex for thread safety
+// Database encapsulates the SQLite connection and a mutex for thread safety.
+// It is the default Store implementation.
 type Database struct {
 	conn *sql.DB
 	mu   sync.RWMutex
+	cfg  config
 }
 
 // DBMetric represents a metric stored in the database
 type DBMetric struct {
+	MetricName  string
+	Type        string
+	Unit        string
+	Kind        MetricKind // What the value measures, see MetricKind
+	Value       float64
+	Reset       ResetPolicy
+	DayStart    string      // "HH:MM" the metric's days start at; empty uses the server-wide start
+	Timezone    string      // IANA zone of DayStart; empty uses the server-wide zone
+	Day         DayBoundary `json:"-"` // Day boundary in effect, resolved from DayStart and Timezone on reads
+	Constraints Constraints // Values the metric accepts
+	Goal        Goal        // Target for the value in each period; the zero value is no goal
+	RestDays    Weekdays    // Days that do not break a streak when the goal is missed
+	LastReset   time.Time   // Boundary of the period the metric was last reset into
+	DeletedAt   time.Time   // Zero unless the metric is in the trash
+	Aliases     []string    // Former names still exported to Prometheus
+	Tags        Tags        // Free-form key/value pairs, exported as labels when allowed
+	Formula     string      // Expression a derived metric computes its value with, see Formula
+}
+
+// Operations recorded in the metric_events history
+const (
+	OpAdd       = "add"
+	OpIncrement = "increment"
+	OpDecrement = "decrement"
+	OpUpdate    = "update"
+	OpReset     = "reset"
+	OpDelete    = "delete"
+	OpRestore   = "restore"
+	OpEdit      = "edit"
+	OpUndo      = "undo"
+)
+
+// DBEvent represents a single mutation of a metric stored in the history
+type DBEvent struct {
+	ID         int64
 	MetricName string
-	Type       string
-	Unit       string
-	Value      float64
-	ResetDaily bool
-	LastReset  time.Time
+	Operation  string
+	Delta      float64
+	Value      float64 // Value of the metric after the mutation
+	OccurredAt time.Time
+	Note       string // Free text attached to an increment, decrement or update
+	Reverts    int64  // ID of the event an undo event takes back, 0 for every other event
+	Source     int64  // ID of the event of the linked metric the event was fanned out from, 0 for entries made directly
+	Reverted   bool   `json:"-"` // Whether an undo event took the event back; read from the undo event, not stored
+	SourceName string `json:"-"` // Metric of the Source event; read from it, not stored
 }
 
-// NewDatabase initializes a new Database instance
-func NewDatabase(dbPath string) (*Database, error) {
-	conn, err := sql.Open("sqlite", dbPath)
+// Entry is an increment, decrement or update of a metric
+type Entry struct {
+	MetricName string
+	Operation  string    // OpIncrement, OpDecrement or OpUpdate
+	Amount     float64   // Amount added or subtracted, or the new value of an update
+	Unit       string    // Unit of Amount, converted to the unit of the metric; empty for the metric's own
+	OccurredAt time.Time // When the entry happened; zero for now
+	Note       string
+	source     int64 // Event the entry was fanned out from, see fanOut
+}
+
+// NewDatabase opens the database and migrates it to the latest schema version
+func NewDatabase(dbPath string, opts ...Option) (*Database, error) {
+	db, err := OpenDatabase(dbPath, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	db := &Database{conn: conn}
+	latest, err := LatestVersion()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 
-	if err := db.init(); err != nil {
+	if err := db.MigrateTo(latest); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
 
-// init creates the metrics table if it doesn't exist
-func (db *Database) init() error {
-	createTableQuery := `
-	CREATE TABLE IF NOT EXISTS metrics (
-		metric_name TEXT PRIMARY KEY,
-		type TEXT NOT NULL,
-		unit TEXT NOT NULL,
-		value DOUBLE NOT NULL DEFAULT 0,
-		reset_daily BOOLEAN NOT NULL DEFAULT FALSE,
-		last_reset TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	_, err := db.conn.Exec(createTableQuery)
+// OpenDatabase opens the database without touching its schema
+func OpenDatabase(dbPath string, opts ...Option) (*Database, error) {
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Database{conn: conn, cfg: newConfig(opts)}, nil
+}
+
+// Close closes the underlying connection
+func (db *Database) Close() error {
+	return db.conn.Close()
+}
+
+// recordEvent appends a mutation to the metric_events history as part of tx
+func recordEvent(tx *sql.Tx, metricName, operation string, delta, value float64, occurredAt time.Time, note string) error {
+	_, err := insertEvent(tx, DBEvent{MetricName: metricName, Operation: operation, Delta: delta, Value: value, OccurredAt: occurredAt, Note: note})
+	return err
+}
+
+// insertEvent appends e to the metric_events history as part of tx and
+// returns the ID it was given
+func insertEvent(tx *sql.Tx, e DBEvent) (int64, error) {
+	insertQuery := `INSERT INTO metric_events (metric_name, operation, delta, value, occurred_at, note, reverts, source) VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
+
+	res, err := tx.Exec(insertQuery, e.MetricName, e.Operation, e.Delta, e.Value, e.OccurredAt.Unix(), e.Note, nullID(e.Reverts), nullID(e.Source))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record %s event: %w", e.Operation, err)
+	}
+	return res.LastInsertId()
+}
+
+// nullID stores an event ID that is 0 for none as NULL
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// validateDay rejects a per-metric day start or zone that cannot be parsed
+func validateDay(metric DBMetric) error {
+	_, err := ParseDayBoundary(metric.DayStart, metric.Timezone, DayBoundary{})
 	return err
 }
 
 // AddMetric inserts a new metric into the database
 func (db *Database) AddMetric(metric DBMetric) error {
+	if err := prepareMetric(&metric); err != nil {
+		return fmt.Errorf("failed to add metric: %w", err)
+	}
+
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	insertQuery := `INSERT INTO metrics (metric_name, type, unit, value, reset_daily, last_reset) VALUES (?, ?, ?, ?, ?, ?);`
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deletedAt sql.NullInt64
+	err = tx.QueryRow(`SELECT deleted_at FROM metrics WHERE metric_name = ?;`, metric.MetricName).Scan(&deletedAt)
+	if err == nil && deletedAt.Valid {
+		return fmt.Errorf("failed to add metric: metric %s is in the trash, restore or purge it first", metric.MetricName)
+	}
+	if err == nil {
+		return fmt.Errorf("failed to add metric: metric %s already exists", metric.MetricName)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to add metric: %w", err)
+	}
 
-	_, err := db.conn.Exec(insertQuery, metric.MetricName, metric.Type, metric.Unit, metric.Value, metric.ResetDaily, metric.LastReset)
+	owner, err := aliasOwner(tx, metric.MetricName)
 	if err != nil {
 		return fmt.Errorf("failed to add metric: %w", err)
 	}
+	if owner != "" {
+		return fmt.Errorf("failed to add metric: %s is an alias of metric %s", metric.MetricName, owner)
+	}
 
-	return nil
+	if metric.Kind == KindDerived {
+		formulas, err := liveFormulas(tx)
+		if err != nil {
+			return fmt.Errorf("failed to add metric: %w", err)
+		}
+		if _, err := checkFormulaChange(formulas, "", metric); err != nil {
+			return fmt.Errorf("failed to add metric: %w", err)
+		}
+	}
+
+	if err := dropHistory(tx, metric.MetricName); err != nil {
+		return fmt.Errorf("failed to add metric: %w", err)
+	}
+
+	now := db.cfg.now()
+	if err := insertMetric(tx, metric, now); err != nil {
+		return fmt.Errorf("failed to add metric: %w", err)
+	}
+
+	if err := recordEvent(tx, metric.MetricName, OpAdd, metric.Value, metric.Value, now, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// DeleteMetric removes a metric from the database by its name.
+// insertMetric writes the row of a new metric as part of tx. A zero LastReset
+// starts the periods of the metric at now.
+func insertMetric(tx *sql.Tx, metric DBMetric, now time.Time) error {
+	lastReset := metric.LastReset
+	if lastReset.IsZero() {
+		lastReset = now
+	}
+	var deletedAt sql.NullInt64
+	if !metric.DeletedAt.IsZero() {
+		deletedAt = sql.NullInt64{Int64: metric.DeletedAt.Unix(), Valid: true}
+	}
+
+	insertQuery := `
+	INSERT INTO metrics (metric_name, type, unit, kind, value, reset_policy, day_start, timezone, last_reset, deleted_at,
+		min_value, max_value, integer_only, step, allow_negative, goal_direction, goal_target, rest_days, formula)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	c := metric.Constraints
+	_, err := tx.Exec(insertQuery, metric.MetricName, metric.Type, metric.Unit, string(metric.Kind), metric.Value, metric.Reset.String(),
+		metric.DayStart, metric.Timezone, lastReset.Unix(), deletedAt, c.Min, c.Max, c.Integer, c.Step, c.AllowNegative,
+		string(metric.Goal.Direction), metric.Goal.Target, metric.RestDays.String(), metric.Formula)
+	if err != nil {
+		return err
+	}
+	return saveTags(tx, metric.MetricName, metric.Tags)
+}
+
+// DeleteMetric moves a metric to the trash. It disappears from GetMetrics and
+// the exporter but keeps its value and history until it is restored or purged.
 func (db *Database) DeleteMetric(metricName string) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	deleteQuery := `DELETE FROM metrics WHERE metric_name = ?;`
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := db.conn.Exec(deleteQuery, metricName)
+	var value float64
+	err = tx.QueryRow(`SELECT value FROM metrics WHERE metric_name = ? AND deleted_at IS NULL;`, metricName).Scan(&value)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("metric '%s' does not exist", metricName)
+		}
 		return fmt.Errorf("failed to delete metric: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	formulas, err := liveFormulas(tx)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected during delete: %w", err)
+		return fmt.Errorf("failed to delete metric: %w", err)
+	}
+	if users := formulaUsers(formulas, metricName); len(users) > 0 {
+		return fmt.Errorf("failed to delete metric: %s is used by the formula of %s", metricName, strings.Join(users, ", "))
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("metric '%s' does not exist", metricName)
+	now := db.cfg.now()
+	deleteQuery := `UPDATE metrics SET deleted_at = ? WHERE metric_name = ?;`
+
+	if _, err := tx.Exec(deleteQuery, now.Unix(), metricName); err != nil {
+		return fmt.Errorf("failed to delete metric: %w", err)
+	}
+
+	if err := recordEvent(tx, metricName, OpDelete, 0, value, now, ""); err != nil {
+		return err
 	}
 
+	return tx.Commit()
+}
+
+// UpdateMetric sets the value of a metric to a new specified value.
+// A zero occurredAt records the update at the current time.
+func (db *Database) UpdateMetric(metricName string, newValue float64, occurredAt time.Time, note string) error {
+	entry := Entry{MetricName: metricName, Operation: OpUpdate, Amount: newValue, OccurredAt: occurredAt, Note: note}
+	if _, err := db.ApplyEntries([]Entry{entry}); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
 	return nil
 }
 
-// UpdateMetric sets the value of a metric to a new specified value
-func (db *Database) UpdateMetric(metricName string, newValue float64) error {
+// resolveOccurredAt defaults a zero timestamp to now and rejects entries in the future
+func resolveOccurredAt(occurredAt, now time.Time) (time.Time, error) {
+	if occurredAt.IsZero() {
+		return now, nil
+	}
+	if occurredAt.After(now) {
+		return time.Time{}, fmt.Errorf("occurred_at %s is in the future", occurredAt.Format(time.RFC3339))
+	}
+	return occurredAt, nil
+}
+
+// isBackdated reports whether an entry belongs to a period that a reset has
+// already closed, in which case it is applied to the rollup of that period
+// instead of the live value.
+func isBackdated(policy ResetPolicy, day DayBoundary, occurredAt, now time.Time) bool {
+	return policy.Resets() && occurredAt.Before(policy.PeriodStart(now, day))
+}
+
+// ApplyEntries performs increments, decrements and updates as a single
+// transaction and returns the events recorded for them, in order, each
+// followed by the events of the entries it fanned out to through links.
+// Either every entry is applied or none is.
+func (db *Database) ApplyEntries(entries []Entry) ([]DBEvent, error) {
+	now := db.cfg.now()
+
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	// Update the metric's value and optionally update the last_reset time
-	updateQuery := `UPDATE metrics SET value = ?, last_reset = ? WHERE metric_name = ?;`
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	now := time.Now()
+	events := make([]DBEvent, 0, len(entries))
+	for _, entry := range entries {
+		event, err := db.applyEntry(tx, &entry, now)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+
+		links, err := linksFrom(tx, entry.MetricName)
+		if err != nil {
+			return nil, err
+		}
+		for _, fanned := range fanOut(entry, event.ID, links) {
+			event, err := db.applyEntry(tx, &fanned, now)
+			if err != nil {
+				return nil, fmt.Errorf("link from %s: %w", entry.MetricName, err)
+			}
+			events = append(events, event)
+		}
+	}
 
-	result, err := db.conn.Exec(updateQuery, newValue, now, metricName)
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// applyEntry performs an increment, decrement or update as part of tx. The
+// new value is computed by SQLite from the stored one, so concurrent entries
+// can never overwrite each other, and the mutation is recorded in the history
+// with its note as part of the same write. An amount given in another unit is
+// converted in entry to the unit of the metric.
+func (db *Database) applyEntry(tx *sql.Tx, entry *Entry, now time.Time) (DBEvent, error) {
+	occurredAt, err := resolveOccurredAt(entry.OccurredAt, now)
+	if err != nil {
+		return DBEvent{}, err
+	}
+	note, err := CleanNote(entry.Note)
 	if err != nil {
-		return fmt.Errorf("failed to update metric: %w", err)
+		return DBEvent{}, err
 	}
+	metricName, amount := entry.MetricName, entry.Amount
 
-	rowsAffected, err := result.RowsAffected()
+	query := `SELECT ` + metricColumns + ` FROM metrics WHERE metric_name = ? AND deleted_at IS NULL;`
+	metric, err := scanMetric(tx.QueryRow(query, metricName))
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected during update: %w", err)
+		if err == sql.ErrNoRows {
+			return DBEvent{}, fmt.Errorf("metric %s does not exist", metricName)
+		}
+		return DBEvent{}, fmt.Errorf("failed to read metric: %w", err)
+	}
+	if err := metric.convertEntry(entry); err != nil {
+		return DBEvent{}, err
 	}
+	amount = entry.Amount
+	oldValue := metric.Value
+	day := db.cfg.dayOf(metric)
+	event := DBEvent{MetricName: metricName, Operation: entry.Operation, OccurredAt: time.Unix(occurredAt.Unix(), 0), Note: note, Source: entry.source}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("metric %s does not exist", metricName)
+	if isBackdated(metric.Reset, day, occurredAt, now) {
+		oldValue, newValue, err := applyToRollup(tx, metric, day, entry.Operation, amount, occurredAt)
+		if err != nil {
+			return DBEvent{}, err
+		}
+		event.Delta, event.Value = newValue-oldValue, newValue
+		event.ID, err = insertEvent(tx, event)
+		return event, err
 	}
 
-	return nil
+	var updateQuery string
+	var args []any
+	switch entry.Operation {
+	case OpIncrement:
+		updateQuery = `UPDATE metrics SET value = value + ? WHERE metric_name = ? RETURNING value;`
+		args = []any{amount, metricName}
+	case OpDecrement:
+		updateQuery = `UPDATE metrics SET value = value - ? WHERE metric_name = ? RETURNING value;`
+		args = []any{amount, metricName}
+	case OpUpdate:
+		updateQuery = `UPDATE metrics SET value = ? WHERE metric_name = ? RETURNING value;`
+		args = []any{amount, metricName}
+	default:
+		return DBEvent{}, fmt.Errorf("unknown operation %s", entry.Operation)
+	}
+
+	var newValue float64
+	if err := tx.QueryRow(updateQuery, args...).Scan(&newValue); err != nil {
+		return DBEvent{}, fmt.Errorf("failed to update metric: %w", err)
+	}
+	// The transaction is rolled back if the new value breaks the kind or constraints
+	if err := metric.checkEntry(oldValue, newValue); err != nil {
+		return DBEvent{}, fmt.Errorf("metric %s %w", metricName, err)
+	}
+
+	event.Delta, event.Value = newValue-oldValue, newValue
+	event.ID, err = insertEvent(tx, event)
+	return event, err
 }
 
-// GetMetrics retrieves all metrics from the database
-func (db *Database) GetMetrics() ([]DBMetric, error) {
+// eventColumns lists the columns scanned by scanEvent, in order. Whether an
+// event was reverted is looked up through the undo event pointing at it, the
+// metric of its source through the source event.
+const eventColumns = `id, metric_name, operation, delta, value, occurred_at, note, COALESCE(reverts, 0), COALESCE(source, 0),
+	EXISTS (SELECT 1 FROM metric_events AS undo WHERE undo.reverts = metric_events.id),
+	COALESCE((SELECT source_event.metric_name FROM metric_events AS source_event WHERE source_event.id = metric_events.source), '')`
+
+// scanEvent reads an event selected with eventColumns
+func scanEvent(row rowScanner) (DBEvent, error) {
+	var e DBEvent
+	var occurredAt int64
+	err := row.Scan(&e.ID, &e.MetricName, &e.Operation, &e.Delta, &e.Value, &occurredAt, &e.Note, &e.Reverts, &e.Source, &e.Reverted, &e.SourceName)
+	e.OccurredAt = time.Unix(occurredAt, 0)
+	return e, err
+}
+
+// GetMetricHistory retrieves recorded mutations, newest first. An empty
+// metricName matches every metric, zero start/end leave the range open and a
+// limit of 0 or less returns every matching event. Derived metrics are
+// computed on read and have no events.
+func (db *Database) GetMetricHistory(metricName string, start, end time.Time, limit int) ([]DBEvent, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
-	query := `SELECT metric_name, type, unit, value, reset_daily, last_reset FROM metrics;`
-	rows, err := db.conn.Query(query)
+	return db.queryEvents("", nil, metricName, start, end, limit)
+}
+
+// queryEvents returns the events matching the SQL condition where, if any,
+// and the filters of GetMetricHistory, newest first. The caller must hold
+// db.mu.
+func (db *Database) queryEvents(where string, args []any, metricName string, start, end time.Time, limit int) ([]DBEvent, error) {
+	query := `SELECT ` + eventColumns + ` FROM metric_events WHERE 1 = 1`
+	if where != "" {
+		query += ` AND ` + where
+	}
+	if metricName != "" {
+		query += ` AND metric_name = ?`
+		args = append(args, metricName)
+	}
+	if !start.IsZero() {
+		query += ` AND occurred_at >= ?`
+		args = append(args, start.Unix())
+	}
+	if !end.IsZero() {
+		query += ` AND occurred_at < ?`
+		args = append(args, end.Unix())
+	}
+	query += ` ORDER BY occurred_at DESC, id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query metrics: %w", err)
+		return nil, fmt.Errorf("failed to query metric history: %w", err)
 	}
 	defer rows.Close()
 
-	var metrics []DBMetric
+	var events []DBEvent
 	for rows.Next() {
-		var m DBMetric
-		var lastResetStr string
-		if err := rows.Scan(&m.MetricName, &m.Type, &m.Unit, &m.Value, &m.ResetDaily, &lastResetStr); err != nil {
-			return nil, fmt.Errorf("failed to scan metric: %w", err)
-		}
-		m.LastReset, err = time.Parse("2006-01-02 15:04:05", lastResetStr)
+		e, err := scanEvent(rows)
 		if err != nil {
-			// If parsing fails, default to current time
-			m.LastReset = time.Now()
+			return nil, fmt.Errorf("failed to scan metric event: %w", err)
 		}
-		metrics = append(metrics, m)
+		events = append(events, e)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("row iteration error: %w", err)
 	}
 
-	return metrics, nil
+	return events, nil
 }
 
-// GetMetric retrieves a single metric by its name
-func (db *Database) GetMetric(metricName string) (*DBMetric, error) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+// liveFormulas returns the formula of every metric that is not in the trash
+// as part of tx, "" for metrics that are not derived
+func liveFormulas(tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.Query(`SELECT metric_name, formula FROM metrics WHERE deleted_at IS NULL;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query formulas: %w", err)
+	}
+	defer rows.Close()
+
+	formulas := make(map[string]string)
+	for rows.Next() {
+		var name, formula string
+		if err := rows.Scan(&name, &formula); err != nil {
+			return nil, fmt.Errorf("failed to scan formula: %w", err)
+		}
+		formulas[name] = formula
+	}
+	return formulas, rows.Err()
+}
 
-	query := `SELECT metric_name, type, unit, value, reset_daily, last_reset FROM metrics WHERE metric_name = ?;`
-	row := db.conn.QueryRow(query, metricName)
+// metricColumns lists the columns scanned by scanMetric, in order. The driver
+// turns values of TIMESTAMP columns into time.Time, so last_reset, which holds
+// Unix seconds, is read through a cast.
+const metricColumns = `metric_name, type, unit, kind, value, reset_policy, day_start, timezone, CAST(last_reset AS INTEGER), deleted_at,
+	min_value, max_value, integer_only, step, allow_negative, goal_direction, goal_target, rest_days, formula`
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanMetric reads a row selected with metricColumns
+func scanMetric(row rowScanner) (DBMetric, error) {
 	var m DBMetric
-	var lastResetStr string
-	if err := row.Scan(&m.MetricName, &m.Type, &m.Unit, &m.Value, &m.ResetDaily, &lastResetStr); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("metric %s does not exist", metricName)
-		}
-		return nil, fmt.Errorf("failed to scan metric: %w", err)
+	var lastReset int64
+	var deletedAt sql.NullInt64
+	var minValue, maxValue sql.NullFloat64
+	c := &m.Constraints
+	if err := row.Scan(&m.MetricName, &m.Type, &m.Unit, &m.Kind, &m.Value, &m.Reset, &m.DayStart, &m.Timezone, &lastReset, &deletedAt,
+		&minValue, &maxValue, &c.Integer, &c.Step, &c.AllowNegative, &m.Goal.Direction, &m.Goal.Target, &m.RestDays, &m.Formula); err != nil {
+		return m, err
 	}
-	var err error
-	m.LastReset, err = time.Parse("2006-01-02 15:04:05", lastResetStr)
-	if err != nil {
-		m.LastReset = time.Now()
+	if minValue.Valid {
+		c.Min = &minValue.Float64
+	}
+	if maxValue.Valid {
+		c.Max = &maxValue.Float64
 	}
 
-	return &m, nil
+	m.LastReset = time.Unix(lastReset, 0)
+	if deletedAt.Valid {
+		m.DeletedAt = time.Unix(deletedAt.Int64, 0)
+	}
+	return m, nil
 }
 
-// IncrementMetric increases the value of a metric by a specified amount
-func (db *Database) IncrementMetric(metricName string, increment float64) error {
+// queryMetrics retrieves the metrics matching the given WHERE condition in
+// the given order. Both are fixed SQL fragments; values go through args.
+func (db *Database) queryMetrics(where, orderBy string, args ...any) ([]DBMetric, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	// Retrieve the current metric
-	metric, err := db.GetMetric(metricName)
+	query := `SELECT ` + metricColumns + ` FROM metrics WHERE ` + where + ` ORDER BY ` + orderBy + `;`
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
-		return fmt.Errorf("increment failed: %w", err)
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []DBMetric
+	for rows.Next() {
+		m, err := scanMetric(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan metric: %w", err)
+		}
+		metrics = append(metrics, m)
 	}
 
-	// Calculate the new value
-	newValue := metric.Value + increment
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
 
-	// Update the metric with the new value
-	err = db.UpdateMetric(metricName, newValue)
+	aliases, err := db.loadAliases()
 	if err != nil {
-		return fmt.Errorf("failed to update metric after incrementing: %w", err)
+		return nil, err
+	}
+	tags, err := db.loadTags()
+	if err != nil {
+		return nil, err
+	}
+	for i := range metrics {
+		metrics[i].Aliases = aliases[metrics[i].MetricName]
+		metrics[i].Tags = tags[metrics[i].MetricName]
+		metrics[i].Day = db.cfg.dayOf(metrics[i])
 	}
 
-	return nil
+	return metrics, nil
 }
 
-// DecrementMetric decreases the value of a metric by a specified amount
-func (db *Database) DecrementMetric(metricName string, decrement float64) error {
-
-	// Retrieve the current metric
-	metric, err := db.GetMetric(metricName)
+// GetMetrics retrieves all metrics from the database that are not in the
+// trash, with the values of derived metrics computed from the others
+func (db *Database) GetMetrics() ([]DBMetric, error) {
+	metrics, err := db.queryMetrics(`deleted_at IS NULL`, `metric_name`)
 	if err != nil {
-		return fmt.Errorf("decrement failed: %w", err)
+		return nil, err
 	}
+	deriveValues(metrics)
+	return metrics, nil
+}
 
-	// Calculate the new value
-	newValue := metric.Value - decrement
-
-	// Ensure that the new value does not go below zero
-	if newValue < 0 {
-		return fmt.Errorf("decrement failed: metric %s value cannot be negative", metricName)
+// GetMetric retrieves a single metric by its name
+func (db *Database) GetMetric(metricName string) (*DBMetric, error) {
+	m, err := db.getMetric(metricName)
+	if err != nil || m.Kind != KindDerived {
+		return m, err
 	}
 
-	// Update the metric with the new value
-	err = db.UpdateMetric(metricName, newValue)
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := `SELECT ` + metricColumns + ` FROM metrics WHERE metric_name = ? AND deleted_at IS NULL;`
+	err = deriveMetric(m, func(metricName string) (DBMetric, bool, error) {
+		ref, err := scanMetric(db.conn.QueryRow(query, metricName))
+		if err == sql.ErrNoRows {
+			return ref, false, nil
+		}
+		if err != nil {
+			return ref, false, fmt.Errorf("failed to scan metric: %w", err)
+		}
+		return ref, true, nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update metric after decrementing: %v", err)
+		return nil, err
 	}
-
-	return nil
+	return m, nil
 }
 
-// ResetDailyMetrics resets all metrics that are marked to reset daily and haven't been reset today
-func (db *Database) ResetDailyMetrics() error {
+// getMetric retrieves a single metric by its name as it is stored
+func (db *Database) getMetric(metricName string) (*DBMetric, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	// Get all metrics
-	metrics, err := db.GetMetrics()
+	query := `SELECT ` + metricColumns + ` FROM metrics WHERE metric_name = ? AND deleted_at IS NULL;`
+	m, err := scanMetric(db.conn.QueryRow(query, metricName))
 	if err != nil {
-		return fmt.Errorf("failed to retrieve metrics: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("metric %s does not exist", metricName)
+		}
+		return nil, fmt.Errorf("failed to scan metric: %w", err)
 	}
 
-	// Iterate over the metrics and reset those that are marked to reset daily
-	for _, metric := range metrics {
-		if metric.ResetDaily {
-			// Reset the metric's value to 0 and update the last reset time
-			err := db.UpdateMetric(metric.MetricName, 0)
-			if err != nil {
-				log.Printf("Failed to reset metric %s: %v", metric.MetricName, err)
-			} else {
-				log.Printf("Reset metric %s to 0", metric.MetricName)
-			}
-		}
+	aliases, err := db.loadAliases()
+	if err != nil {
+		return nil, err
 	}
+	m.Aliases = aliases[m.MetricName]
+	tags, err := db.loadTags()
+	if err != nil {
+		return nil, err
+	}
+	m.Tags = tags[m.MetricName]
+	m.Day = db.cfg.dayOf(m)
 
-	return nil
+	return &m, nil
 }
 
-// StartDailyResetScheduler starts a scheduler that resets daily metrics at midnight using Go channels.
-func (db *Database) StartDailyResetScheduler(stopChan chan bool) {
-	// Define a function to schedule the next reset
-	var scheduleReset func()
-	scheduleReset = func() {
-		now := time.Now().Local()
-		nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
-		durationUntilMidnight := nextMidnight.Sub(now)
-
-		// Schedule the ResetDailyMetrics function to execute at midnight
-		timer := time.AfterFunc(durationUntilMidnight, func() {
-			select {
-			case <-stopChan:
-				log.Println("Stopping the daily reset scheduler.")
-				return
-			default:
-				// Call ResetDailyMetrics to reset the metrics
-				if err := db.ResetDailyMetrics(); err != nil {
-					log.Printf("Error resetting daily metrics: %v", err)
-				} else {
-					log.Println("Successfully reset daily metrics at midnight.")
-				}
-				// Reschedule for the next midnight
-				scheduleReset()
-			}
-		})
-
-		// Listen on the stopChan to cancel the timer if needed
-		go func() {
-			<-stopChan
-			if !timer.Stop() {
-				<-timer.C
-			}
-			log.Println("Stopping the daily reset scheduler.")
-		}()
+// IncrementMetric increases the value of a metric by a specified amount.
+// A zero occurredAt records the increment at the current time.
+func (db *Database) IncrementMetric(metricName string, increment float64, occurredAt time.Time, note string) error {
+	entry := Entry{MetricName: metricName, Operation: OpIncrement, Amount: increment, OccurredAt: occurredAt, Note: note}
+	if _, err := db.ApplyEntries([]Entry{entry}); err != nil {
+		return fmt.Errorf("increment failed: %w", err)
 	}
+	return nil
+}
 
-	// Start the scheduling
-	scheduleReset()
+// DecrementMetric decreases the value of a metric by a specified amount.
+// The value of a metric can never go below zero.
+// A zero occurredAt records the decrement at the current time.
+func (db *Database) DecrementMetric(metricName string, decrement float64, occurredAt time.Time, note string) error {
+	entry := Entry{MetricName: metricName, Operation: OpDecrement, Amount: decrement, OccurredAt: occurredAt, Note: note}
+	if _, err := db.ApplyEntries([]Entry{entry}); err != nil {
+		return fmt.Errorf("decrement failed: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,243 @@
+// edit.go
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MetricEdit describes the changes EditMetric makes to a metric. Empty strings
+// and nil pointers leave the corresponding setting untouched.
+type MetricEdit struct {
+	NewName     string
+	Type        string
+	Unit        string
+	Kind        MetricKind // Empty keeps the current kind
+	Reset       *ResetPolicy
+	DayStart    *string // An empty day start or zone falls back to the server-wide one
+	Timezone    *string
+	Constraints *Constraints // Replaces all constraints; the current value has to meet them
+	Goal        *Goal        // Replaces the goal; a goal without direction removes it
+	RestDays    *Weekdays    // Replaces the rest days of the streak
+	Tags        *Tags        // Replaces all tags
+	Formula     string       // Replaces the formula of a derived metric
+	KeepAlias   bool         // Keep exporting the metric under its old name after a rename
+}
+
+// renames reports whether the edit gives metricName a different name
+func (e MetricEdit) renames(metricName string) bool {
+	return e.NewName != "" && e.NewName != metricName
+}
+
+// applyTo copies the requested settings onto metric, renaming it if needed.
+// A new reset policy or day boundary starts counting its periods at now, so
+// the value logged so far is not mistaken for a reset the new periods missed.
+func (e MetricEdit) applyTo(metric *DBMetric, now time.Time) {
+	if e.NewName != "" {
+		metric.MetricName = e.NewName
+	}
+	if e.Type != "" {
+		metric.Type = e.Type
+	}
+	if e.Unit != "" {
+		metric.Unit = e.Unit
+	}
+	if e.Kind != "" {
+		metric.Kind = e.Kind
+	}
+	if e.Reset != nil && *e.Reset != metric.Reset {
+		metric.Reset = *e.Reset
+		metric.LastReset = now
+	}
+	if e.DayStart != nil && *e.DayStart != metric.DayStart {
+		metric.DayStart = *e.DayStart
+		metric.LastReset = now
+	}
+	if e.Timezone != nil && *e.Timezone != metric.Timezone {
+		metric.Timezone = *e.Timezone
+		metric.LastReset = now
+	}
+	if e.Constraints != nil {
+		metric.Constraints = *e.Constraints
+	}
+	if e.Goal != nil {
+		metric.Goal = *e.Goal
+	}
+	if e.RestDays != nil {
+		metric.RestDays = *e.RestDays
+	}
+	if e.Tags != nil {
+		metric.Tags = *e.Tags
+	}
+	if e.Formula != "" {
+		metric.Formula = e.Formula
+	}
+}
+
+// checkKindChange rejects an edit that turns a derived metric into one that
+// takes entries or the other way around, as neither has a value the other
+// could start from
+func checkKindChange(before, after DBMetric) error {
+	if (before.Kind == KindDerived) != (after.Kind == KindDerived) {
+		return fmt.Errorf("a metric cannot become derived or stop being derived, add a new metric instead")
+	}
+	return nil
+}
+
+// EditMetric changes the type, unit, reset policy, day boundary and
+// constraints of a metric and optionally renames it. A rename carries the
+// value, history and daily rollups over to the new name.
+func (db *Database) EditMetric(metricName string, edit MetricEdit) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + metricColumns + ` FROM metrics WHERE metric_name = ? AND deleted_at IS NULL;`
+	metric, err := scanMetric(tx.QueryRow(query, metricName))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("metric '%s' does not exist", metricName)
+		}
+		return fmt.Errorf("failed to edit metric: %w", err)
+	}
+	before := metric
+	now := db.cfg.now()
+	edit.applyTo(&metric, now)
+	if err := checkKindChange(before, metric); err != nil {
+		return fmt.Errorf("failed to edit metric: %w", err)
+	}
+	if err := prepareMetric(&metric); err != nil {
+		return fmt.Errorf("failed to edit metric: %w", err)
+	}
+
+	// Formulas that refer to the metric follow it to its new name
+	formulas, err := liveFormulas(tx)
+	if err != nil {
+		return fmt.Errorf("failed to edit metric: %w", err)
+	}
+	rewritten, err := checkFormulaChange(formulas, metricName, metric)
+	if err != nil {
+		return fmt.Errorf("failed to edit metric: %w", err)
+	}
+	for name, formula := range rewritten {
+		if _, err := tx.Exec(`UPDATE metrics SET formula = ? WHERE metric_name = ?;`, formula, name); err != nil {
+			return fmt.Errorf("failed to edit metric: %w", err)
+		}
+	}
+
+	if edit.renames(metricName) {
+		if err := renameMetric(tx, metricName, edit.NewName, edit.KeepAlias); err != nil {
+			return err
+		}
+	}
+
+	updateQuery := `
+	UPDATE metrics SET type = ?, unit = ?, kind = ?, reset_policy = ?, day_start = ?, timezone = ?, last_reset = ?,
+		min_value = ?, max_value = ?, integer_only = ?, step = ?, allow_negative = ?, goal_direction = ?, goal_target = ?, rest_days = ?, formula = ?
+	WHERE metric_name = ?;`
+	c := metric.Constraints
+	_, err = tx.Exec(updateQuery, metric.Type, metric.Unit, string(metric.Kind), metric.Reset.String(), metric.DayStart, metric.Timezone,
+		metric.LastReset.Unix(), c.Min, c.Max, c.Integer, c.Step, c.AllowNegative,
+		string(metric.Goal.Direction), metric.Goal.Target, metric.RestDays.String(), metric.Formula, metric.MetricName)
+	if err != nil {
+		return fmt.Errorf("failed to edit metric: %w", err)
+	}
+
+	if edit.Tags != nil {
+		if err := saveTags(tx, metric.MetricName, metric.Tags); err != nil {
+			return err
+		}
+	}
+
+	if err := recordEvent(tx, metric.MetricName, OpEdit, 0, metric.Value, now, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// renameMetric moves a metric and everything recorded about it to newName as part of tx
+func renameMetric(tx *sql.Tx, oldName, newName string, keepAlias bool) error {
+	owner, err := aliasOwner(tx, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename metric: %w", err)
+	}
+	if owner != "" && owner != oldName {
+		return fmt.Errorf("failed to rename metric: %s is an alias of metric %s", newName, owner)
+	}
+
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM metrics WHERE metric_name = ?;`, newName).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to rename metric: %w", err)
+	}
+	if exists > 0 {
+		return fmt.Errorf("failed to rename metric: metric %s already exists", newName)
+	}
+
+	if err := dropHistory(tx, newName); err != nil {
+		return fmt.Errorf("failed to rename metric: %w", err)
+	}
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE metrics SET metric_name = ? WHERE metric_name = ?;`, []any{newName, oldName}},
+		{`UPDATE metric_events SET metric_name = ? WHERE metric_name = ?;`, []any{newName, oldName}},
+		{`UPDATE daily_rollups SET metric_name = ? WHERE metric_name = ?;`, []any{newName, oldName}},
+		{`UPDATE metric_aliases SET metric_name = ? WHERE metric_name = ?;`, []any{newName, oldName}},
+		{`UPDATE metric_tags SET metric_name = ? WHERE metric_name = ?;`, []any{newName, oldName}},
+		{`UPDATE metric_links SET source = ? WHERE source = ?;`, []any{newName, oldName}},
+		{`UPDATE metric_links SET target = ? WHERE target = ?;`, []any{newName, oldName}},
+		{`DELETE FROM metric_aliases WHERE alias = ?;`, []any{newName}},
+	}
+	if keepAlias {
+		statements = append(statements, struct {
+			query string
+			args  []any
+		}{`INSERT INTO metric_aliases (alias, metric_name) VALUES (?, ?);`, []any{oldName, newName}})
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			return fmt.Errorf("failed to rename metric: %w", err)
+		}
+	}
+	return nil
+}
+
+// aliasOwner returns the metric that exports name as an alias, or "" if there is none
+func aliasOwner(tx *sql.Tx, name string) (string, error) {
+	var owner string
+	err := tx.QueryRow(`SELECT metric_name FROM metric_aliases WHERE alias = ?;`, name).Scan(&owner)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return owner, err
+}
+
+// loadAliases returns the aliases of every metric keyed by metric name.
+// The caller must hold db.mu.
+func (db *Database) loadAliases() (map[string][]string, error) {
+	rows, err := db.conn.Query(`SELECT alias, metric_name FROM metric_aliases ORDER BY alias;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := make(map[string][]string)
+	for rows.Next() {
+		var alias, metricName string
+		if err := rows.Scan(&alias, &metricName); err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+		aliases[metricName] = append(aliases[metricName], alias)
+	}
+	return aliases, rows.Err()
+}
@@ -0,0 +1,628 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Formula is the parsed expression of a derived metric, computing its value
+// from the values of other metrics, e.g. `espresso*63 + drip_coffee*95`.
+//
+// Formulas know numbers, metric names, the operators + - * / % with the usual
+// precedence, parentheses, the comparisons < <= > >= == != and the logical
+// && || !, which give 1 for true and 0 for false, and the functions min, max,
+// abs, round, floor, ceil and if(condition, then, else). A name that is not a
+// plain identifier, or that clashes with a function, is written in double
+// quotes: "green tea" * 2. Dividing by zero gives 0, so a ratio of inputs
+// that were just reset reads 0 instead of breaking the export.
+type Formula struct {
+	root formulaNode
+	refs []string // Names of the metrics the formula reads, sorted
+}
+
+// formulaFuncs maps the functions a formula can call to the number of
+// arguments they take, -1 for one or more
+var formulaFuncs = map[string]int{"min": -1, "max": -1, "abs": 1, "round": 1, "floor": 1, "ceil": 1, "if": 3}
+
+// ParseFormula parses the formula of a derived metric
+func ParseFormula(s string) (*Formula, error) {
+	tokens, err := lexFormula(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &formulaParser{tokens: tokens, refs: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEnd {
+		return nil, tok.unexpected()
+	}
+
+	f := &Formula{root: root}
+	for name := range p.refs {
+		f.refs = append(f.refs, name)
+	}
+	slices.Sort(f.refs)
+	return f, nil
+}
+
+// Refs returns the names of the metrics the formula reads, sorted
+func (f *Formula) Refs() []string {
+	return f.refs
+}
+
+// Eval computes the formula, reading the value of each metric it refers to
+// through value
+func (f *Formula) Eval(value func(metricName string) float64) float64 {
+	return f.root.eval(value)
+}
+
+// tokenKind tells the tokens of a formula apart
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenName   // Identifier, a metric name or the name of a function
+	tokenQuoted // Metric name in double quotes
+	tokenOp     // Operator, parenthesis or comma
+)
+
+// formulaToken is a token of a formula and the bytes it spans
+type formulaToken struct {
+	kind       tokenKind
+	text       string // Literal, name without quotes or operator
+	start, end int
+}
+
+func (t formulaToken) unexpected() error {
+	if t.kind == tokenEnd {
+		return fmt.Errorf("formula ends too early")
+	}
+	return fmt.Errorf("unexpected %q at position %d of the formula", t.text, t.start+1)
+}
+
+// formulaOps lists the operators of formulas, longest first so <= is not
+// read as < followed by =
+var formulaOps = []string{"<=", ">=", "==", "!=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ","}
+
+// lexFormula splits a formula into tokens, ending with a tokenEnd
+func lexFormula(s string) ([]formulaToken, error) {
+	var tokens []formulaToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			j := i
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+				j++
+			}
+			// Exponents such as 1e-3
+			if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+				k := j + 1
+				if k < len(s) && (s[k] == '+' || s[k] == '-') {
+					k++
+				}
+				if k < len(s) && s[k] >= '0' && s[k] <= '9' {
+					j = k
+					for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+						j++
+					}
+				}
+			}
+			if _, err := strconv.ParseFloat(s[i:j], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d of the formula", s[i:j], i+1)
+			}
+			tokens = append(tokens, formulaToken{tokenNumber, s[i:j], i, j})
+			i = j
+		case isNameStart(rune(c)):
+			j := i
+			for j < len(s) && isNamePart(rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, formulaToken{tokenName, s[i:j], i, j})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated name at position %d of the formula", i+1)
+			}
+			name, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid name %s at position %d of the formula", s[i:j+1], i+1)
+			}
+			tokens = append(tokens, formulaToken{tokenQuoted, name, i, j + 1})
+			i = j + 1
+		default:
+			op := ""
+			for _, candidate := range formulaOps {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d of the formula", c, i+1)
+			}
+			tokens = append(tokens, formulaToken{tokenOp, op, i, i + len(op)})
+			i += len(op)
+		}
+	}
+	return append(tokens, formulaToken{kind: tokenEnd, start: len(s), end: len(s)}), nil
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+func isNamePart(r rune) bool {
+	return isNameStart(r) || r >= '0' && r <= '9'
+}
+
+// formulaRef writes a metric name the way a formula refers to it, in quotes
+// unless it is a plain identifier that is not a function
+func formulaRef(metricName string) string {
+	plain := metricName != "" && isNameStart(rune(metricName[0])) && strings.IndexFunc(metricName, func(r rune) bool { return !isNamePart(r) }) < 0
+	if _, isFunc := formulaFuncs[metricName]; plain && !isFunc {
+		return metricName
+	}
+	return strconv.Quote(metricName)
+}
+
+// renameFormulaRef rewrites formula so its references to oldName read newName,
+// leaving everything else as written
+func renameFormulaRef(formula, oldName, newName string) (string, error) {
+	tokens, err := lexFormula(formula)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	last := 0
+	for i, tok := range tokens {
+		isCall := tok.kind == tokenName && tokens[i+1].text == "("
+		if (tok.kind == tokenName || tok.kind == tokenQuoted) && !isCall && tok.text == oldName {
+			b.WriteString(formula[last:tok.start])
+			b.WriteString(formulaRef(newName))
+			last = tok.end
+		}
+	}
+	b.WriteString(formula[last:])
+	return b.String(), nil
+}
+
+// formulaParser is a recursive descent parser over the tokens of a formula
+type formulaParser struct {
+	tokens []formulaToken
+	pos    int
+	refs   map[string]bool
+}
+
+func (p *formulaParser) peek() formulaToken {
+	return p.tokens[p.pos]
+}
+
+func (p *formulaParser) next() formulaToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEnd {
+		p.pos++
+	}
+	return tok
+}
+
+// acceptOp consumes the next token if it is one of ops and returns it
+func (p *formulaParser) acceptOp(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind == tokenOp && slices.Contains(ops, tok.text) {
+		p.pos++
+		return tok.text, true
+	}
+	return "", false
+}
+
+func (p *formulaParser) expectOp(op string) error {
+	if _, ok := p.acceptOp(op); !ok {
+		return p.peek().unexpected()
+	}
+	return nil
+}
+
+// parseBinary parses operands joined by any of ops, left to right
+func (p *formulaParser) parseBinary(operand func() (formulaNode, error), ops ...string) (formulaNode, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp(ops...)
+		if !ok {
+			return x, nil
+		}
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		x = binaryNode{op, x, y}
+	}
+}
+
+func (p *formulaParser) parseOr() (formulaNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *formulaParser) parseAnd() (formulaNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+// parseComparison parses a single comparison, as a < b < c reads like it
+// means something it does not
+func (p *formulaParser) parseComparison() (formulaNode, error) {
+	comparisons := []string{"<", "<=", ">", ">=", "==", "!="}
+	x, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp(comparisons...)
+	if !ok {
+		return x, nil
+	}
+	y, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind == tokenOp && slices.Contains(comparisons, tok.text) {
+		return nil, fmt.Errorf("comparisons cannot be chained at position %d of the formula, join them with &&", tok.start+1)
+	}
+	return binaryNode{op, x, y}, nil
+}
+
+func (p *formulaParser) parseSum() (formulaNode, error) {
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *formulaParser) parseProduct() (formulaNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *formulaParser) parseUnary() (formulaNode, error) {
+	if op, ok := p.acceptOp("-", "+", "!"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op, x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *formulaParser) parsePrimary() (formulaNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		v, _ := strconv.ParseFloat(tok.text, 64)
+		return numberNode(v), nil
+	case tokenQuoted:
+		p.refs[tok.text] = true
+		return refNode(tok.text), nil
+	case tokenName:
+		if _, ok := p.acceptOp("("); ok {
+			return p.parseCall(tok)
+		}
+		p.refs[tok.text] = true
+		return refNode(tok.text), nil
+	case tokenOp:
+		if tok.text == "(" {
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expectOp(")")
+		}
+	}
+	return nil, tok.unexpected()
+}
+
+// parseCall parses the arguments of a call to the function named by fn,
+// after its opening parenthesis
+func (p *formulaParser) parseCall(fn formulaToken) (formulaNode, error) {
+	arity, ok := formulaFuncs[fn.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d of the formula", fn.text, fn.start+1)
+	}
+	call := callNode{fn: fn.text}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if _, ok := p.acceptOp(","); !ok {
+			break
+		}
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	if arity >= 0 && len(call.args) != arity {
+		return nil, fmt.Errorf("%s takes %d arguments, not %d, at position %d of the formula", fn.text, arity, len(call.args), fn.start+1)
+	}
+	return call, nil
+}
+
+// formulaNode is a node of the syntax tree of a formula
+type formulaNode interface {
+	eval(value func(metricName string) float64) float64
+}
+
+type numberNode float64
+
+func (n numberNode) eval(func(string) float64) float64 { return float64(n) }
+
+type refNode string
+
+func (n refNode) eval(value func(string) float64) float64 { return value(string(n)) }
+
+type unaryNode struct {
+	op string
+	x  formulaNode
+}
+
+func (n unaryNode) eval(value func(string) float64) float64 {
+	x := n.x.eval(value)
+	switch n.op {
+	case "-":
+		return -x
+	case "!":
+		return truth(x == 0)
+	}
+	return x
+}
+
+type binaryNode struct {
+	op   string
+	x, y formulaNode
+}
+
+func (n binaryNode) eval(value func(string) float64) float64 {
+	x, y := n.x.eval(value), n.y.eval(value)
+	switch n.op {
+	case "+":
+		return x + y
+	case "-":
+		return x - y
+	case "*":
+		return x * y
+	case "/":
+		if y == 0 {
+			return 0
+		}
+		return x / y
+	case "%":
+		if y == 0 {
+			return 0
+		}
+		return math.Mod(x, y)
+	case "<":
+		return truth(x < y)
+	case "<=":
+		return truth(x <= y)
+	case ">":
+		return truth(x > y)
+	case ">=":
+		return truth(x >= y)
+	case "==":
+		return truth(x == y)
+	case "!=":
+		return truth(x != y)
+	case "&&":
+		return truth(x != 0 && y != 0)
+	case "||":
+		return truth(x != 0 || y != 0)
+	}
+	panic("unknown operator " + n.op)
+}
+
+type callNode struct {
+	fn   string
+	args []formulaNode
+}
+
+func (n callNode) eval(value func(string) float64) float64 {
+	if n.fn == "if" {
+		if n.args[0].eval(value) != 0 {
+			return n.args[1].eval(value)
+		}
+		return n.args[2].eval(value)
+	}
+
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.eval(value)
+	}
+	switch n.fn {
+	case "min":
+		return slices.Min(args)
+	case "max":
+		return slices.Max(args)
+	case "abs":
+		return math.Abs(args[0])
+	case "round":
+		return math.Round(args[0])
+	case "floor":
+		return math.Floor(args[0])
+	case "ceil":
+		return math.Ceil(args[0])
+	}
+	panic("unknown function " + n.fn)
+}
+
+// truth turns a condition into the 1 or 0 formulas compute with
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// checkFormula returns an error unless the formula of the derived metric
+// metricName only refers to live metrics and does not depend on itself, even
+// through other derived metrics. formulas maps the name of each live metric to
+// its formula, or to "" if it is not derived.
+func checkFormula(formulas map[string]string, metricName string) error {
+	f, err := ParseFormula(formulas[metricName])
+	if err != nil {
+		return fmt.Errorf("formula of %s: %w", metricName, err)
+	}
+	for _, ref := range f.Refs() {
+		if _, ok := formulas[ref]; !ok {
+			return fmt.Errorf("formula of %s refers to unknown metric %s", metricName, ref)
+		}
+	}
+
+	const visiting, done = 1, 2
+	state := make(map[string]int)
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			cycle := append(path[slices.Index(path, name):], name)
+			return fmt.Errorf("formula of %s depends on itself: %s", name, strings.Join(cycle, " -> "))
+		}
+		state[name] = visiting
+		if f, err := ParseFormula(formulas[name]); formulas[name] != "" && err == nil {
+			for _, ref := range f.Refs() {
+				if err := visit(ref, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	return visit(metricName, nil)
+}
+
+// formulaUsers returns the derived metrics in formulas, keyed like for
+// checkFormula, whose formula refers to metricName, sorted
+func formulaUsers(formulas map[string]string, metricName string) []string {
+	var users []string
+	for name, formula := range formulas {
+		if f, err := ParseFormula(formula); err == nil && slices.Contains(f.Refs(), metricName) {
+			users = append(users, name)
+		}
+	}
+	slices.Sort(users)
+	return users
+}
+
+// checkFormulaChange checks the formula of a metric that is added, edited or
+// restored as changed, and returns the formulas of the other metrics that
+// have to be rewritten because it was renamed from oldName. formulas holds the
+// live metrics beforehand, keyed like for checkFormula; oldName is "" for a
+// metric that was not live.
+func checkFormulaChange(formulas map[string]string, oldName string, changed DBMetric) (map[string]string, error) {
+	rewritten := make(map[string]string)
+	if oldName != "" && oldName != changed.MetricName {
+		delete(formulas, oldName)
+		for _, user := range formulaUsers(formulas, oldName) {
+			formula, err := renameFormulaRef(formulas[user], oldName, changed.MetricName)
+			if err != nil {
+				return nil, err
+			}
+			formulas[user], rewritten[user] = formula, formula
+		}
+	}
+	formulas[changed.MetricName] = changed.Formula
+	if changed.Formula != "" {
+		if err := checkFormula(formulas, changed.MetricName); err != nil {
+			return nil, err
+		}
+	}
+	return rewritten, nil
+}
+
+// deriveValues sets the value of every derived metric among metrics from the
+// values of the metrics its formula refers to, deriving those first when they
+// are derived themselves. Formulas are checked when they are stored, but an
+// imported bundle can still bring one that refers to a missing metric or to
+// itself; such references read 0.
+func deriveValues(metrics []DBMetric) {
+	index := make(map[string]int, len(metrics))
+	for i, m := range metrics {
+		index[m.MetricName] = i
+	}
+
+	const visiting, done = 1, 2
+	state := make(map[string]int)
+	var derive func(i int)
+	value := func(metricName string) float64 {
+		i, ok := index[metricName]
+		if !ok || state[metricName] == visiting {
+			return 0
+		}
+		derive(i)
+		return metrics[i].Value
+	}
+	derive = func(i int) {
+		m := &metrics[i]
+		if m.Kind != KindDerived || state[m.MetricName] == done {
+			return
+		}
+		state[m.MetricName] = visiting
+		m.Value = 0
+		if f, err := ParseFormula(m.Formula); err == nil {
+			m.Value = f.Eval(value)
+		}
+		state[m.MetricName] = done
+	}
+	for i := range metrics {
+		derive(i)
+	}
+}
+
+// deriveMetric computes the value of the derived metric m from only the
+// metrics its formula refers to, directly or through other derived metrics.
+// lookup reads a live metric as stored and reports whether it exists.
+func deriveMetric(m *DBMetric, lookup func(metricName string) (DBMetric, bool, error)) error {
+	metrics := []DBMetric{*m}
+	seen := map[string]bool{m.MetricName: true}
+	for i := 0; i < len(metrics); i++ {
+		if metrics[i].Kind != KindDerived {
+			continue
+		}
+		f, err := ParseFormula(metrics[i].Formula)
+		if err != nil {
+			continue
+		}
+		for _, ref := range f.Refs() {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			other, ok, err := lookup(ref)
+			if err != nil {
+				return err
+			}
+			if ok {
+				metrics = append(metrics, other)
+			}
+		}
+	}
+	deriveValues(metrics)
+	m.Value = metrics[0].Value
+	return nil
+}
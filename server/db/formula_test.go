@@ -0,0 +1,140 @@
+package db
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormulaEval(t *testing.T) {
+	values := map[string]float64{"espresso": 2, "drip_coffee": 1, "green tea": 3, "min": 7}
+	value := func(name string) float64 { return values[name] }
+
+	for _, tt := range []struct {
+		formula string
+		want    float64
+		refs    []string
+	}{
+		{"espresso*63 + drip_coffee*95", 221, []string{"drip_coffee", "espresso"}},
+		{"(espresso + drip_coffee) * 2 - -1", 7, []string{"drip_coffee", "espresso"}},
+		{"2 + 3 * 4 % 5 / 2", 3, nil},
+		{`"green tea" * 1.5e1`, 45, []string{"green tea"}},
+		{`min(espresso, drip_coffee, 5) + max(1, "min")`, 8, []string{"drip_coffee", "espresso", "min"}},
+		{"abs(drip_coffee - espresso) + round(2.5) + floor(1.9) + ceil(1.1)", 7, []string{"drip_coffee", "espresso"}},
+		{"if(espresso > 1 && !(drip_coffee >= 2), 10, 20)", 10, []string{"drip_coffee", "espresso"}},
+		{"espresso == 2 || espresso != 2", 1, []string{"espresso"}},
+		{"espresso / (drip_coffee - 1) + espresso % 0", 0, []string{"drip_coffee", "espresso"}},
+	} {
+		f, err := ParseFormula(tt.formula)
+		if err != nil {
+			t.Errorf("ParseFormula(%q) failed: %v", tt.formula, err)
+			continue
+		}
+		if got := f.Eval(value); got != tt.want {
+			t.Errorf("%q = %g, want %g", tt.formula, got, tt.want)
+		}
+		if !slices.Equal(f.Refs(), tt.refs) {
+			t.Errorf("%q refers to %q, want %q", tt.formula, f.Refs(), tt.refs)
+		}
+	}
+
+	for _, formula := range []string{"", "espresso +", "(espresso", "espresso)", "sqrt(4)", "if(1, 2)", "1 < 2 < 3", `"open`, "espresso # 2", "1..2"} {
+		if _, err := ParseFormula(formula); err == nil {
+			t.Errorf("ParseFormula(%q) succeeded, want an error", formula)
+		}
+	}
+}
+
+func TestRenameFormulaRef(t *testing.T) {
+	for _, tt := range []struct {
+		formula, oldName, newName, want string
+	}{
+		{"espresso*63 + max(espresso, 1)", "espresso", "ristretto", "ristretto*63 + max(ristretto, 1)"},
+		{`"green tea" + tea`, "green tea", "tea leaves", `"tea leaves" + tea`},
+		{"tea + teapot", "tea", "min", `"min" + teapot`},
+		{"max(1, 2)", "max", "top", "max(1, 2)"},
+	} {
+		got, err := renameFormulaRef(tt.formula, tt.oldName, tt.newName)
+		if err != nil || got != tt.want {
+			t.Errorf("renaming %s to %s in %q = %q, %v, want %q", tt.oldName, tt.newName, tt.formula, got, err, tt.want)
+		}
+	}
+}
+
+func TestDerivedMetrics(t *testing.T) {
+	sqlite, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer sqlite.Close()
+
+	for _, store := range []Store{sqlite, NewMemoryStore()} {
+		value := func(name string) float64 {
+			t.Helper()
+			m, err := store.GetMetric(name)
+			if err != nil {
+				t.Fatalf("%T: GetMetric(%s) failed: %v", store, name, err)
+			}
+			return m.Value
+		}
+		must := func(step string, err error) {
+			t.Helper()
+			if err != nil {
+				t.Fatalf("%T: %s failed: %v", store, step, err)
+			}
+		}
+		rejects := func(step, want string, err error) {
+			t.Helper()
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%T: %s = %v, want an error containing %q", store, step, err, want)
+			}
+		}
+
+		must("add espresso", store.AddMetric(DBMetric{MetricName: "espresso", Type: "Food", Unit: "cups", Kind: KindCounter}))
+		must("add drip", store.AddMetric(DBMetric{MetricName: "drip_coffee", Type: "Food", Unit: "cups", Kind: KindCounter}))
+		must("add caffeine", store.AddMetric(DBMetric{MetricName: "caffeine_mg", Type: "Food", Unit: "mg", Kind: KindDerived,
+			Formula: "espresso*63 + drip_coffee*95"}))
+		must("add limit", store.AddMetric(DBMetric{MetricName: "over_limit", Type: "Food", Unit: "", Kind: KindDerived,
+			Formula: "caffeine_mg > 400"}))
+		must("increment espresso", store.IncrementMetric("espresso", 2, time.Time{}, ""))
+		must("increment drip", store.IncrementMetric("drip_coffee", 3, time.Time{}, ""))
+		if got := value("caffeine_mg"); got != 411 {
+			t.Errorf("%T: caffeine_mg = %g, want 411", store, got)
+		}
+		if got := value("over_limit"); got != 1 {
+			t.Errorf("%T: over_limit = %g, want 1", store, got)
+		}
+
+		rejects("add unknown reference", "unknown metric tea", store.AddMetric(DBMetric{MetricName: "tea_mg", Kind: KindDerived, Formula: "tea*40"}))
+		rejects("add self reference", "depends on itself", store.AddMetric(DBMetric{MetricName: "loop", Kind: KindDerived, Formula: "loop + 1"}))
+		rejects("edit into a cycle", "caffeine_mg -> over_limit -> caffeine_mg",
+			store.EditMetric("caffeine_mg", MetricEdit{Formula: "espresso*63 + over_limit"}))
+		rejects("reset policy", "cannot be reset", store.AddMetric(DBMetric{MetricName: "daily_mg", Kind: KindDerived,
+			Formula: "espresso", Reset: ResetPolicy{Kind: ResetDaily}}))
+		rejects("formula on a gauge", "only derived", store.AddMetric(DBMetric{MetricName: "gauge", Formula: "espresso"}))
+		rejects("increment", "cannot be changed by hand", store.IncrementMetric("caffeine_mg", 1, time.Time{}, ""))
+		rejects("kind change", "cannot become derived", store.EditMetric("caffeine_mg", MetricEdit{Kind: KindGauge}))
+		rejects("delete input", "used by the formula of caffeine_mg", store.DeleteMetric("espresso"))
+
+		// Formulas follow a renamed input
+		must("rename drip", store.EditMetric("drip_coffee", MetricEdit{NewName: "filter coffee"}))
+		m, err := store.GetMetric("caffeine_mg")
+		must("get caffeine", err)
+		if m.Formula != `espresso*63 + "filter coffee"*95` || m.Value != 411 {
+			t.Errorf("%T: after renaming an input caffeine_mg = %q with %g", store, m.Formula, m.Value)
+		}
+
+		// A restored derived metric needs its inputs
+		must("delete limit", store.DeleteMetric("over_limit"))
+		must("delete caffeine", store.DeleteMetric("caffeine_mg"))
+		must("delete espresso", store.DeleteMetric("espresso"))
+		rejects("restore caffeine", "unknown metric espresso", store.RestoreMetric("caffeine_mg"))
+		must("restore espresso", store.RestoreMetric("espresso"))
+		must("restore caffeine", store.RestoreMetric("caffeine_mg"))
+		if got := value("caffeine_mg"); got != 411 {
+			t.Errorf("%T: restored caffeine_mg = %g, want 411", store, got)
+		}
+	}
+}
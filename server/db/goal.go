@@ -0,0 +1,101 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// GoalDirection says whether a goal is reached by staying at or above its
+// target or at or below it
+type GoalDirection string
+
+// Goal directions understood by ParseGoalDirection
+const (
+	GoalNone    GoalDirection = ""         // The metric has no goal
+	GoalAtLeast GoalDirection = "at_least" // Reached once the value is at or above the target
+	GoalAtMost  GoalDirection = "at_most"  // Kept while the value stays at or below the target
+)
+
+// Goal is a target for the value of a metric. It covers the same periods as
+// the metric's reset policy, e.g. "at least 8 glasses" on a daily metric is a
+// daily goal; a metric that never resets has one goal for its whole life. The
+// zero value is no goal.
+type Goal struct {
+	Direction GoalDirection
+	Target    float64
+}
+
+// ParseGoalDirection parses "at_least" or "at_most", also written with a
+// hyphen or a space. An empty name or "none" is no goal.
+func ParseGoalDirection(s string) (GoalDirection, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	name = strings.NewReplacer("-", "_", " ", "_").Replace(name)
+	switch GoalDirection(name) {
+	case GoalNone, "none":
+		return GoalNone, nil
+	case GoalAtLeast, GoalAtMost:
+		return GoalDirection(name), nil
+	default:
+		return "", fmt.Errorf("unknown goal direction %q, expected at_least, at_most or none", s)
+	}
+}
+
+// Set reports whether the goal has a direction
+func (g Goal) Set() bool {
+	return g.Direction != GoalNone
+}
+
+// Validate rejects a goal whose target is not a positive number
+func (g Goal) Validate() error {
+	if !g.Set() {
+		return nil
+	}
+	if _, err := ParseGoalDirection(string(g.Direction)); err != nil {
+		return err
+	}
+	if g.Target <= 0 || math.IsNaN(g.Target) || math.IsInf(g.Target, 0) {
+		return fmt.Errorf("goal target %g must be a positive number", g.Target)
+	}
+	return nil
+}
+
+// Met reports whether value reaches an at_least goal or stays within an
+// at_most one. No goal is never met.
+func (g Goal) Met(value float64) bool {
+	switch g.Direction {
+	case GoalAtLeast:
+		return value >= g.Target
+	case GoalAtMost:
+		return value <= g.Target
+	default:
+		return false
+	}
+}
+
+// GoalProgress is how far the current period of a metric is toward its goal
+type GoalProgress struct {
+	MetricName  string
+	Goal        Goal
+	Value       float64   // Value of the metric so far in the period
+	Ratio       float64   // Value divided by the target; above 1 breaks an at_most goal
+	Met         bool      // The value reaches an at_least goal or keeps within an at_most one
+	PeriodStart time.Time // Zero when the metric never resets
+	PeriodEnd   time.Time // Zero when the metric never resets
+}
+
+// GoalProgress returns the progress of m toward its goal in the period
+// containing now. It must only be called on metrics with a goal.
+func (m DBMetric) GoalProgress(now time.Time) GoalProgress {
+	progress := GoalProgress{
+		MetricName:  m.MetricName,
+		Goal:        m.Goal,
+		Value:       m.Value,
+		Ratio:       m.Value / m.Goal.Target,
+		PeriodStart: m.Reset.PeriodStart(now, m.Day),
+		PeriodEnd:   m.Reset.Next(now, m.Day),
+	}
+	progress.Met = m.Goal.Met(m.Value)
+	return progress
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// JournalOp is a change made through the server that Undo can take back and
+// Redo apply again. Stores keep the journal so it survives a restart.
+type JournalOp struct {
+	ID         int64 // Order in which the ops were made, assigned by SaveJournalOp
+	ClientID   string
+	MetricName string
+	Kind       string // OpAdd, OpDelete or the operation of Entry
+	Entry      Entry  // Increment, decrement or update applied again on Redo, at the time it first occurred
+	EventID    int64  // Event recorded for Entry, reverted on Undo together with what it fanned out to
+	Undone     int    // Order in which the op was undone, 0 while it is applied
+}
+
+// GetJournal returns the ops of the undo journal, oldest first
+func (db *Database) GetJournal() ([]JournalOp, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rows, err := db.conn.Query(`SELECT id, client_id, metric_name, kind, amount, unit, occurred_at, note, event_id, undone
+		FROM undo_journal ORDER BY id;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query undo journal: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []JournalOp
+	for rows.Next() {
+		var op JournalOp
+		var occurredAt, eventID sql.NullInt64
+		err := rows.Scan(&op.ID, &op.ClientID, &op.MetricName, &op.Kind, &op.Entry.Amount, &op.Entry.Unit, &occurredAt, &op.Entry.Note, &eventID, &op.Undone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan undo journal: %w", err)
+		}
+		if op.Kind != OpAdd && op.Kind != OpDelete {
+			op.Entry.MetricName, op.Entry.Operation = op.MetricName, op.Kind
+		}
+		if occurredAt.Valid {
+			op.Entry.OccurredAt = time.Unix(occurredAt.Int64, 0)
+		}
+		op.EventID = eventID.Int64
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
+
+// SaveJournalOp adds an op to the undo journal, or updates it when it has an
+// ID, and removes the ops drop in the same transaction. It returns the ID of
+// op.
+func (db *Database) SaveJournalOp(op JournalOp, drop []int64) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var occurredAt sql.NullInt64
+	if !op.Entry.OccurredAt.IsZero() {
+		occurredAt = sql.NullInt64{Int64: op.Entry.OccurredAt.Unix(), Valid: true}
+	}
+	args := []any{op.ClientID, op.MetricName, op.Kind, op.Entry.Amount, op.Entry.Unit, occurredAt, op.Entry.Note, nullID(op.EventID), op.Undone}
+
+	if op.ID == 0 {
+		res, err := tx.Exec(`INSERT INTO undo_journal (client_id, metric_name, kind, amount, unit, occurred_at, note, event_id, undone)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save undo journal: %w", err)
+		}
+		if op.ID, err = res.LastInsertId(); err != nil {
+			return 0, fmt.Errorf("failed to save undo journal: %w", err)
+		}
+	} else {
+		_, err := tx.Exec(`UPDATE undo_journal SET client_id = ?, metric_name = ?, kind = ?, amount = ?, unit = ?, occurred_at = ?,
+			note = ?, event_id = ?, undone = ? WHERE id = ?;`, append(args, op.ID)...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save undo journal: %w", err)
+		}
+	}
+
+	for _, id := range drop {
+		if _, err := tx.Exec(`DELETE FROM undo_journal WHERE id = ?;`, id); err != nil {
+			return 0, fmt.Errorf("failed to delete from undo journal: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to save undo journal: %w", err)
+	}
+	return op.ID, nil
+}
+
+// DeleteJournalOps removes ops from the undo journal
+func (db *Database) DeleteJournalOps(ids []int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, id := range ids {
+		if _, err := db.conn.Exec(`DELETE FROM undo_journal WHERE id = ?;`, id); err != nil {
+			return fmt.Errorf("failed to delete from undo journal: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetricKind says what the value of a metric measures, which decides how it
+// is entered and which entries it accepts
+type MetricKind string
+
+// Metric kinds understood by ParseMetricKind
+const (
+	KindCounter  MetricKind = "counter"  // Only goes up until it is reset
+	KindGauge    MetricKind = "gauge"    // Holds the latest reading and accepts any entry
+	KindBoolean  MetricKind = "boolean"  // 1 for yes and 0 for no, toggled by updates
+	KindRating   MetricKind = "rating"   // Whole number between a minimum and a maximum, 1 to 5 unless set
+	KindDuration MetricKind = "duration" // Length of time in seconds
+	KindDerived  MetricKind = "derived"  // Computed from other metrics by its formula, never entered
+)
+
+// ParseMetricKind parses the name of a kind. An empty name is a gauge, which
+// is how metrics behaved before they had kinds.
+func ParseMetricKind(s string) (MetricKind, error) {
+	kind := MetricKind(strings.ToLower(strings.TrimSpace(s)))
+	switch kind {
+	case "":
+		return KindGauge, nil
+	case KindCounter, KindGauge, KindBoolean, KindRating, KindDuration, KindDerived:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown metric kind %q, expected counter, gauge, boolean, rating, duration or derived", s)
+	}
+}
+
+// applyTo sets the constraints and unit that come with the kind: booleans
+// are 0 or 1, ratings are whole numbers in a range and durations count
+// seconds. Other settings are left as they are.
+func (k MetricKind) applyTo(metric *DBMetric) {
+	c := &metric.Constraints
+	switch k {
+	case KindBoolean:
+		no, yes := 0.0, 1.0
+		*c = Constraints{Min: &no, Max: &yes, Integer: true}
+	case KindRating:
+		c.Integer = true
+		if c.Min == nil {
+			lowest := 1.0
+			c.Min = &lowest
+		}
+		if c.Max == nil {
+			highest := 5.0
+			c.Max = &highest
+		}
+	case KindDuration:
+		metric.Unit = "seconds"
+	}
+}
+
+// checkEntry returns an error if an entry that changes the value of m from
+// old to value breaks its kind or constraints. The error completes a
+// sentence starting with the metric's name.
+func (m DBMetric) checkEntry(old, value float64) error {
+	if m.Kind == KindDerived {
+		return fmt.Errorf("is derived from other metrics and cannot be changed by hand")
+	}
+	if m.Kind == KindCounter && value < old {
+		return fmt.Errorf("is a counter and cannot go down until it is reset")
+	}
+	return m.Constraints.Check(value)
+}
+
+// prepareMetric applies the kind of a metric to its settings and rejects
+// settings that cannot be stored
+func prepareMetric(metric *DBMetric) error {
+	kind, err := ParseMetricKind(string(metric.Kind))
+	if err != nil {
+		return err
+	}
+	metric.Kind = kind
+	kind.applyTo(metric)
+
+	if err := validateFormula(*metric); err != nil {
+		return err
+	}
+	if err := validateDay(*metric); err != nil {
+		return err
+	}
+	if err := metric.Goal.Validate(); err != nil {
+		return fmt.Errorf("invalid goal: %w", err)
+	}
+	if err := metric.Tags.validate(); err != nil {
+		return err
+	}
+	return validateConstraints(*metric)
+}
+
+// validateFormula rejects a derived metric without a valid formula or with a
+// reset policy, as its value follows its inputs, and a formula on any other
+// kind. Whether the formula refers to live metrics is checked by the store.
+func validateFormula(metric DBMetric) error {
+	if metric.Kind != KindDerived {
+		if metric.Formula != "" {
+			return fmt.Errorf("only derived metrics have a formula")
+		}
+		return nil
+	}
+	if strings.TrimSpace(metric.Formula) == "" {
+		return fmt.Errorf("a derived metric needs a formula")
+	}
+	if _, err := ParseFormula(metric.Formula); err != nil {
+		return err
+	}
+	if metric.Reset.Resets() {
+		return fmt.Errorf("a derived metric cannot be reset, its value follows the metrics it is computed from")
+	}
+	return nil
+}
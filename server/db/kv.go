@@ -0,0 +1,1341 @@
+// kv.go
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Buckets used by the key-value stores. Values are JSON encoded.
+const (
+	metricsBucket = "metrics" // metric name -> DBMetric
+	eventsBucket  = "events"  // zero padded event id -> DBEvent
+	rollupsBucket = "rollups" // metric name + "\x00" + day -> DBRollup
+	linksBucket   = "links"   // source + "\x00" + target -> Link
+	revertsBucket = "reverts" // zero padded id of a reverted event -> ID of the undo event
+	journalBucket = "journal" // zero padded op id -> JournalOp
+)
+
+var kvBuckets = []string{metricsBucket, eventsBucket, rollupsBucket, linksBucket, revertsBucket, journalBucket}
+
+// kvBackend is an ordered key-value storage with serializable transactions
+type kvBackend interface {
+	view(fn func(tx kvTx) error) error
+	update(fn func(tx kvTx) error) error
+	close() error
+}
+
+// kvTx is a read or read-write transaction of a kvBackend. Writes are only
+// visible to other transactions once update returns without an error.
+type kvTx interface {
+	get(bucket, key string) []byte
+	put(bucket, key string, value []byte) error
+	delete(bucket, key string) error
+	// forEach visits every entry of bucket in key order
+	forEach(bucket string, fn func(key string, value []byte) error) error
+	nextID(bucket string) (int64, error)
+}
+
+// kvStore implements Store on top of a kvBackend so the in-memory and bbolt
+// stores share the same semantics as the SQLite Database.
+type kvStore struct {
+	backend kvBackend
+	cfg     config
+}
+
+func eventKey(id int64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+func rollupKey(metricName, day string) string {
+	return metricName + "\x00" + day
+}
+
+func linkKey(source, target string) string {
+	return source + "\x00" + target
+}
+
+// getJSON decodes the value stored under key into v and reports whether it exists
+func getJSON(tx kvTx, bucket, key string, v any) (bool, error) {
+	data := tx.get(bucket, key)
+	if data == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode %s/%q: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+// putJSON stores v under key
+func putJSON(tx kvTx, bucket, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%q: %w", bucket, key, err)
+	}
+	return tx.put(bucket, key, data)
+}
+
+// kvRecordEvent appends a mutation to the history as part of tx
+func kvRecordEvent(tx kvTx, metricName, operation string, delta, value float64, occurredAt time.Time, note string) error {
+	_, err := kvInsertEvent(tx, DBEvent{MetricName: metricName, Operation: operation, Delta: delta, Value: value, OccurredAt: occurredAt, Note: note})
+	return err
+}
+
+// kvInsertEvent appends e to the history as part of tx under a new ID and
+// returns it as stored. An undo event also marks the event it reverts.
+func kvInsertEvent(tx kvTx, e DBEvent) (DBEvent, error) {
+	id, err := tx.nextID(eventsBucket)
+	if err != nil {
+		return DBEvent{}, fmt.Errorf("failed to record %s event: %w", e.Operation, err)
+	}
+	e.ID = id
+	e.OccurredAt = time.Unix(e.OccurredAt.Unix(), 0)
+	if err := putJSON(tx, eventsBucket, eventKey(id), e); err != nil {
+		return DBEvent{}, err
+	}
+	if e.Reverts != 0 {
+		if err := putJSON(tx, revertsBucket, eventKey(e.Reverts), id); err != nil {
+			return DBEvent{}, err
+		}
+	}
+	return e, nil
+}
+
+// kvDecodeEvent decodes an event stored under key and looks up whether it was
+// reverted and the metric of its source
+func kvDecodeEvent(tx kvTx, key string, value []byte) (DBEvent, error) {
+	var e DBEvent
+	if err := json.Unmarshal(value, &e); err != nil {
+		return e, fmt.Errorf("failed to decode event %q: %w", key, err)
+	}
+	e.Reverted = tx.get(revertsBucket, key) != nil
+	if e.Source != 0 {
+		var source DBEvent
+		if _, err := getJSON(tx, eventsBucket, eventKey(e.Source), &source); err != nil {
+			return e, err
+		}
+		e.SourceName = source.MetricName
+	}
+	return e, nil
+}
+
+// UnmarshalJSON decodes a metric stored by the key-value stores, including
+// metrics written before reset policies replaced the ResetDaily flag and
+// before metrics had kinds and constraints
+func (m *DBMetric) UnmarshalJSON(data []byte) error {
+	type plain DBMetric
+	var stored struct {
+		plain
+		ResetDaily  bool
+		Constraints *Constraints
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	*m = DBMetric(stored.plain)
+	if stored.ResetDaily && m.Reset.Kind == "" {
+		m.Reset = ResetPolicy{Kind: ResetDaily}
+	}
+	if m.Kind == "" {
+		m.Kind = KindGauge
+	}
+	if stored.Constraints != nil {
+		m.Constraints = *stored.Constraints
+	} else {
+		// Like migration 0007, a metric that already went negative may stay so
+		m.Constraints.AllowNegative = m.Value < 0
+	}
+	return nil
+}
+
+// kvLiveMetric reads a metric that is not in the trash and reports whether it exists
+func kvLiveMetric(tx kvTx, metricName string) (DBMetric, bool, error) {
+	var metric DBMetric
+	ok, err := getJSON(tx, metricsBucket, metricName, &metric)
+	if err != nil || !ok {
+		return metric, false, err
+	}
+	return metric, metric.DeletedAt.IsZero(), nil
+}
+
+// kvLiveFormulas returns the formula of every metric that is not in the trash
+// as part of tx, "" for metrics that are not derived
+func kvLiveFormulas(tx kvTx) (map[string]string, error) {
+	formulas := make(map[string]string)
+	err := tx.forEach(metricsBucket, func(key string, value []byte) error {
+		var m DBMetric
+		if err := json.Unmarshal(value, &m); err != nil {
+			return fmt.Errorf("failed to decode metric %q: %w", key, err)
+		}
+		if m.DeletedAt.IsZero() {
+			formulas[m.MetricName] = m.Formula
+		}
+		return nil
+	})
+	return formulas, err
+}
+
+// AddMetric inserts a new metric
+func (s *kvStore) AddMetric(metric DBMetric) error {
+	if err := prepareMetric(&metric); err != nil {
+		return fmt.Errorf("failed to add metric: %w", err)
+	}
+
+	return s.backend.update(func(tx kvTx) error {
+		var existing DBMetric
+		ok, err := getJSON(tx, metricsBucket, metric.MetricName, &existing)
+		if err != nil {
+			return fmt.Errorf("failed to add metric: %w", err)
+		}
+		if ok && !existing.DeletedAt.IsZero() {
+			return fmt.Errorf("failed to add metric: metric %s is in the trash, restore or purge it first", metric.MetricName)
+		}
+		if ok {
+			return fmt.Errorf("failed to add metric: metric %s already exists", metric.MetricName)
+		}
+		owner, err := kvAliasOwner(tx, metric.MetricName)
+		if err != nil {
+			return fmt.Errorf("failed to add metric: %w", err)
+		}
+		if owner != "" {
+			return fmt.Errorf("failed to add metric: %s is an alias of metric %s", metric.MetricName, owner)
+		}
+		if metric.Kind == KindDerived {
+			formulas, err := kvLiveFormulas(tx)
+			if err != nil {
+				return fmt.Errorf("failed to add metric: %w", err)
+			}
+			if _, err := checkFormulaChange(formulas, "", metric); err != nil {
+				return fmt.Errorf("failed to add metric: %w", err)
+			}
+		}
+		if err := kvDropHistory(tx, metric.MetricName); err != nil {
+			return fmt.Errorf("failed to add metric: %w", err)
+		}
+		now := s.cfg.now()
+		if metric.LastReset.IsZero() {
+			metric.LastReset = now
+		}
+		metric.LastReset = time.Unix(metric.LastReset.Unix(), 0)
+		if err := putJSON(tx, metricsBucket, metric.MetricName, metric); err != nil {
+			return fmt.Errorf("failed to add metric: %w", err)
+		}
+		return kvRecordEvent(tx, metric.MetricName, OpAdd, metric.Value, metric.Value, now, "")
+	})
+}
+
+// DeleteMetric moves a metric to the trash
+func (s *kvStore) DeleteMetric(metricName string) error {
+	return s.backend.update(func(tx kvTx) error {
+		metric, ok, err := kvLiveMetric(tx, metricName)
+		if err != nil {
+			return fmt.Errorf("failed to delete metric: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("metric '%s' does not exist", metricName)
+		}
+		formulas, err := kvLiveFormulas(tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete metric: %w", err)
+		}
+		if users := formulaUsers(formulas, metricName); len(users) > 0 {
+			return fmt.Errorf("failed to delete metric: %s is used by the formula of %s", metricName, strings.Join(users, ", "))
+		}
+		now := s.cfg.now()
+		metric.DeletedAt = time.Unix(now.Unix(), 0)
+		if err := putJSON(tx, metricsBucket, metricName, metric); err != nil {
+			return fmt.Errorf("failed to delete metric: %w", err)
+		}
+		return kvRecordEvent(tx, metricName, OpDelete, 0, metric.Value, now, "")
+	})
+}
+
+// filterMetrics retrieves the metrics accepted by keep, ordered by name
+func (s *kvStore) filterMetrics(keep func(m DBMetric) bool) ([]DBMetric, error) {
+	var metrics []DBMetric
+	err := s.backend.view(func(tx kvTx) error {
+		return tx.forEach(metricsBucket, func(key string, value []byte) error {
+			var m DBMetric
+			if err := json.Unmarshal(value, &m); err != nil {
+				return fmt.Errorf("failed to decode metric %q: %w", key, err)
+			}
+			if keep(m) {
+				m.Day = s.cfg.dayOf(m)
+				metrics = append(metrics, m)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	return metrics, nil
+}
+
+// GetMetrics retrieves all metrics that are not in the trash, ordered by
+// name, with the values of derived metrics computed from the others
+func (s *kvStore) GetMetrics() ([]DBMetric, error) {
+	metrics, err := s.filterMetrics(func(m DBMetric) bool { return m.DeletedAt.IsZero() })
+	if err != nil {
+		return nil, err
+	}
+	deriveValues(metrics)
+	return metrics, nil
+}
+
+// GetMetric retrieves a single metric by its name
+func (s *kvStore) GetMetric(metricName string) (*DBMetric, error) {
+	var m DBMetric
+	var ok bool
+	err := s.backend.view(func(tx kvTx) error {
+		var err error
+		m, ok, err = kvLiveMetric(tx, metricName)
+		if err != nil || !ok || m.Kind != KindDerived {
+			return err
+		}
+		return deriveMetric(&m, func(metricName string) (DBMetric, bool, error) {
+			return kvLiveMetric(tx, metricName)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("metric %s does not exist", metricName)
+	}
+	m.Day = s.cfg.dayOf(m)
+	return &m, nil
+}
+
+// UpdateMetric sets the value of a metric to a new specified value
+func (s *kvStore) UpdateMetric(metricName string, newValue float64, occurredAt time.Time, note string) error {
+	entry := Entry{MetricName: metricName, Operation: OpUpdate, Amount: newValue, OccurredAt: occurredAt, Note: note}
+	if _, err := s.ApplyEntries([]Entry{entry}); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+	return nil
+}
+
+// IncrementMetric increases the value of a metric by a specified amount
+func (s *kvStore) IncrementMetric(metricName string, increment float64, occurredAt time.Time, note string) error {
+	entry := Entry{MetricName: metricName, Operation: OpIncrement, Amount: increment, OccurredAt: occurredAt, Note: note}
+	if _, err := s.ApplyEntries([]Entry{entry}); err != nil {
+		return fmt.Errorf("increment failed: %w", err)
+	}
+	return nil
+}
+
+// DecrementMetric decreases the value of a metric by a specified amount without going below zero
+func (s *kvStore) DecrementMetric(metricName string, decrement float64, occurredAt time.Time, note string) error {
+	entry := Entry{MetricName: metricName, Operation: OpDecrement, Amount: decrement, OccurredAt: occurredAt, Note: note}
+	if _, err := s.ApplyEntries([]Entry{entry}); err != nil {
+		return fmt.Errorf("decrement failed: %w", err)
+	}
+	return nil
+}
+
+// ApplyEntries performs increments, decrements and updates and records them in
+// the history within one transaction, with the same semantics as
+// Database.ApplyEntries
+func (s *kvStore) ApplyEntries(entries []Entry) ([]DBEvent, error) {
+	now := s.cfg.now()
+	var events []DBEvent
+	err := s.backend.update(func(tx kvTx) error {
+		events = make([]DBEvent, 0, len(entries))
+		for _, entry := range entries {
+			event, err := s.applyEntry(tx, &entry, now)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+
+			links, err := kvLinksFrom(tx, entry.MetricName)
+			if err != nil {
+				return err
+			}
+			for _, fanned := range fanOut(entry, event.ID, links) {
+				event, err := s.applyEntry(tx, &fanned, now)
+				if err != nil {
+					return fmt.Errorf("link from %s: %w", entry.MetricName, err)
+				}
+				events = append(events, event)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// applyEntry performs an increment, decrement or update and records it in
+// the history as part of tx, converting its amount like Database.applyEntry
+func (s *kvStore) applyEntry(tx kvTx, entry *Entry, now time.Time) (DBEvent, error) {
+	occurredAt, err := resolveOccurredAt(entry.OccurredAt, now)
+	if err != nil {
+		return DBEvent{}, err
+	}
+	note, err := CleanNote(entry.Note)
+	if err != nil {
+		return DBEvent{}, err
+	}
+	metricName, amount := entry.MetricName, entry.Amount
+
+	metric, ok, err := kvLiveMetric(tx, metricName)
+	if err != nil {
+		return DBEvent{}, err
+	}
+	if !ok {
+		return DBEvent{}, fmt.Errorf("metric %s does not exist", metricName)
+	}
+	if err := metric.convertEntry(entry); err != nil {
+		return DBEvent{}, err
+	}
+	amount = entry.Amount
+	event := DBEvent{MetricName: metricName, Operation: entry.Operation, OccurredAt: occurredAt, Note: note, Source: entry.source}
+
+	day := s.cfg.dayOf(metric)
+	if isBackdated(metric.Reset, day, occurredAt, now) {
+		oldValue, newValue, err := kvApplyToRollup(tx, metric, day, entry.Operation, amount, occurredAt)
+		if err != nil {
+			return DBEvent{}, err
+		}
+		event.Delta, event.Value = newValue-oldValue, newValue
+		return kvInsertEvent(tx, event)
+	}
+
+	oldValue := metric.Value
+	switch entry.Operation {
+	case OpIncrement:
+		metric.Value += amount
+	case OpDecrement:
+		metric.Value -= amount
+	case OpUpdate:
+		metric.Value = amount
+	default:
+		return DBEvent{}, fmt.Errorf("unknown operation %s", entry.Operation)
+	}
+	if err := metric.checkEntry(oldValue, metric.Value); err != nil {
+		return DBEvent{}, fmt.Errorf("metric %s %w", metricName, err)
+	}
+
+	if err := putJSON(tx, metricsBucket, metricName, metric); err != nil {
+		return DBEvent{}, fmt.Errorf("failed to update metric: %w", err)
+	}
+	event.Delta, event.Value = metric.Value-oldValue, metric.Value
+	return kvInsertEvent(tx, event)
+}
+
+// kvApplyToRollup applies a backdated entry to the rollup of its period as
+// part of tx and returns the archived value before and after
+func kvApplyToRollup(tx kvTx, metric DBMetric, day DayBoundary, operation string, amount float64, occurredAt time.Time) (oldValue, newValue float64, err error) {
+	metricName := metric.MetricName
+	date := metric.Reset.rollupDay(occurredAt, day)
+	rollup := DBRollup{MetricName: metricName, Date: date}
+	if _, err := getJSON(tx, rollupsBucket, rollupKey(metricName, date), &rollup); err != nil {
+		return 0, 0, fmt.Errorf("failed to read daily rollup: %w", err)
+	}
+
+	oldValue = rollup.FinalValue
+	if err := rollup.apply(operation, amount, metric); err != nil {
+		return 0, 0, err
+	}
+
+	if err := putJSON(tx, rollupsBucket, rollupKey(metricName, date), rollup); err != nil {
+		return 0, 0, fmt.Errorf("failed to update daily rollup: %w", err)
+	}
+	return oldValue, rollup.FinalValue, nil
+}
+
+// RevertEntries takes back recorded increments, decrements and updates and
+// the events fanned out from them in one transaction, with the same semantics
+// as Database.RevertEntries
+func (s *kvStore) RevertEntries(eventIDs []int64) error {
+	now := s.cfg.now()
+	return s.backend.update(func(tx kvTx) error {
+		reverted := make(map[int64]bool)
+		for _, id := range eventIDs {
+			if reverted[id] {
+				continue
+			}
+			fanned, err := kvFannedFrom(tx, id)
+			if err != nil {
+				return err
+			}
+			for _, id := range append([]int64{id}, fanned...) {
+				if reverted[id] {
+					continue
+				}
+				if err := s.revertEvent(tx, id, now); err != nil {
+					return err
+				}
+				reverted[id] = true
+			}
+		}
+		return nil
+	})
+}
+
+// kvFannedFrom returns the events fanned out from the event source that were
+// not reverted yet as part of tx
+func kvFannedFrom(tx kvTx, source int64) ([]int64, error) {
+	var ids []int64
+	err := tx.forEach(eventsBucket, func(key string, value []byte) error {
+		var e DBEvent
+		if err := json.Unmarshal(value, &e); err != nil {
+			return fmt.Errorf("failed to decode event %q: %w", key, err)
+		}
+		if e.Source == source && tx.get(revertsBucket, key) == nil {
+			ids = append(ids, e.ID)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// revertEvent takes back the event id and records the undo event as part of
+// tx, like Database.revertEvent
+func (s *kvStore) revertEvent(tx kvTx, id int64, now time.Time) error {
+	data := tx.get(eventsBucket, eventKey(id))
+	if data == nil {
+		return fmt.Errorf("entry %d is no longer in the history", id)
+	}
+	e, err := kvDecodeEvent(tx, eventKey(id), data)
+	if err != nil {
+		return err
+	}
+	if err := revertible(e); err != nil {
+		return err
+	}
+
+	metric, ok, err := kvLiveMetric(tx, e.MetricName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("metric %s does not exist", e.MetricName)
+	}
+
+	var value float64
+	day := s.cfg.dayOf(metric)
+	if isBackdated(metric.Reset, day, e.OccurredAt, now) {
+		date := metric.Reset.rollupDay(e.OccurredAt, day)
+		rollup := DBRollup{MetricName: e.MetricName, Date: date}
+		if _, err := getJSON(tx, rollupsBucket, rollupKey(e.MetricName, date), &rollup); err != nil {
+			return fmt.Errorf("failed to read daily rollup: %w", err)
+		}
+		revertRollup(&rollup, e)
+		if err := putJSON(tx, rollupsBucket, rollupKey(e.MetricName, date), rollup); err != nil {
+			return fmt.Errorf("failed to update daily rollup: %w", err)
+		}
+		value = rollup.FinalValue
+	} else {
+		metric.Value -= e.Delta
+		if err := putJSON(tx, metricsBucket, e.MetricName, metric); err != nil {
+			return fmt.Errorf("failed to update metric: %w", err)
+		}
+		value = metric.Value
+	}
+
+	undo := DBEvent{MetricName: e.MetricName, Operation: OpUndo, Delta: -e.Delta, Value: value, OccurredAt: now, Reverts: id}
+	_, err = kvInsertEvent(tx, undo)
+	return err
+}
+
+// ResetMetric archives the current value of a metric as the closing value of
+// the period starting at periodStart and sets the value back to 0 in one
+// transaction, with the same semantics as Database.ResetMetric
+func (s *kvStore) ResetMetric(metricName string, periodStart time.Time) error {
+	return s.backend.update(func(tx kvTx) error {
+		metric, ok, err := kvLiveMetric(tx, metricName)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("metric %s does not exist", metricName)
+		}
+		if !metric.Reset.Resets() {
+			return fmt.Errorf("metric %s has no reset policy", metricName)
+		}
+
+		day := s.cfg.dayOf(metric)
+		date, windowStart, windowEnd := metric.Reset.rollupWindow(metric.Reset.PeriodStart(periodStart, day), day)
+		rollup := DBRollup{
+			MetricName: metricName,
+			Date:       date,
+			FinalValue: metric.Value,
+			MinValue:   metric.Value,
+			MaxValue:   metric.Value,
+		}
+		err = tx.forEach(eventsBucket, func(key string, value []byte) error {
+			e, err := kvDecodeEvent(tx, key, value)
+			if err != nil {
+				return err
+			}
+			if e.MetricName != metricName || e.OccurredAt.Before(windowStart) || !e.OccurredAt.Before(windowEnd) || e.Reverted {
+				return nil
+			}
+			if e.Operation == OpIncrement || e.Operation == OpDecrement || e.Operation == OpUpdate {
+				rollup.MinValue = min(rollup.MinValue, e.Value)
+				rollup.MaxValue = max(rollup.MaxValue, e.Value)
+				rollup.UpdateCount++
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to compute period stats: %w", err)
+		}
+
+		var existing DBRollup
+		ok, err = getJSON(tx, rollupsBucket, rollupKey(metricName, date), &existing)
+		if err != nil {
+			return fmt.Errorf("failed to read daily rollup: %w", err)
+		}
+		if ok {
+			rollup.merge(existing)
+		}
+		if err := putJSON(tx, rollupsBucket, rollupKey(metricName, date), rollup); err != nil {
+			return fmt.Errorf("failed to archive daily rollup: %w", err)
+		}
+
+		value := metric.Value
+		now := s.cfg.now()
+		metric.Value = 0
+		metric.LastReset = metric.Reset.PeriodStart(now, day)
+		if err := putJSON(tx, metricsBucket, metricName, metric); err != nil {
+			return fmt.Errorf("failed to reset metric: %w", err)
+		}
+		return kvRecordEvent(tx, metricName, OpReset, -value, 0, now, "")
+	})
+}
+
+// GetMetricHistory retrieves recorded mutations, newest first, with the same
+// filters as Database.GetMetricHistory
+func (s *kvStore) GetMetricHistory(metricName string, start, end time.Time, limit int) ([]DBEvent, error) {
+	events, err := s.scanEvents(nil, metricName, start, end, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric history: %w", err)
+	}
+	return events, nil
+}
+
+// SearchNotes returns the entries with a note containing text with the same
+// filters as Database.SearchNotes
+func (s *kvStore) SearchNotes(text, metricName string, start, end time.Time, limit int) ([]DBEvent, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	events, err := s.scanEvents(func(e DBEvent) bool { return matchesNote(e, text) }, metricName, start, end, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notes: %w", err)
+	}
+	return events, nil
+}
+
+// scanEvents returns the events accepted by match, if given, and the filters
+// of GetMetricHistory, newest first. Events are keyed by id rather than time,
+// so every event is visited, but only the newest limit matches are held while
+// scanning.
+func (s *kvStore) scanEvents(match func(DBEvent) bool, metricName string, start, end time.Time, limit int) ([]DBEvent, error) {
+	newer := func(a, b DBEvent) bool {
+		if !a.OccurredAt.Equal(b.OccurredAt) {
+			return a.OccurredAt.After(b.OccurredAt)
+		}
+		return a.ID > b.ID
+	}
+
+	var events []DBEvent
+	err := s.backend.view(func(tx kvTx) error {
+		return tx.forEach(eventsBucket, func(key string, value []byte) error {
+			e, err := kvDecodeEvent(tx, key, value)
+			if err != nil {
+				return err
+			}
+			if metricName != "" && e.MetricName != metricName {
+				return nil
+			}
+			if !start.IsZero() && e.OccurredAt.Unix() < start.Unix() {
+				return nil
+			}
+			if !end.IsZero() && e.OccurredAt.Unix() >= end.Unix() {
+				return nil
+			}
+			if match != nil && !match(e) {
+				return nil
+			}
+			if limit > 0 && len(events) == limit && !newer(e, events[limit-1]) {
+				return nil
+			}
+			i := sort.Search(len(events), func(i int) bool { return newer(e, events[i]) })
+			events = slices.Insert(events, i, e)
+			if limit > 0 && len(events) > limit {
+				events = events[:limit]
+			}
+			return nil
+		})
+	})
+	return events, err
+}
+
+// GetDailyRollups retrieves archived daily values, newest day first, with the
+// same filters as Database.GetDailyRollups
+func (s *kvStore) GetDailyRollups(metricName, startDate, endDate string) ([]DBRollup, error) {
+	var rollups []DBRollup
+	err := s.backend.view(func(tx kvTx) error {
+		return tx.forEach(rollupsBucket, func(key string, value []byte) error {
+			var r DBRollup
+			if err := json.Unmarshal(value, &r); err != nil {
+				return fmt.Errorf("failed to decode rollup %q: %w", key, err)
+			}
+			if metricName != "" && r.MetricName != metricName {
+				return nil
+			}
+			if startDate != "" && r.Date < startDate {
+				return nil
+			}
+			if endDate != "" && r.Date > endDate {
+				return nil
+			}
+			rollups = append(rollups, r)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily rollups: %w", err)
+	}
+
+	sort.SliceStable(rollups, func(i, j int) bool {
+		if rollups[i].Date != rollups[j].Date {
+			return rollups[i].Date > rollups[j].Date
+		}
+		return rollups[i].MetricName < rollups[j].MetricName
+	})
+	return rollups, nil
+}
+
+// EditMetric changes the type, unit and reset policy of a metric and
+// optionally renames it, with the same semantics as Database.EditMetric
+func (s *kvStore) EditMetric(metricName string, edit MetricEdit) error {
+	return s.backend.update(func(tx kvTx) error {
+		metric, ok, err := kvLiveMetric(tx, metricName)
+		if err != nil {
+			return fmt.Errorf("failed to edit metric: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("metric '%s' does not exist", metricName)
+		}
+
+		before := metric
+		if edit.renames(metricName) {
+			if err := kvRenameMetric(tx, &metric, edit.NewName, edit.KeepAlias); err != nil {
+				return err
+			}
+		}
+		now := s.cfg.now()
+		edit.applyTo(&metric, time.Unix(now.Unix(), 0))
+		if err := checkKindChange(before, metric); err != nil {
+			return fmt.Errorf("failed to edit metric: %w", err)
+		}
+		if err := prepareMetric(&metric); err != nil {
+			return fmt.Errorf("failed to edit metric: %w", err)
+		}
+
+		// Formulas that refer to the metric follow it to its new name
+		formulas, err := kvLiveFormulas(tx)
+		if err != nil {
+			return fmt.Errorf("failed to edit metric: %w", err)
+		}
+		delete(formulas, metric.MetricName)
+		formulas[metricName] = before.Formula
+		rewritten, err := checkFormulaChange(formulas, metricName, metric)
+		if err != nil {
+			return fmt.Errorf("failed to edit metric: %w", err)
+		}
+		for name, formula := range rewritten {
+			user, _, err := kvLiveMetric(tx, name)
+			if err != nil {
+				return fmt.Errorf("failed to edit metric: %w", err)
+			}
+			user.Formula = formula
+			if err := putJSON(tx, metricsBucket, name, user); err != nil {
+				return fmt.Errorf("failed to edit metric: %w", err)
+			}
+		}
+
+		if err := putJSON(tx, metricsBucket, metric.MetricName, metric); err != nil {
+			return fmt.Errorf("failed to edit metric: %w", err)
+		}
+		return kvRecordEvent(tx, metric.MetricName, OpEdit, 0, metric.Value, now, "")
+	})
+}
+
+// kvRenameMetric moves the history and rollups of metric to newName and
+// updates its aliases as part of tx. The caller stores the renamed metric.
+func kvRenameMetric(tx kvTx, metric *DBMetric, newName string, keepAlias bool) error {
+	oldName := metric.MetricName
+	if tx.get(metricsBucket, newName) != nil {
+		return fmt.Errorf("failed to rename metric: metric %s already exists", newName)
+	}
+	owner, err := kvAliasOwner(tx, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename metric: %w", err)
+	}
+	if owner != "" && owner != oldName {
+		return fmt.Errorf("failed to rename metric: %s is an alias of metric %s", newName, owner)
+	}
+
+	if err := kvDropHistory(tx, newName); err != nil {
+		return fmt.Errorf("failed to rename metric: %w", err)
+	}
+
+	// Collect first, the buckets must not be modified while iterating them
+	var rollups []DBRollup
+	err = tx.forEach(rollupsBucket, func(key string, value []byte) error {
+		var r DBRollup
+		if err := json.Unmarshal(value, &r); err != nil {
+			return fmt.Errorf("failed to decode rollup %q: %w", key, err)
+		}
+		if r.MetricName == oldName {
+			rollups = append(rollups, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var events []DBEvent
+	err = tx.forEach(eventsBucket, func(key string, value []byte) error {
+		var e DBEvent
+		if err := json.Unmarshal(value, &e); err != nil {
+			return fmt.Errorf("failed to decode event %q: %w", key, err)
+		}
+		if e.MetricName == oldName {
+			events = append(events, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rollups {
+		if err := tx.delete(rollupsBucket, rollupKey(oldName, r.Date)); err != nil {
+			return fmt.Errorf("failed to rename metric: %w", err)
+		}
+		r.MetricName = newName
+		if err := putJSON(tx, rollupsBucket, rollupKey(newName, r.Date), r); err != nil {
+			return fmt.Errorf("failed to rename metric: %w", err)
+		}
+	}
+	for _, e := range events {
+		e.MetricName = newName
+		if err := putJSON(tx, eventsBucket, eventKey(e.ID), e); err != nil {
+			return fmt.Errorf("failed to rename metric: %w", err)
+		}
+	}
+	if err := tx.delete(metricsBucket, oldName); err != nil {
+		return fmt.Errorf("failed to rename metric: %w", err)
+	}
+	if err := kvMoveLinks(tx, oldName, newName); err != nil {
+		return fmt.Errorf("failed to rename metric: %w", err)
+	}
+
+	var aliases []string
+	for _, alias := range metric.Aliases {
+		if alias != newName {
+			aliases = append(aliases, alias)
+		}
+	}
+	if keepAlias {
+		aliases = append(aliases, oldName)
+		sort.Strings(aliases)
+	}
+	metric.Aliases = aliases
+	return nil
+}
+
+// SetLink adds a link or replaces the multiplier of an existing one, like
+// Database.SetLink
+func (s *kvStore) SetLink(link Link) error {
+	return s.backend.update(func(tx kvTx) error {
+		var metrics [2]DBMetric
+		for i, name := range []string{link.Source, link.Target} {
+			metric, ok, err := kvLiveMetric(tx, name)
+			if err != nil {
+				return fmt.Errorf("failed to read metric: %w", err)
+			}
+			if !ok {
+				return fmt.Errorf("metric %s does not exist", name)
+			}
+			metrics[i] = metric
+		}
+		if err := checkLink(link, metrics[0], metrics[1]); err != nil {
+			return err
+		}
+		if err := putJSON(tx, linksBucket, linkKey(link.Source, link.Target), link); err != nil {
+			return fmt.Errorf("failed to save link: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteLink removes the link from source to target
+func (s *kvStore) DeleteLink(source, target string) error {
+	return s.backend.update(func(tx kvTx) error {
+		if tx.get(linksBucket, linkKey(source, target)) == nil {
+			return fmt.Errorf("%s is not linked to %s", source, target)
+		}
+		if err := tx.delete(linksBucket, linkKey(source, target)); err != nil {
+			return fmt.Errorf("failed to delete link: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetLinks returns the links from or to metricName, or every link when it is
+// empty, ordered by source and target
+func (s *kvStore) GetLinks(metricName string) ([]Link, error) {
+	var links []Link
+	err := s.backend.view(func(tx kvTx) error {
+		all, err := kvLinks(tx)
+		if err != nil {
+			return err
+		}
+		for _, link := range all {
+			if metricName == "" || link.Source == metricName || link.Target == metricName {
+				links = append(links, link)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// kvLinks returns every link as part of tx, ordered by source and target
+func kvLinks(tx kvTx) ([]Link, error) {
+	var links []Link
+	err := tx.forEach(linksBucket, func(key string, value []byte) error {
+		var link Link
+		if err := json.Unmarshal(value, &link); err != nil {
+			return fmt.Errorf("failed to decode link %q: %w", key, err)
+		}
+		links = append(links, link)
+		return nil
+	})
+	return links, err
+}
+
+// kvLinksFrom returns the links of source whose target is out of the trash as
+// part of tx, like linksFrom
+func kvLinksFrom(tx kvTx, source string) ([]Link, error) {
+	all, err := kvLinks(tx)
+	if err != nil {
+		return nil, err
+	}
+	var links []Link
+	for _, link := range all {
+		if link.Source != source {
+			continue
+		}
+		if _, ok, err := kvLiveMetric(tx, link.Target); err != nil {
+			return nil, err
+		} else if ok {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// kvMoveLinks moves the links from and to oldName over to newName as part of
+// tx, or deletes them when newName is empty
+func kvMoveLinks(tx kvTx, oldName, newName string) error {
+	links, err := kvLinks(tx)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if link.Source != oldName && link.Target != oldName {
+			continue
+		}
+		if err := tx.delete(linksBucket, linkKey(link.Source, link.Target)); err != nil {
+			return err
+		}
+		if newName == "" {
+			continue
+		}
+		if link.Source == oldName {
+			link.Source = newName
+		}
+		if link.Target == oldName {
+			link.Target = newName
+		}
+		if err := putJSON(tx, linksBucket, linkKey(link.Source, link.Target), link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kvAliasOwner returns the metric that exports name as an alias, or "" if there is none
+func kvAliasOwner(tx kvTx, name string) (string, error) {
+	var owner string
+	err := tx.forEach(metricsBucket, func(key string, value []byte) error {
+		var m DBMetric
+		if err := json.Unmarshal(value, &m); err != nil {
+			return fmt.Errorf("failed to decode metric %q: %w", key, err)
+		}
+		for _, alias := range m.Aliases {
+			if alias == name {
+				owner = m.MetricName
+			}
+		}
+		return nil
+	})
+	return owner, err
+}
+
+// ListDeletedMetrics retrieves the metrics in the trash, most recently deleted first
+func (s *kvStore) ListDeletedMetrics() ([]DBMetric, error) {
+	metrics, err := s.filterMetrics(func(m DBMetric) bool { return !m.DeletedAt.IsZero() })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].DeletedAt.After(metrics[j].DeletedAt) })
+	return metrics, nil
+}
+
+// kvTrashedMetric reads a metric that is in the trash
+func kvTrashedMetric(tx kvTx, metricName string) (DBMetric, error) {
+	var metric DBMetric
+	ok, err := getJSON(tx, metricsBucket, metricName, &metric)
+	if err != nil {
+		return metric, err
+	}
+	if !ok || metric.DeletedAt.IsZero() {
+		return metric, fmt.Errorf("metric '%s' is not in the trash", metricName)
+	}
+	return metric, nil
+}
+
+// RestoreMetric moves a metric out of the trash with its value intact
+func (s *kvStore) RestoreMetric(metricName string) error {
+	return s.backend.update(func(tx kvTx) error {
+		metric, err := kvTrashedMetric(tx, metricName)
+		if err != nil {
+			return err
+		}
+		// The metrics a derived metric is computed from may be gone by now
+		if metric.Kind == KindDerived {
+			formulas, err := kvLiveFormulas(tx)
+			if err != nil {
+				return fmt.Errorf("failed to restore metric: %w", err)
+			}
+			if _, err := checkFormulaChange(formulas, "", metric); err != nil {
+				return fmt.Errorf("failed to restore metric: %w", err)
+			}
+		}
+		metric.DeletedAt = time.Time{}
+		if err := putJSON(tx, metricsBucket, metricName, metric); err != nil {
+			return fmt.Errorf("failed to restore metric: %w", err)
+		}
+		return kvRecordEvent(tx, metricName, OpRestore, 0, metric.Value, s.cfg.now(), "")
+	})
+}
+
+// kvPurgeMetric deletes a trashed metric and its history as part of tx
+func kvPurgeMetric(tx kvTx, metricName string) error {
+	if _, err := kvTrashedMetric(tx, metricName); err != nil {
+		return err
+	}
+	if err := tx.delete(metricsBucket, metricName); err != nil {
+		return fmt.Errorf("failed to purge metric: %w", err)
+	}
+	if err := kvMoveLinks(tx, metricName, ""); err != nil {
+		return fmt.Errorf("failed to purge metric: %w", err)
+	}
+	return kvDropHistory(tx, metricName)
+}
+
+// kvDropHistory deletes the recorded events and daily rollups of metricName
+// as part of tx, with the same rules as dropHistory
+func kvDropHistory(tx kvTx, metricName string) error {
+	var events, reverted, rollups []string
+	err := tx.forEach(eventsBucket, func(key string, value []byte) error {
+		var e DBEvent
+		if err := json.Unmarshal(value, &e); err != nil {
+			return fmt.Errorf("failed to decode event %q: %w", key, err)
+		}
+		if e.MetricName == metricName {
+			events = append(events, key)
+			if e.Reverts != 0 {
+				reverted = append(reverted, eventKey(e.Reverts))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.forEach(rollupsBucket, func(key string, value []byte) error {
+		var r DBRollup
+		if err := json.Unmarshal(value, &r); err != nil {
+			return fmt.Errorf("failed to decode rollup %q: %w", key, err)
+		}
+		if r.MetricName == metricName {
+			rollups = append(rollups, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range events {
+		if err := tx.delete(eventsBucket, key); err != nil {
+			return fmt.Errorf("failed to drop history of %s: %w", metricName, err)
+		}
+	}
+	for _, key := range reverted {
+		if err := tx.delete(revertsBucket, key); err != nil {
+			return fmt.Errorf("failed to drop history of %s: %w", metricName, err)
+		}
+	}
+	for _, key := range rollups {
+		if err := tx.delete(rollupsBucket, key); err != nil {
+			return fmt.Errorf("failed to drop history of %s: %w", metricName, err)
+		}
+	}
+	return nil
+}
+
+// PurgeMetric permanently removes a metric from the trash together with its history
+func (s *kvStore) PurgeMetric(metricName string) error {
+	return s.backend.update(func(tx kvTx) error {
+		return kvPurgeMetric(tx, metricName)
+	})
+}
+
+// PurgeDeletedMetrics permanently removes every metric moved to the trash before cutoff
+func (s *kvStore) PurgeDeletedMetrics(cutoff time.Time) ([]string, error) {
+	var purged []string
+	err := s.backend.update(func(tx kvTx) error {
+		// Collect first, the bucket must not be modified while iterating it
+		err := tx.forEach(metricsBucket, func(key string, value []byte) error {
+			var m DBMetric
+			if err := json.Unmarshal(value, &m); err != nil {
+				return fmt.Errorf("failed to decode metric %q: %w", key, err)
+			}
+			if !m.DeletedAt.IsZero() && m.DeletedAt.Before(cutoff) {
+				purged = append(purged, m.MetricName)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, name := range purged {
+			if err := kvPurgeMetric(tx, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
+
+// ImportMetric stores a metric with its history in a single transaction,
+// with the same semantics as Database.ImportMetric
+func (s *kvStore) ImportMetric(data MetricData, policy ConflictPolicy, dryRun bool) (ImportResult, error) {
+	if err := data.prepare(); err != nil {
+		return ImportResult{}, fmt.Errorf("failed to import metric %s: %w", data.Metric.MetricName, err)
+	}
+	metric := data.Metric
+
+	var result ImportResult
+	err := s.backend.update(func(tx kvTx) error {
+		var existing DBMetric
+		exists, err := getJSON(tx, metricsBucket, metric.MetricName, &existing)
+		if err != nil {
+			return fmt.Errorf("failed to import metric: %w", err)
+		}
+		owner, err := kvAliasOwner(tx, metric.MetricName)
+		if err != nil {
+			return fmt.Errorf("failed to import metric: %w", err)
+		}
+		if owner != "" {
+			return fmt.Errorf("failed to import metric: %s is an alias of metric %s", metric.MetricName, owner)
+		}
+
+		result = ImportResult{MetricName: metric.MetricName, Outcome: policy.outcome(exists)}
+		known := make(map[string]int64)
+		switch result.Outcome {
+		case ImportSkipped:
+			return nil
+		case ImportMerged:
+			err := tx.forEach(eventsBucket, func(key string, value []byte) error {
+				var e DBEvent
+				if err := json.Unmarshal(value, &e); err != nil {
+					return fmt.Errorf("failed to decode event %q: %w", key, err)
+				}
+				if e.MetricName == metric.MetricName {
+					known[eventIdentity(e)] = e.ID
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		default:
+			if err := kvDropHistory(tx, metric.MetricName); err != nil {
+				return fmt.Errorf("failed to import metric: %w", err)
+			}
+			for _, alias := range metric.Aliases {
+				if err := kvCheckAlias(tx, alias, metric.MetricName); err != nil {
+					return fmt.Errorf("failed to import metric: %w", err)
+				}
+			}
+			if metric.LastReset.IsZero() {
+				metric.LastReset = s.cfg.now()
+			}
+			metric.LastReset = time.Unix(metric.LastReset.Unix(), 0)
+			if !metric.DeletedAt.IsZero() {
+				metric.DeletedAt = time.Unix(metric.DeletedAt.Unix(), 0)
+			}
+			if err := putJSON(tx, metricsBucket, metric.MetricName, metric); err != nil {
+				return fmt.Errorf("failed to import metric: %w", err)
+			}
+		}
+
+		ids := make(eventIDs)
+		for _, e := range data.Events {
+			if id, ok := known[eventIdentity(e)]; ok {
+				ids.add(e.ID, id)
+				continue
+			}
+			e.Reverts = ids[e.Reverts]
+			// The source of a fanned out event is an event of another metric
+			e.Source = 0
+			stored, err := kvInsertEvent(tx, e)
+			if err != nil {
+				return err
+			}
+			known[eventIdentity(e)] = stored.ID
+			ids.add(e.ID, stored.ID)
+			result.Events++
+		}
+		for _, r := range data.Rollups {
+			key := rollupKey(r.MetricName, r.Date)
+			if tx.get(rollupsBucket, key) != nil {
+				continue
+			}
+			if err := putJSON(tx, rollupsBucket, key, r); err != nil {
+				return fmt.Errorf("failed to import daily rollup: %w", err)
+			}
+			result.Rollups++
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return ImportResult{}, err
+	}
+	return result, nil
+}
+
+// GetJournal returns the ops of the undo journal, oldest first
+func (s *kvStore) GetJournal() ([]JournalOp, error) {
+	var ops []JournalOp
+	err := s.backend.view(func(tx kvTx) error {
+		return tx.forEach(journalBucket, func(key string, value []byte) error {
+			var op JournalOp
+			if err := json.Unmarshal(value, &op); err != nil {
+				return fmt.Errorf("failed to decode journal op %q: %w", key, err)
+			}
+			ops = append(ops, op)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query undo journal: %w", err)
+	}
+	return ops, nil
+}
+
+// SaveJournalOp adds an op to the undo journal, or updates it when it has an
+// ID, and removes the ops drop in the same transaction, like
+// Database.SaveJournalOp
+func (s *kvStore) SaveJournalOp(op JournalOp, drop []int64) (int64, error) {
+	err := s.backend.update(func(tx kvTx) error {
+		if op.ID == 0 {
+			id, err := tx.nextID(journalBucket)
+			if err != nil {
+				return err
+			}
+			op.ID = id
+		}
+		if err := putJSON(tx, journalBucket, eventKey(op.ID), op); err != nil {
+			return err
+		}
+		for _, id := range drop {
+			if err := tx.delete(journalBucket, eventKey(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to save undo journal: %w", err)
+	}
+	return op.ID, nil
+}
+
+// DeleteJournalOps removes ops from the undo journal
+func (s *kvStore) DeleteJournalOps(ids []int64) error {
+	err := s.backend.update(func(tx kvTx) error {
+		for _, id := range ids {
+			if err := tx.delete(journalBucket, eventKey(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete from undo journal: %w", err)
+	}
+	return nil
+}
+
+// kvCheckAlias returns an error unless metricName may export alias
+func kvCheckAlias(tx kvTx, alias, metricName string) error {
+	if tx.get(metricsBucket, alias) != nil {
+		return fmt.Errorf("alias %s is the name of another metric", alias)
+	}
+	owner, err := kvAliasOwner(tx, alias)
+	if err != nil {
+		return err
+	}
+	if owner != "" && owner != metricName {
+		return fmt.Errorf("%s is already an alias of metric %s", alias, owner)
+	}
+	return nil
+}
+
+// Close releases the underlying backend
+func (s *kvStore) Close() error {
+	return s.backend.close()
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+)
+
+// Link fans the increments and decrements of its source metric out to its
+// target metric, so logging a latte can also add 75 to caffeine_mg. The
+// target changes by the amount of the entry times the multiplier, in the
+// same transaction as the source. Links are not followed any further: the
+// links of a target do not apply to the changes fanned out to it.
+type Link struct {
+	Source     string
+	Target     string
+	Multiplier float64 // Change of the target per unit of the source, negative to change it the other way
+}
+
+// checkLink validates a link between two live metrics
+func checkLink(link Link, source, target DBMetric) error {
+	switch {
+	case link.Source == link.Target:
+		return fmt.Errorf("metric %s cannot be linked to itself", link.Source)
+	case link.Multiplier == 0 || math.IsNaN(link.Multiplier) || math.IsInf(link.Multiplier, 0):
+		return fmt.Errorf("the multiplier of a link must be a number other than 0")
+	case source.Kind == KindDerived:
+		return fmt.Errorf("metric %s is derived from other metrics and cannot be the source of a link", link.Source)
+	case target.Kind == KindDerived:
+		return fmt.Errorf("metric %s is derived from other metrics and cannot be the target of a link", link.Target)
+	}
+	return nil
+}
+
+// fanOut returns the entries an entry of a source metric, recorded as the
+// event source, fans out to through links. They keep the note of the entry.
+// Only increments and decrements are fanned out; a negative multiplier turns
+// one into the other.
+func fanOut(entry Entry, source int64, links []Link) []Entry {
+	if entry.Operation != OpIncrement && entry.Operation != OpDecrement {
+		return nil
+	}
+	var entries []Entry
+	for _, link := range links {
+		fanned := Entry{
+			MetricName: link.Target,
+			Operation:  entry.Operation,
+			Amount:     entry.Amount * link.Multiplier,
+			OccurredAt: entry.OccurredAt,
+			Note:       entry.Note,
+			source:     source,
+		}
+		if fanned.Amount < 0 {
+			fanned.Amount = -fanned.Amount
+			if fanned.Operation == OpIncrement {
+				fanned.Operation = OpDecrement
+			} else {
+				fanned.Operation = OpIncrement
+			}
+		}
+		entries = append(entries, fanned)
+	}
+	return entries
+}
+
+// SetLink adds a link or replaces the multiplier of an existing one. Both
+// metrics must exist and be out of the trash.
+func (db *Database) SetLink(link Link) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var metrics [2]DBMetric
+	for i, name := range []string{link.Source, link.Target} {
+		query := `SELECT ` + metricColumns + ` FROM metrics WHERE metric_name = ? AND deleted_at IS NULL;`
+		metrics[i], err = scanMetric(tx.QueryRow(query, name))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("metric %s does not exist", name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read metric: %w", err)
+		}
+	}
+	if err := checkLink(link, metrics[0], metrics[1]); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO metric_links (source, target, multiplier) VALUES (?, ?, ?)
+		ON CONFLICT (source, target) DO UPDATE SET multiplier = excluded.multiplier;`, link.Source, link.Target, link.Multiplier)
+	if err != nil {
+		return fmt.Errorf("failed to save link: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteLink removes the link from source to target
+func (db *Database) DeleteLink(source, target string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	res, err := db.conn.Exec(`DELETE FROM metric_links WHERE source = ? AND target = ?;`, source, target)
+	if err != nil {
+		return fmt.Errorf("failed to delete link: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete link: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("%s is not linked to %s", source, target)
+	}
+	return nil
+}
+
+// GetLinks returns the links from or to metricName, or every link when it is
+// empty, ordered by source and target
+func (db *Database) GetLinks(metricName string) ([]Link, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := `SELECT source, target, multiplier FROM metric_links`
+	var args []any
+	if metricName != "" {
+		query += ` WHERE source = ? OR target = ?`
+		args = append(args, metricName, metricName)
+	}
+	query += ` ORDER BY source, target;`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []Link
+	for rows.Next() {
+		var link Link
+		if err := rows.Scan(&link.Source, &link.Target, &link.Multiplier); err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// linksFrom returns the links of source whose target is out of the trash as
+// part of tx. Entries are not fanned out to trashed metrics, and reach them
+// again once they are restored.
+func linksFrom(tx *sql.Tx, source string) ([]Link, error) {
+	rows, err := tx.Query(`SELECT l.source, l.target, l.multiplier FROM metric_links l
+		JOIN metrics m ON m.metric_name = l.target
+		WHERE l.source = ? AND m.deleted_at IS NULL
+		ORDER BY l.target;`, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []Link
+	for rows.Next() {
+		var link Link
+		if err := rows.Scan(&link.Source, &link.Target, &link.Multiplier); err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
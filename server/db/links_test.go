@@ -0,0 +1,118 @@
+package db
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinks(t *testing.T) {
+	sqlite, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer sqlite.Close()
+
+	for _, store := range []Store{sqlite, NewMemoryStore()} {
+		value := func(name string) float64 {
+			t.Helper()
+			m, err := store.GetMetric(name)
+			if err != nil {
+				t.Fatalf("%T: GetMetric(%s) failed: %v", store, name, err)
+			}
+			return m.Value
+		}
+		must := func(step string, err error) {
+			t.Helper()
+			if err != nil {
+				t.Fatalf("%T: %s failed: %v", store, step, err)
+			}
+		}
+		rejects := func(step, want string, err error) {
+			t.Helper()
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%T: %s = %v, want an error containing %q", store, step, err, want)
+			}
+		}
+
+		for _, name := range []string{"latte", "caffeine_mg", "dairy_servings", "oat_milk"} {
+			must("add "+name, store.AddMetric(DBMetric{MetricName: name, Type: "Food", Unit: "units", Kind: KindGauge,
+				Constraints: Constraints{AllowNegative: true}}))
+		}
+		must("add total", store.AddMetric(DBMetric{MetricName: "total_mg", Type: "Food", Kind: KindDerived, Formula: "caffeine_mg"}))
+		must("link caffeine", store.SetLink(Link{Source: "latte", Target: "caffeine_mg", Multiplier: 60}))
+		must("replace caffeine", store.SetLink(Link{Source: "latte", Target: "caffeine_mg", Multiplier: 75}))
+		must("link dairy", store.SetLink(Link{Source: "latte", Target: "dairy_servings", Multiplier: 1}))
+		must("link oat milk", store.SetLink(Link{Source: "latte", Target: "oat_milk", Multiplier: -0.5}))
+		must("link back", store.SetLink(Link{Source: "caffeine_mg", Target: "latte", Multiplier: 2}))
+
+		rejects("link to itself", "itself", store.SetLink(Link{Source: "latte", Target: "latte", Multiplier: 1}))
+		rejects("zero multiplier", "other than 0", store.SetLink(Link{Source: "latte", Target: "oat_milk", Multiplier: 0}))
+		rejects("unknown target", "tea does not exist", store.SetLink(Link{Source: "latte", Target: "tea", Multiplier: 1}))
+		rejects("derived target", "cannot be the target", store.SetLink(Link{Source: "latte", Target: "total_mg", Multiplier: 1}))
+		rejects("missing link", "not linked", store.DeleteLink("dairy_servings", "latte"))
+
+		// Links are applied once, the link back from caffeine_mg is not followed
+		events, err := store.ApplyEntries([]Entry{{MetricName: "latte", Operation: OpIncrement, Amount: 2, Note: "with a friend"}})
+		must("increment latte", err)
+		var changed []string
+		for _, e := range events {
+			changed = append(changed, e.MetricName+" "+e.Operation)
+			if e.Note != "with a friend" || e.MetricName != "latte" && e.Source != events[0].ID {
+				t.Errorf("%T: event %+v of incrementing latte lost its note or source", store, e)
+			}
+		}
+		want := []string{"latte increment", "caffeine_mg increment", "dairy_servings increment", "oat_milk decrement"}
+		if !slices.Equal(changed, want) {
+			t.Errorf("%T: incrementing latte changed %q, want %q", store, changed, want)
+		}
+		for name, want := range map[string]float64{"latte": 2, "caffeine_mg": 150, "dairy_servings": 2, "oat_milk": -1, "total_mg": 150} {
+			if got := value(name); got != want {
+				t.Errorf("%T: %s = %g after incrementing latte, want %g", store, name, got, want)
+			}
+		}
+
+		history, err := store.GetMetricHistory("caffeine_mg", time.Time{}, time.Time{}, 1)
+		must("caffeine history", err)
+		if len(history) != 1 || history[0].SourceName != "latte" {
+			t.Errorf("%T: history of caffeine_mg = %+v, want an event fanned out from latte", store, history)
+		}
+
+		// Updates are not fanned out and reverting the entry takes the links back too
+		must("update latte", store.UpdateMetric("latte", 10, time.Time{}, ""))
+		must("revert", store.RevertEntries([]int64{events[0].ID}))
+		for name, want := range map[string]float64{"latte": 8, "caffeine_mg": 0, "dairy_servings": 0, "oat_milk": 0} {
+			if got := value(name); got != want {
+				t.Errorf("%T: %s = %g after the revert, want %g", store, name, got, want)
+			}
+		}
+
+		// A target that rejects its change rolls back the whole entry
+		must("make dairy a counter", store.EditMetric("dairy_servings", MetricEdit{Kind: KindCounter}))
+		rejects("decrement latte", "link from latte: metric dairy_servings", store.DecrementMetric("latte", 1, time.Time{}, ""))
+		if got := value("latte"); got != 8 {
+			t.Errorf("%T: latte = %g after a rejected decrement, want 8", store, got)
+		}
+
+		// Trashed targets are skipped, renames and purges carry over to links
+		must("delete oat milk", store.DeleteMetric("oat_milk"))
+		must("increment latte", store.IncrementMetric("latte", 1, time.Time{}, ""))
+		must("rename latte", store.EditMetric("latte", MetricEdit{NewName: "flat_white"}))
+		must("purge oat milk", store.PurgeMetric("oat_milk"))
+		links, err := store.GetLinks("flat_white")
+		must("get links", err)
+		want = nil
+		for _, l := range links {
+			want = append(want, l.Source+" -> "+l.Target)
+		}
+		if w := []string{"caffeine_mg -> flat_white", "flat_white -> caffeine_mg", "flat_white -> dairy_servings"}; !slices.Equal(want, w) {
+			t.Errorf("%T: links of flat_white = %q, want %q", store, want, w)
+		}
+		must("unlink", store.DeleteLink("flat_white", "dairy_servings"))
+		if links, err := store.GetLinks(""); err != nil || len(links) != 2 {
+			t.Errorf("%T: GetLinks = %v, %v, want 2 links", store, links, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// memory.go
+package db
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps every metric in process memory. Nothing survives a
+// restart, which makes it suited for tests and throwaway setups.
+type MemoryStore struct {
+	kvStore
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore(opts ...Option) *MemoryStore {
+	backend := &memBackend{
+		buckets: make(map[string]map[string][]byte),
+		seqs:    make(map[string]int64),
+	}
+	for _, bucket := range kvBuckets {
+		backend.buckets[bucket] = make(map[string][]byte)
+	}
+	return &MemoryStore{kvStore{backend: backend, cfg: newConfig(opts)}}
+}
+
+// memBackend is a kvBackend made of maps guarded by a single lock
+type memBackend struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string][]byte
+	seqs    map[string]int64
+}
+
+func (b *memBackend) view(fn func(tx kvTx) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return fn(&memTx{backend: b})
+}
+
+// update buffers the writes of fn and only applies them once fn succeeds
+func (b *memBackend) update(fn func(tx kvTx) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tx := &memTx{
+		backend:  b,
+		writable: true,
+		pending:  make(map[string]map[string][]byte),
+		seqs:     make(map[string]int64),
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	for bucket, entries := range tx.pending {
+		for key, value := range entries {
+			if value == nil {
+				delete(b.buckets[bucket], key)
+			} else {
+				b.buckets[bucket][key] = value
+			}
+		}
+	}
+	for bucket, seq := range tx.seqs {
+		b.seqs[bucket] = seq
+	}
+	return nil
+}
+
+func (b *memBackend) close() error {
+	return nil
+}
+
+// memTx reads through its pending writes to the committed buckets
+type memTx struct {
+	backend  *memBackend
+	writable bool
+	pending  map[string]map[string][]byte // A nil value marks a deleted key
+	seqs     map[string]int64
+}
+
+func (tx *memTx) get(bucket, key string) []byte {
+	if value, ok := tx.pending[bucket][key]; ok {
+		return value
+	}
+	return tx.backend.buckets[bucket][key]
+}
+
+func (tx *memTx) put(bucket, key string, value []byte) error {
+	return tx.set(bucket, key, append([]byte{}, value...))
+}
+
+func (tx *memTx) delete(bucket, key string) error {
+	return tx.set(bucket, key, nil)
+}
+
+func (tx *memTx) set(bucket, key string, value []byte) error {
+	if !tx.writable {
+		return fmt.Errorf("write to %s in a read-only transaction", bucket)
+	}
+	if _, ok := tx.backend.buckets[bucket]; !ok {
+		return fmt.Errorf("unknown bucket %s", bucket)
+	}
+	if tx.pending[bucket] == nil {
+		tx.pending[bucket] = make(map[string][]byte)
+	}
+	tx.pending[bucket][key] = value
+	return nil
+}
+
+func (tx *memTx) forEach(bucket string, fn func(key string, value []byte) error) error {
+	keys := make([]string, 0, len(tx.backend.buckets[bucket]))
+	for key := range tx.backend.buckets[bucket] {
+		if _, ok := tx.pending[bucket][key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	for key := range tx.pending[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := tx.get(bucket, key)
+		if value == nil {
+			continue
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *memTx) nextID(bucket string) (int64, error) {
+	if !tx.writable {
+		return 0, fmt.Errorf("sequence of %s used in a read-only transaction", bucket)
+	}
+	seq, ok := tx.seqs[bucket]
+	if !ok {
+		seq = tx.backend.seqs[bucket]
+	}
+	seq++
+	tx.seqs[bucket] = seq
+	return seq, nil
+}
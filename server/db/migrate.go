@@ -0,0 +1,221 @@
+// migrate.go
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration files are named NNNN_description.sql and applied in version order.
+// Released files must never change; schema changes always go into a new file.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus reports whether a migration has been applied to the database
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrations returns every embedded migration ordered by version
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		versionStr, name, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s is not named NNNN_description.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %w", entry.Name(), err)
+		}
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be contiguous from 1, found %d at position %d", m.Version, i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// LatestVersion returns the schema version this binary migrates databases to
+func LatestVersion() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return len(migrations), nil
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// schemaVersion returns the highest applied migration version, treating a
+// database without a schema_version table as version 0
+func schemaVersion(q querier) (int, error) {
+	var tables int
+	tableQuery := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version';`
+	if err := q.QueryRow(tableQuery).Scan(&tables); err != nil {
+		return 0, fmt.Errorf("failed to look up schema_version table: %w", err)
+	}
+	if tables == 0 {
+		return 0, nil
+	}
+
+	var version int
+	if err := q.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version;`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// SchemaVersion returns the highest migration version applied to the database
+func (db *Database) SchemaVersion() (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return schemaVersion(db.conn)
+}
+
+// MigrationStatus lists every known migration and whether it has been applied
+func (db *Database) MigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	applied := make(map[int]time.Time)
+	version, err := schemaVersion(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	if version > 0 {
+		rows, err := db.conn.Query(`SELECT version, applied_at FROM schema_version;`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query schema_version: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var version int
+			var appliedAt int64
+			if err := rows.Scan(&version, &appliedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan schema_version: %w", err)
+			}
+			applied[version] = time.Unix(appliedAt, 0)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("row iteration error: %w", err)
+		}
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		statuses[i] = MigrationStatus{Migration: m, Applied: ok, AppliedAt: appliedAt}
+	}
+	return statuses, nil
+}
+
+// MigrateTo applies every pending migration up to and including target in a
+// single transaction, so a failing step leaves the database untouched.
+// Downgrades are not supported and a database newer than this binary is refused.
+func (db *Database) MigrateTo(target int) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	return db.migrate(migrations, target)
+}
+
+// migrate applies the pending steps of migrations up to target, see MigrateTo
+func (db *Database) migrate(migrations []Migration, target int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := schemaVersion(tx)
+	if err != nil {
+		return err
+	}
+
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than the %d supported by this binary, refusing to continue", current, len(migrations))
+	}
+	if target < 0 || target > len(migrations) {
+		return fmt.Errorf("unknown schema version %d, latest is %d", target, len(migrations))
+	}
+	if target < current {
+		return fmt.Errorf("database is at schema version %d, downgrading to %d is not supported", current, target)
+	}
+	if target == current {
+		return nil
+	}
+
+	createTableQuery := `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at INTEGER NOT NULL -- Unix seconds
+	);`
+
+	if _, err := tx.Exec(createTableQuery); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	for _, m := range migrations[current:target] {
+		if _, err := tx.Exec(m.SQL); err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		insertQuery := `INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?);`
+		if _, err := tx.Exec(insertQuery, m.Version, m.Name, time.Now().Unix()); err != nil {
+			return fmt.Errorf("failed to record migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package db
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openTestDatabase opens an empty SQLite file without migrating it
+func openTestDatabase(t *testing.T) (*Database, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kettle.db")
+	db, err := OpenDatabase(path)
+	if err != nil {
+		t.Fatalf("OpenDatabase failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+// latestVersion returns LatestVersion or fails the test
+func latestVersion(t *testing.T) int {
+	t.Helper()
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatalf("LatestVersion failed: %v", err)
+	}
+	return latest
+}
+
+func TestMigrateBaselineDatabaseKeepsData(t *testing.T) {
+	db, path := openTestDatabase(t)
+
+	// The only table written by releases before metric history existed
+	baseline := `
+	CREATE TABLE metrics (
+		metric_name TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		unit TEXT NOT NULL,
+		value DOUBLE NOT NULL DEFAULT 0,
+		reset_daily BOOLEAN NOT NULL DEFAULT FALSE,
+		last_reset TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO metrics (metric_name, type, unit, value, reset_daily) VALUES
+		('coffee', 'Food', 'cups', 3, TRUE),
+		('books', 'Brain', 'count', 12, FALSE);`
+	if _, err := db.conn.Exec(baseline); err != nil {
+		t.Fatalf("failed to create baseline schema: %v", err)
+	}
+	db.Close()
+
+	migrated, err := NewDatabase(path)
+	if err != nil {
+		t.Fatalf("NewDatabase failed on a baseline database: %v", err)
+	}
+	defer migrated.Close()
+
+	version, err := migrated.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if latest := latestVersion(t); version != latest {
+		t.Errorf("schema version = %d, want %d", version, latest)
+	}
+
+	metrics, err := migrated.GetMetrics()
+	if err != nil {
+		t.Fatalf("GetMetrics failed: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("got %d metrics after migrating, want 2", len(metrics))
+	}
+	for _, m := range metrics {
+		switch {
+		case m.MetricName == "coffee" && m.Value == 3 && m.Reset.Kind == ResetDaily && m.Unit == "cups":
+		case m.MetricName == "books" && m.Value == 12 && m.Reset.Kind == ResetNone && m.Type == "Brain":
+		default:
+			t.Errorf("metric changed by the migration: %+v", m)
+		}
+	}
+
+	// The migrated schema is usable, not just readable
+	if err := migrated.IncrementMetric("coffee", 1, time.Time{}, ""); err != nil {
+		t.Errorf("IncrementMetric failed after migrating: %v", err)
+	}
+	if err := migrated.DeleteMetric("books"); err != nil {
+		t.Errorf("DeleteMetric failed after migrating: %v", err)
+	}
+}
+
+func TestMigrateRefusesNewerDatabase(t *testing.T) {
+	db, path := openTestDatabase(t)
+	latest := latestVersion(t)
+
+	if err := db.MigrateTo(latest); err != nil {
+		t.Fatalf("MigrateTo failed: %v", err)
+	}
+	if _, err := db.conn.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'from_the_future', 0);`, latest+1); err != nil {
+		t.Fatalf("failed to fake a newer schema: %v", err)
+	}
+
+	if err := db.MigrateTo(latest); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("MigrateTo on a newer database returned %v, want a refusal", err)
+	}
+	db.Close()
+
+	if newer, err := NewDatabase(path); err == nil {
+		newer.Close()
+		t.Error("NewDatabase opened a database newer than the binary")
+	}
+}
+
+func TestMigrateToLowerTarget(t *testing.T) {
+	db, _ := openTestDatabase(t)
+	latest := latestVersion(t)
+
+	if err := db.MigrateTo(1); err != nil {
+		t.Fatalf("MigrateTo(1) failed: %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != 1 {
+		t.Fatalf("schema version = %d, want 1", version)
+	}
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Applied != (s.Version == 1) {
+			t.Errorf("migration %04d_%s applied = %t", s.Version, s.Name, s.Applied)
+		}
+	}
+
+	if err := db.MigrateTo(latest); err != nil {
+		t.Fatalf("MigrateTo(%d) failed: %v", latest, err)
+	}
+	if err := db.MigrateTo(1); err == nil || !strings.Contains(err.Error(), "downgrading") {
+		t.Errorf("MigrateTo(1) on version %d returned %v, want a refusal", latest, err)
+	}
+	if err := db.MigrateTo(latest); err != nil {
+		t.Errorf("MigrateTo at the current version failed: %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != latest {
+		t.Errorf("schema version = %d, want %d", version, latest)
+	}
+}
+
+func TestFailingMigrationRollsBack(t *testing.T) {
+	db, _ := openTestDatabase(t)
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	broken := append(migrations[:1:1], Migration{
+		Version: 2,
+		Name:    "broken",
+		SQL:     `CREATE TABLE half_done (id INTEGER); INSERT INTO missing_table VALUES (1);`,
+	})
+
+	if err := db.migrate(broken, 2); err == nil {
+		t.Fatal("a failing migration succeeded")
+	}
+
+	if version, err := db.SchemaVersion(); err != nil || version != 0 {
+		t.Errorf("schema version = %d (%v), want 0", version, err)
+	}
+	var tables int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('metrics', 'half_done', 'schema_version');`).Scan(&tables); err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("%d tables survived the failed migration, want 0", tables)
+	}
+}
+
+func TestMigrateRecoversLastReset(t *testing.T) {
+	db, _ := openTestDatabase(t)
+
+	if err := db.MigrateTo(4); err != nil {
+		t.Fatalf("MigrateTo(4) failed: %v", err)
+	}
+
+	// Earlier releases stored the time of the latest entry as a Go time string
+	added := time.Date(2024, time.March, 10, 9, 30, 0, 0, time.Local)
+	reset := time.Date(2024, time.March, 12, 0, 0, 0, 0, time.Local)
+	setup := `
+	INSERT INTO metrics (metric_name, type, unit, value, reset_policy, last_reset) VALUES
+		('coffee', 'Food', 'cups', 3, 'daily', '2024-03-12 18:03:11.123456789 +0100 CET'),
+		('books', 'Brain', 'count', 12, 'none', '2024-03-12 18:03:11.123456789 +0100 CET');
+	INSERT INTO metric_events (metric_name, operation, delta, value, occurred_at) VALUES
+		('coffee', 'add', 0, 0, ?),
+		('coffee', 'reset', -2, 0, ?),
+		('coffee', 'increment', 3, 3, ?),
+		('books', 'add', 0, 0, ?);`
+	if _, err := db.conn.Exec(setup, added.Unix(), reset.Unix(), reset.Add(time.Hour).Unix(), added.Unix()); err != nil {
+		t.Fatalf("failed to fill the version 4 schema: %v", err)
+	}
+
+	if err := db.MigrateTo(latestVersion(t)); err != nil {
+		t.Fatalf("MigrateTo failed: %v", err)
+	}
+
+	for name, want := range map[string]time.Time{"coffee": reset, "books": added} {
+		m, err := db.GetMetric(name)
+		if err != nil {
+			t.Fatalf("GetMetric failed: %v", err)
+		}
+		if !m.LastReset.Equal(want) {
+			t.Errorf("%s last reset = %v, want %v", name, m.LastReset, want)
+		}
+	}
+}
+
+func TestMigrateKeepsNegativeValues(t *testing.T) {
+	db, _ := openTestDatabase(t)
+
+	if err := db.MigrateTo(6); err != nil {
+		t.Fatalf("MigrateTo(6) failed: %v", err)
+	}
+	setup := `
+	INSERT INTO metrics (metric_name, type, unit, value, reset_policy, last_reset) VALUES
+		('balance', 'House', 'EUR', -20, 'none', 0),
+		('coffee', 'Food', 'cups', 3, 'daily', 0);`
+	if _, err := db.conn.Exec(setup); err != nil {
+		t.Fatalf("failed to fill the version 6 schema: %v", err)
+	}
+
+	if err := db.MigrateTo(latestVersion(t)); err != nil {
+		t.Fatalf("MigrateTo failed: %v", err)
+	}
+
+	for name, want := range map[string]bool{"balance": true, "coffee": false} {
+		m, err := db.GetMetric(name)
+		if err != nil {
+			t.Fatalf("GetMetric failed: %v", err)
+		}
+		if m.Constraints.AllowNegative != want {
+			t.Errorf("%s allows negative values = %v, want %v", name, m.Constraints.AllowNegative, want)
+		}
+	}
+}
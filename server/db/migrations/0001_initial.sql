@@ -0,0 +1,31 @@
+-- Tables created by releases before versioned migrations existed. IF NOT EXISTS
+-- lets this step adopt kettle.db files that already contain some of them.
+CREATE TABLE IF NOT EXISTS metrics (
+	metric_name TEXT PRIMARY KEY,
+	type TEXT NOT NULL,
+	unit TEXT NOT NULL,
+	value DOUBLE NOT NULL DEFAULT 0,
+	reset_daily BOOLEAN NOT NULL DEFAULT FALSE,
+	last_reset TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS metric_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	metric_name TEXT NOT NULL,
+	operation TEXT NOT NULL,
+	delta DOUBLE NOT NULL,
+	value DOUBLE NOT NULL,
+	occurred_at INTEGER NOT NULL -- Unix seconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_metric_events_name_time ON metric_events (metric_name, occurred_at);
+
+CREATE TABLE IF NOT EXISTS daily_rollups (
+	metric_name TEXT NOT NULL,
+	day TEXT NOT NULL, -- YYYY-MM-DD
+	final_value DOUBLE NOT NULL,
+	min_value DOUBLE NOT NULL,
+	max_value DOUBLE NOT NULL,
+	update_count INTEGER NOT NULL,
+	PRIMARY KEY (metric_name, day)
+);
@@ -0,0 +1,3 @@
+-- Deleted metrics stay in the table until purged. NULL means the metric is live,
+-- otherwise the Unix time it was moved to the trash.
+ALTER TABLE metrics ADD COLUMN deleted_at INTEGER;
@@ -0,0 +1,8 @@
+-- Former names of renamed metrics that are still exported to Prometheus so
+-- existing dashboards and recording rules keep working.
+CREATE TABLE metric_aliases (
+	alias TEXT PRIMARY KEY,
+	metric_name TEXT NOT NULL
+);
+
+CREATE INDEX idx_metric_aliases_metric_name ON metric_aliases (metric_name);
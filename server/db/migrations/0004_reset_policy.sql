@@ -0,0 +1,7 @@
+-- Replace the reset_daily flag with a reset policy in the text form of
+-- ResetPolicy: none, hourly, daily, weekly:<day>, monthly or cron:<expression>.
+ALTER TABLE metrics ADD COLUMN reset_policy TEXT NOT NULL DEFAULT 'none';
+
+UPDATE metrics SET reset_policy = 'daily' WHERE reset_daily;
+
+ALTER TABLE metrics DROP COLUMN reset_daily;
@@ -0,0 +1,9 @@
+-- last_reset held Go time strings that could not be read back and was
+-- overwritten by every entry. Store the time of the last reset in Unix seconds
+-- instead, recovered from the history: the latest reset, else the time the
+-- metric was added, else now.
+UPDATE metrics SET last_reset = COALESCE(
+	(SELECT MAX(occurred_at) FROM metric_events
+	 WHERE metric_events.metric_name = metrics.metric_name AND operation IN ('reset', 'add')),
+	CAST(strftime('%s', 'now') AS INTEGER)
+);
@@ -0,0 +1,5 @@
+-- Per-metric day boundary: the "HH:MM" wall clock time its days start at and
+-- the IANA zone that clock is read in. Empty values use the server-wide ones.
+ALTER TABLE metrics ADD COLUMN day_start TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE metrics ADD COLUMN timezone TEXT NOT NULL DEFAULT '';
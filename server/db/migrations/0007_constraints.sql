@@ -0,0 +1,14 @@
+-- Per-metric value constraints, see Constraints. NULL bounds are open and a
+-- step of 0 allows any value. Metrics that already hold a negative value keep
+-- accepting negative values.
+ALTER TABLE metrics ADD COLUMN min_value REAL;
+
+ALTER TABLE metrics ADD COLUMN max_value REAL;
+
+ALTER TABLE metrics ADD COLUMN integer_only BOOLEAN NOT NULL DEFAULT 0;
+
+ALTER TABLE metrics ADD COLUMN step REAL NOT NULL DEFAULT 0;
+
+ALTER TABLE metrics ADD COLUMN allow_negative BOOLEAN NOT NULL DEFAULT 0;
+
+UPDATE metrics SET allow_negative = 1 WHERE value < 0;
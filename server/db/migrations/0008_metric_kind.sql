@@ -0,0 +1,3 @@
+-- The kind of a metric: counter, gauge, boolean, rating or duration. Existing
+-- metrics accept any entry, which makes them gauges.
+ALTER TABLE metrics ADD COLUMN kind TEXT NOT NULL DEFAULT 'gauge';
@@ -0,0 +1,4 @@
+-- Per-metric goals, see Goal. An empty direction is no goal.
+ALTER TABLE metrics ADD COLUMN goal_direction TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE metrics ADD COLUMN goal_target REAL NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Days of the week that do not break a streak, see Weekdays
+ALTER TABLE metrics ADD COLUMN rest_days TEXT NOT NULL DEFAULT '';
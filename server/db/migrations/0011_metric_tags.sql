@@ -0,0 +1,8 @@
+-- Free-form key/value tags of metrics, see Tags. The exporter turns the
+-- keys on its allowlist into Prometheus labels.
+CREATE TABLE metric_tags (
+	metric_name TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (metric_name, key)
+);
@@ -0,0 +1,3 @@
+-- Free text the user attached to an increment, decrement or update, empty
+-- for entries without a note and for every other operation.
+ALTER TABLE metric_events ADD COLUMN note TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- Expression a derived metric computes its value with from other metrics,
+-- empty for every other kind. The value column of a derived metric is not
+-- read; it is computed whenever the metric is.
+ALTER TABLE metrics ADD COLUMN formula TEXT NOT NULL DEFAULT '';
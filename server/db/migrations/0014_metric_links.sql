@@ -0,0 +1,8 @@
+-- Link rules, see Link. An increment or decrement of source also changes
+-- target by the same amount times multiplier, in the same transaction.
+CREATE TABLE metric_links (
+	source TEXT NOT NULL,
+	target TEXT NOT NULL,
+	multiplier REAL NOT NULL,
+	PRIMARY KEY (source, target)
+);
@@ -0,0 +1,5 @@
+-- The event an undo event takes back, NULL for every other event. Reverted
+-- events stay in the history and are told apart by the undo event pointing
+-- at them.
+ALTER TABLE metric_events ADD COLUMN reverts INTEGER;
+CREATE INDEX idx_metric_events_reverts ON metric_events (reverts);
@@ -0,0 +1,5 @@
+-- The event of the source metric an increment or decrement was fanned out
+-- from through a link, NULL for entries made directly. Reverting the source
+-- event also reverts the events fanned out from it.
+ALTER TABLE metric_events ADD COLUMN source INTEGER;
+CREATE INDEX idx_metric_events_source ON metric_events (source);
@@ -0,0 +1,15 @@
+-- Changes made through the server that Undo can take back and Redo apply
+-- again, see JournalOp, kept so the journal survives a restart. The entry
+-- columns are set for increments, decrements and updates only.
+CREATE TABLE undo_journal (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	client_id TEXT NOT NULL,
+	metric_name TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	amount REAL NOT NULL DEFAULT 0,
+	unit TEXT NOT NULL DEFAULT '',
+	occurred_at INTEGER,
+	note TEXT NOT NULL DEFAULT '',
+	event_id INTEGER,
+	undone INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxNoteLength bounds the note of an entry, in bytes
+const MaxNoteLength = 1000
+
+// CleanNote trims a note and rejects one that is too long to store
+func CleanNote(note string) (string, error) {
+	note = strings.TrimSpace(note)
+	if len(note) > MaxNoteLength {
+		return "", fmt.Errorf("note is %d bytes long, the limit is %d", len(note), MaxNoteLength)
+	}
+	return note, nil
+}
+
+// SearchNotes returns the entries with a note containing text, ignoring
+// case, newest first. An empty text matches every note and an empty
+// metricName every metric; start, end and limit filter like they do for
+// GetMetricHistory. Entries without a note are never returned. The note and
+// the limit are applied by the query, so a search reads only what it returns.
+func (db *Database) SearchNotes(text, metricName string, start, end time.Time, limit int) ([]DBEvent, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	text = strings.ToLower(strings.TrimSpace(text))
+	events, err := db.queryEvents(`note != '' AND instr(lower(note), ?) > 0`, []any{text}, metricName, start, end, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notes: %w", err)
+	}
+	return events, nil
+}
+
+// matchesNote reports whether an event has a note containing text, which is
+// lowercase
+func matchesNote(e DBEvent, text string) bool {
+	return e.Note != "" && strings.Contains(strings.ToLower(e.Note), text)
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ResetKind selects how often a metric is archived and set back to 0
+type ResetKind string
+
+// Reset kinds understood by ParseResetPolicy
+const (
+	ResetNone    ResetKind = "none"
+	ResetHourly  ResetKind = "hourly"
+	ResetDaily   ResetKind = "daily"
+	ResetWeekly  ResetKind = "weekly"
+	ResetMonthly ResetKind = "monthly"
+	ResetCron    ResetKind = "cron"
+)
+
+// ResetPolicy describes the periods of a metric. At the end of each period
+// the value is archived into the rollup of the day the period started and
+// set back to 0. The zero value never resets.
+type ResetPolicy struct {
+	Kind      ResetKind
+	WeekStart time.Weekday // First day of the week for weekly resets
+	Cron      string       // Standard five-field expression for cron resets
+}
+
+// ParseResetPolicy parses the text form of a policy: "none" (or ""),
+// "hourly", "daily", "weekly" (starting on Monday), "weekly:<day>",
+// "monthly" or "cron:<expression>".
+func ParseResetPolicy(s string) (ResetPolicy, error) {
+	s = strings.TrimSpace(s)
+	kind, arg, _ := strings.Cut(s, ":")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	arg = strings.TrimSpace(arg)
+
+	switch ResetKind(kind) {
+	case "", ResetNone:
+		return ResetPolicy{Kind: ResetNone}, nil
+	case ResetHourly, ResetDaily, ResetMonthly:
+		if arg != "" {
+			return ResetPolicy{}, fmt.Errorf("reset policy %s takes no argument", kind)
+		}
+		return ResetPolicy{Kind: ResetKind(kind)}, nil
+	case ResetWeekly:
+		policy := ResetPolicy{Kind: ResetWeekly, WeekStart: time.Monday}
+		if arg == "" {
+			return policy, nil
+		}
+		day, err := parseWeekday(arg)
+		if err != nil {
+			return ResetPolicy{}, err
+		}
+		policy.WeekStart = day
+		return policy, nil
+	case ResetCron:
+		if _, err := cron.ParseStandard(arg); err != nil {
+			return ResetPolicy{}, fmt.Errorf("invalid cron expression %q: %w", arg, err)
+		}
+		return ResetPolicy{Kind: ResetCron, Cron: arg}, nil
+	default:
+		return ResetPolicy{}, fmt.Errorf("unknown reset policy %q", s)
+	}
+}
+
+// parseWeekday accepts English day names and their three letter abbreviations
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if s == name || s == name[:3] {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day of the week %q", s)
+}
+
+// String returns the text form accepted by ParseResetPolicy
+func (p ResetPolicy) String() string {
+	switch p.Kind {
+	case "", ResetNone:
+		return string(ResetNone)
+	case ResetWeekly:
+		return string(ResetWeekly) + ":" + strings.ToLower(p.WeekStart.String())
+	case ResetCron:
+		return string(ResetCron) + ":" + p.Cron
+	default:
+		return string(p.Kind)
+	}
+}
+
+// MarshalText stores the policy in its text form
+func (p ResetPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText reads a policy stored by MarshalText
+func (p *ResetPolicy) UnmarshalText(text []byte) error {
+	policy, err := ParseResetPolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+// Scan reads a policy stored in the reset_policy column
+func (p *ResetPolicy) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return p.UnmarshalText([]byte(v))
+	case []byte:
+		return p.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a reset policy", src)
+	}
+}
+
+// Value stores the policy in the reset_policy column in its text form
+func (p ResetPolicy) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+// Resets reports whether the policy ever resets the metric
+func (p ResetPolicy) Resets() bool {
+	return p.Kind != "" && p.Kind != ResetNone
+}
+
+// PeriodStart returns the start of the period containing t, or the zero time
+// if the policy never resets
+func (p ResetPolicy) PeriodStart(t time.Time, day DayBoundary) time.Time {
+	start, _ := p.period(t, day)
+	return start
+}
+
+// Next returns the start of the period following the one containing t, or
+// the zero time if the policy never resets
+func (p ResetPolicy) Next(t time.Time, day DayBoundary) time.Time {
+	_, next := p.period(t, day)
+	return next
+}
+
+// period returns the start of the period containing t and of the one after
+// it. Days, weeks and months start at the day boundary; hours and cron
+// schedules follow the wall clock of its zone.
+func (p ResetPolicy) period(t time.Time, day DayBoundary) (start, next time.Time) {
+	loc := day.location()
+	year, month, date := day.date(t)
+
+	switch p.Kind {
+	case ResetHourly:
+		// Truncating the shifted instant keeps every hour one hour long when
+		// the clocks change
+		_, offset := t.In(loc).Zone()
+		shift := time.Duration(offset) * time.Second
+		start = t.Add(shift).Truncate(time.Hour).Add(-shift).In(loc)
+		return start, start.Add(time.Hour)
+	case ResetDaily:
+		return day.on(year, month, date), day.on(year, month, date+1)
+	case ResetWeekly:
+		weekday := time.Date(year, month, date, 12, 0, 0, 0, time.UTC).Weekday()
+		back := (int(weekday) - int(p.WeekStart) + 7) % 7
+		return day.on(year, month, date-back), day.on(year, month, date-back+7)
+	case ResetMonthly:
+		return day.on(year, month, 1), day.on(year, month+1, 1)
+	case ResetCron:
+		schedule, err := cron.ParseStandard(p.Cron)
+		if err != nil {
+			return time.Time{}, time.Time{}
+		}
+		start = previousFire(schedule, t.In(loc))
+		if start.IsZero() {
+			return time.Time{}, time.Time{}
+		}
+		return start, schedule.Next(t.In(loc))
+	default:
+		return time.Time{}, time.Time{}
+	}
+}
+
+// rollupWindow returns the day of the rollup the period starting at
+// periodStart is archived into, and the range of events it covers. Periods
+// shorter than a day share the rollup of their day, so the range starts with
+// the first period of that day.
+func (p ResetPolicy) rollupWindow(periodStart time.Time, day DayBoundary) (date string, start, end time.Time) {
+	start = day.StartOfDay(periodStart)
+	if !p.PeriodStart(start, day).Equal(start) {
+		start = p.Next(start, day)
+	}
+	return day.Date(periodStart), start, p.Next(periodStart, day)
+}
+
+// rollupDay returns the day of the rollup an entry at t belongs to
+func (p ResetPolicy) rollupDay(t time.Time, day DayBoundary) string {
+	return day.Date(p.PeriodStart(t, day))
+}
+
+// previousFire returns the last time at or before t when schedule fires, or
+// the zero time if it did not fire in the last four years. The cron library
+// only searches forward, so the window is widened backwards until it contains
+// a firing time and then walked up to t.
+func previousFire(schedule cron.Schedule, t time.Time) time.Time {
+	const limit = 4 * 366 * 24 * time.Hour
+	for window := time.Minute; window <= limit; window *= 2 {
+		fire := schedule.Next(t.Add(-window))
+		if fire.IsZero() || fire.After(t) {
+			continue
+		}
+		for {
+			next := schedule.Next(fire)
+			if next.IsZero() || next.After(t) {
+				return fire
+			}
+			fire = next
+		}
+	}
+	return time.Time{}
+}
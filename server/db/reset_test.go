@@ -0,0 +1,383 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// localMidnight is the default day boundary of a store
+var localMidnight = DayBoundary{Location: time.Local}
+
+// local returns the given wall clock time in the local zone
+func local(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+}
+
+// newYork returns a day boundary at start in America/New_York, which changes
+// its clocks on 2024-03-10 and 2024-11-03
+func newYork(t *testing.T, start string) DayBoundary {
+	t.Helper()
+	day, err := ParseDayBoundary(start, "America/New_York", DayBoundary{})
+	if err != nil {
+		t.Fatalf("ParseDayBoundary failed: %v", err)
+	}
+	return day
+}
+
+// clock is a settable time source for WithClock
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+func TestParseResetPolicy(t *testing.T) {
+	valid := map[string]string{
+		"":                  "none",
+		"None":              "none",
+		"hourly":            "hourly",
+		" daily ":           "daily",
+		"weekly":            "weekly:monday",
+		"weekly:Sun":        "weekly:sunday",
+		"weekly:saturday":   "weekly:saturday",
+		"monthly":           "monthly",
+		"cron:0 6,18 * * *": "cron:0 6,18 * * *",
+		"cron:@weekly":      "cron:@weekly",
+	}
+	for input, want := range valid {
+		policy, err := ParseResetPolicy(input)
+		if err != nil {
+			t.Errorf("ParseResetPolicy(%q) failed: %v", input, err)
+			continue
+		}
+		if got := policy.String(); got != want {
+			t.Errorf("ParseResetPolicy(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"yearly", "daily:monday", "weekly:someday", "cron:", "cron:61 * * * *"} {
+		if _, err := ParseResetPolicy(input); err == nil {
+			t.Errorf("ParseResetPolicy(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestResetPolicyPeriods(t *testing.T) {
+	// A Wednesday afternoon
+	now := local(2024, time.March, 13, 15, 42)
+
+	tests := []struct {
+		policy      string
+		start, next time.Time
+	}{
+		{"hourly", local(2024, time.March, 13, 15, 0), local(2024, time.March, 13, 16, 0)},
+		{"daily", local(2024, time.March, 13, 0, 0), local(2024, time.March, 14, 0, 0)},
+		{"weekly", local(2024, time.March, 11, 0, 0), local(2024, time.March, 18, 0, 0)},
+		{"weekly:wednesday", local(2024, time.March, 13, 0, 0), local(2024, time.March, 20, 0, 0)},
+		{"weekly:thursday", local(2024, time.March, 7, 0, 0), local(2024, time.March, 14, 0, 0)},
+		{"monthly", local(2024, time.March, 1, 0, 0), local(2024, time.April, 1, 0, 0)},
+		{"cron:0 6,18 * * *", local(2024, time.March, 13, 6, 0), local(2024, time.March, 13, 18, 0)},
+		{"cron:0 0 1 1 *", local(2024, time.January, 1, 0, 0), local(2025, time.January, 1, 0, 0)},
+	}
+	for _, tt := range tests {
+		policy, err := ParseResetPolicy(tt.policy)
+		if err != nil {
+			t.Fatalf("ParseResetPolicy(%q) failed: %v", tt.policy, err)
+		}
+		if got := policy.PeriodStart(now, localMidnight); !got.Equal(tt.start) {
+			t.Errorf("%s: PeriodStart = %v, want %v", tt.policy, got, tt.start)
+		}
+		if got := policy.Next(now, localMidnight); !got.Equal(tt.next) {
+			t.Errorf("%s: Next = %v, want %v", tt.policy, got, tt.next)
+		}
+		if got := policy.PeriodStart(tt.start, localMidnight); !got.Equal(tt.start) {
+			t.Errorf("%s: a period does not start at its own start: %v", tt.policy, got)
+		}
+	}
+
+	none := ResetPolicy{}
+	if none.Resets() || !none.PeriodStart(now, localMidnight).IsZero() || !none.Next(now, localMidnight).IsZero() {
+		t.Error("the zero policy resets")
+	}
+}
+
+func TestResetPolicyRollupWindow(t *testing.T) {
+	twiceDaily, err := ParseResetPolicy("cron:0 6,18 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The morning period of a day is preceded by the evening period of the
+	// day before, so the window of the day's rollup starts at 06:00
+	day, start, end := twiceDaily.rollupWindow(local(2024, time.March, 13, 18, 0), localMidnight)
+	if day != "2024-03-13" || !start.Equal(local(2024, time.March, 13, 6, 0)) || !end.Equal(local(2024, time.March, 14, 6, 0)) {
+		t.Errorf("rollupWindow = %s [%v, %v)", day, start, end)
+	}
+
+	weekly := ResetPolicy{Kind: ResetWeekly, WeekStart: time.Monday}
+	day, start, end = weekly.rollupWindow(local(2024, time.March, 11, 0, 0), localMidnight)
+	if day != "2024-03-11" || !start.Equal(local(2024, time.March, 11, 0, 0)) || !end.Equal(local(2024, time.March, 18, 0, 0)) {
+		t.Errorf("weekly rollupWindow = %s [%v, %v)", day, start, end)
+	}
+}
+
+func TestParseDayBoundary(t *testing.T) {
+	day, err := ParseDayBoundary("02:30", "Asia/Tokyo", localMidnight)
+	if err != nil {
+		t.Fatalf("ParseDayBoundary failed: %v", err)
+	}
+	if got := day.String(); got != "02:30 Asia/Tokyo" {
+		t.Errorf("day boundary = %q, want 02:30 Asia/Tokyo", got)
+	}
+
+	// Empty values keep the defaults
+	day, err = ParseDayBoundary("", " ", newYork(t, "04:00"))
+	if err != nil {
+		t.Fatalf("ParseDayBoundary failed: %v", err)
+	}
+	if got := day.String(); got != "04:00 America/New_York" {
+		t.Errorf("day boundary = %q, want the defaults", got)
+	}
+
+	for _, input := range [][2]string{{"24:00", ""}, {"2am", ""}, {"", "Mars/Olympus_Mons"}} {
+		if _, err := ParseDayBoundary(input[0], input[1], localMidnight); err == nil {
+			t.Errorf("ParseDayBoundary(%q, %q) succeeded, want an error", input[0], input[1])
+		}
+	}
+}
+
+func TestDayBoundaryPeriods(t *testing.T) {
+	day := newYork(t, "02:00")
+	at := func(year int, month time.Month, date, hour, minute int) time.Time {
+		return time.Date(year, month, date, hour, minute, 0, 0, day.Location)
+	}
+	// The second 01:30 of the night the clocks go back, in standard time
+	fallBack := day.on(2024, time.November, 3).Add(-30 * time.Minute)
+
+	tests := []struct {
+		name        string
+		policy      ResetPolicy
+		t           time.Time
+		start, next time.Time
+		date        string
+	}{
+		{"before the boundary", ResetPolicy{Kind: ResetDaily}, at(2024, time.March, 13, 1, 30),
+			at(2024, time.March, 12, 2, 0), at(2024, time.March, 13, 2, 0), "2024-03-12"},
+		{"at the boundary", ResetPolicy{Kind: ResetDaily}, at(2024, time.March, 13, 2, 0),
+			at(2024, time.March, 13, 2, 0), at(2024, time.March, 14, 2, 0), "2024-03-13"},
+		// 02:00 does not exist on the day the clocks go forward, so that day
+		// starts at 03:00 and is 23 hours long
+		{"spring forward", ResetPolicy{Kind: ResetDaily}, at(2024, time.March, 10, 12, 0),
+			at(2024, time.March, 10, 3, 0), at(2024, time.March, 11, 2, 0), "2024-03-10"},
+		{"before spring forward", ResetPolicy{Kind: ResetDaily}, at(2024, time.March, 10, 1, 59),
+			at(2024, time.March, 9, 2, 0), at(2024, time.March, 10, 3, 0), "2024-03-09"},
+		// The day the clocks go back is 25 hours long and both 01:30s belong
+		// to the day before
+		{"fall back", ResetPolicy{Kind: ResetDaily}, fallBack,
+			at(2024, time.November, 2, 2, 0), day.on(2024, time.November, 3), "2024-11-02"},
+		{"weekly across spring forward", ResetPolicy{Kind: ResetWeekly, WeekStart: time.Monday}, at(2024, time.March, 11, 1, 0),
+			at(2024, time.March, 4, 2, 0), at(2024, time.March, 11, 2, 0), "2024-03-04"},
+		{"monthly", ResetPolicy{Kind: ResetMonthly}, at(2024, time.April, 1, 1, 0),
+			at(2024, time.March, 1, 2, 0), at(2024, time.April, 1, 2, 0), "2024-03-01"},
+		{"hourly after spring forward", ResetPolicy{Kind: ResetHourly}, at(2024, time.March, 10, 3, 30),
+			at(2024, time.March, 10, 3, 0), at(2024, time.March, 10, 4, 0), "2024-03-10"},
+		{"hourly in the repeated hour", ResetPolicy{Kind: ResetHourly}, fallBack,
+			fallBack.Add(-30 * time.Minute), fallBack.Add(30 * time.Minute), "2024-11-02"},
+		{"cron in the day's zone", ResetPolicy{Kind: ResetCron, Cron: "0 6 * * *"}, at(2024, time.March, 13, 5, 0),
+			at(2024, time.March, 12, 6, 0), at(2024, time.March, 13, 6, 0), "2024-03-12"},
+	}
+	for _, tt := range tests {
+		start, next := tt.policy.PeriodStart(tt.t, day), tt.policy.Next(tt.t, day)
+		if !start.Equal(tt.start) || !next.Equal(tt.next) {
+			t.Errorf("%s: period = [%v, %v), want [%v, %v)", tt.name, start, next, tt.start, tt.next)
+		}
+		if got := tt.policy.rollupDay(tt.t, day); got != tt.date {
+			t.Errorf("%s: rollup day = %s, want %s", tt.name, got, tt.date)
+		}
+	}
+
+	if got := day.on(2024, time.March, 11).Sub(day.on(2024, time.March, 10)); got != 23*time.Hour {
+		t.Errorf("the day the clocks go forward lasts %v, want 23h", got)
+	}
+	if got := day.on(2024, time.November, 3).Sub(day.on(2024, time.November, 2)); got != 25*time.Hour {
+		t.Errorf("the day the clocks go back lasts %v, want 25h", got)
+	}
+}
+
+func TestRunResetsUsesDayBoundary(t *testing.T) {
+	stores := map[string]func(t *testing.T, opts ...Option) Store{
+		"sqlite": func(t *testing.T, opts ...Option) Store {
+			db, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"), opts...)
+			if err != nil {
+				t.Fatalf("NewDatabase failed: %v", err)
+			}
+			return db
+		},
+		"memory": func(_ *testing.T, opts ...Option) Store { return NewMemoryStore(opts...) },
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			day := newYork(t, "02:00")
+			tokyo, err := time.LoadLocation("Asia/Tokyo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := &clock{now: time.Date(2024, time.March, 9, 16, 0, 0, 0, day.Location)}
+			store := open(t, WithClock(now.Now), WithDayBoundary(day))
+			defer store.Close()
+
+			for _, m := range []DBMetric{
+				{MetricName: "coffee", Type: "Food", Unit: "cups", Reset: ResetPolicy{Kind: ResetDaily}},
+				// Days of this metric start at 05:00 in Tokyo, where it is
+				// already Sunday
+				{MetricName: "sake", Type: "Food", Unit: "cups", Reset: ResetPolicy{Kind: ResetDaily}, DayStart: "05:00", Timezone: "Asia/Tokyo"},
+			} {
+				if err := store.AddMetric(m); err != nil {
+					t.Fatalf("AddMetric failed: %v", err)
+				}
+			}
+			if m, _ := store.GetMetric("sake"); m.Day.String() != "05:00 Asia/Tokyo" {
+				t.Errorf("sake day boundary = %s, want its own", m.Day)
+			}
+			if err := store.AddMetric(DBMetric{MetricName: "mead", Type: "Food", Unit: "cups", Timezone: "Atlantis"}); err == nil {
+				t.Error("AddMetric accepted an unknown time zone")
+			}
+
+			// A late coffee at 01:30 the night the clocks go forward still
+			// counts toward Saturday
+			now.now = time.Date(2024, time.March, 10, 1, 30, 0, 0, day.Location)
+			for _, name := range []string{"coffee", "sake"} {
+				if err := store.IncrementMetric(name, 2, time.Time{}, ""); err != nil {
+					t.Fatalf("IncrementMetric failed: %v", err)
+				}
+			}
+			if wake := runResets(store, now.Now()); !wake.Equal(now.now.Add(resetRescan)) {
+				t.Errorf("scheduler wakes at %v, want a minute later", wake)
+			}
+			if m, _ := store.GetMetric("coffee"); m.Value != 2 {
+				t.Errorf("coffee was reset before the day ended: %v", m.Value)
+			}
+
+			// 02:00 is skipped that night, so Saturday ends at 03:00
+			now.now = time.Date(2024, time.March, 10, 3, 0, 0, 0, day.Location)
+			runResets(store, now.Now())
+			coffee, err := store.GetMetric("coffee")
+			if err != nil {
+				t.Fatalf("GetMetric failed: %v", err)
+			}
+			if coffee.Value != 0 || !coffee.LastReset.Equal(now.now) {
+				t.Errorf("coffee = %v last reset %v, want 0 last reset %v", coffee.Value, coffee.LastReset, now.now)
+			}
+			if m, _ := store.GetMetric("sake"); m.Value != 2 {
+				t.Errorf("sake was reset at 03:00 in New York: %v", m.Value)
+			}
+
+			// A backdated entry before 03:00 lands in Saturday's rollup
+			if err := store.IncrementMetric("coffee", 1, now.now.Add(-time.Minute), ""); err != nil {
+				t.Fatalf("IncrementMetric failed: %v", err)
+			}
+			rollups, err := store.GetDailyRollups("coffee", "", "")
+			if err != nil {
+				t.Fatalf("GetDailyRollups failed: %v", err)
+			}
+			if len(rollups) != 1 || rollups[0].Date != "2024-03-09" || rollups[0].FinalValue != 3 {
+				t.Errorf("coffee rollups = %+v, want 3 on 2024-03-09", rollups)
+			}
+
+			// Sake's day ends at 05:00 in Tokyo
+			now.now = time.Date(2024, time.March, 11, 5, 0, 0, 0, tokyo)
+			runResets(store, now.Now())
+			if m, _ := store.GetMetric("sake"); m.Value != 0 {
+				t.Errorf("sake was not reset at 05:00 in Tokyo: %v", m.Value)
+			}
+			rollups, err = store.GetDailyRollups("sake", "", "")
+			if err != nil {
+				t.Fatalf("GetDailyRollups failed: %v", err)
+			}
+			if len(rollups) != 1 || rollups[0].Date != "2024-03-10" || rollups[0].FinalValue != 2 {
+				t.Errorf("sake rollups = %+v, want 2 on 2024-03-10", rollups)
+			}
+		})
+	}
+}
+
+func TestRunResetsWakesAtPeriodEnd(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if err := store.AddMetric(DBMetric{MetricName: "coffee", Type: "Food", Unit: "cups", Reset: ResetPolicy{Kind: ResetDaily}}); err != nil {
+		t.Fatalf("AddMetric failed: %v", err)
+	}
+
+	// The metric was last reset after the day the scheduler is run on, so there
+	// is nothing to reset yet and the scheduler sleeps until midnight
+	beforeMidnight := local(2024, time.March, 13, 23, 59)
+	if wake := runResets(store, beforeMidnight); !wake.Equal(local(2024, time.March, 14, 0, 0)) {
+		t.Errorf("scheduler wakes at %v, want midnight", wake)
+	}
+	if wake := runResets(store, local(2024, time.March, 13, 12, 0)); !wake.Equal(local(2024, time.March, 13, 12, 1)) {
+		t.Errorf("scheduler wakes at %v, want a minute later", wake)
+	}
+}
+
+func TestRunResetsCatchesUpMissedResets(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"sqlite": func(t *testing.T) Store {
+			db, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"))
+			if err != nil {
+				t.Fatalf("NewDatabase failed: %v", err)
+			}
+			return db
+		},
+		"memory": func(*testing.T) Store { return NewMemoryStore() },
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			// The server was stopped three days ago, after the last reset
+			now := time.Now()
+			stoppedAt := time.Date(now.Year(), now.Month(), now.Day()-3, 12, 0, 0, 0, time.Local)
+			for _, m := range []DBMetric{
+				{MetricName: "coffee", Type: "Food", Unit: "cups", Value: 2, Reset: ResetPolicy{Kind: ResetDaily}, LastReset: stoppedAt},
+				{MetricName: "tea", Type: "Food", Unit: "cups", Value: 1, Reset: ResetPolicy{Kind: ResetDaily}},
+				{MetricName: "books", Type: "Brain", Unit: "count", Value: 5, LastReset: stoppedAt},
+			} {
+				if err := store.AddMetric(m); err != nil {
+					t.Fatalf("AddMetric failed: %v", err)
+				}
+			}
+
+			runResets(store, now)
+
+			coffee, err := store.GetMetric("coffee")
+			if err != nil {
+				t.Fatalf("GetMetric failed: %v", err)
+			}
+			if today := localMidnight.StartOfDay(now); coffee.Value != 0 || !coffee.LastReset.Equal(today) {
+				t.Errorf("coffee after catching up = %v last reset %v, want 0 last reset %v", coffee.Value, coffee.LastReset, today)
+			}
+			rollups, err := store.GetDailyRollups("", "", "")
+			if err != nil {
+				t.Fatalf("GetDailyRollups failed: %v", err)
+			}
+			if want := stoppedAt.Format(DateFormat); len(rollups) != 1 || rollups[0].MetricName != "coffee" || rollups[0].Date != want || rollups[0].FinalValue != 2 {
+				t.Errorf("rollups = %+v, want coffee 2 on %s", rollups, want)
+			}
+			for name, want := range map[string]float64{"tea": 1, "books": 5} {
+				if m, _ := store.GetMetric(name); m.Value != want {
+					t.Errorf("%s was reset to %v, want %v", name, m.Value, want)
+				}
+			}
+
+			// Once caught up, the next tick has nothing left to do
+			if err := store.IncrementMetric("coffee", 1, time.Time{}, ""); err != nil {
+				t.Fatalf("IncrementMetric failed: %v", err)
+			}
+			runResets(store, now)
+			if m, _ := store.GetMetric("coffee"); m.Value != 1 {
+				t.Errorf("coffee was reset again: %v", m.Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// rollups.go
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DateFormat is the layout of the day keys used by daily rollups
+const DateFormat = "2006-01-02"
+
+// DBRollup represents the archived closing state of a metric for one day
+type DBRollup struct {
+	MetricName  string
+	Date        string // Day in DateFormat
+	FinalValue  float64
+	MinValue    float64
+	MaxValue    float64
+	UpdateCount int
+}
+
+// apply folds a backdated increment, decrement or update into the rollup
+func (r *DBRollup) apply(operation string, amount float64, metric DBMetric) error {
+	value := r.FinalValue
+	switch operation {
+	case OpIncrement:
+		value += amount
+	case OpDecrement:
+		value -= amount
+	case OpUpdate:
+		value = amount
+	default:
+		return fmt.Errorf("operation %s cannot be backdated", operation)
+	}
+	if err := metric.checkEntry(r.FinalValue, value); err != nil {
+		return fmt.Errorf("metric %s on %s: %w", r.MetricName, r.Date, err)
+	}
+	r.FinalValue = value
+
+	r.MinValue = min(r.MinValue, r.FinalValue)
+	r.MaxValue = max(r.MaxValue, r.FinalValue)
+	r.UpdateCount++
+	return nil
+}
+
+// merge folds an existing rollup of the same day into r, which holds the live
+// value archived at a reset. Such a rollup was created by backdated entries
+// logged before the reset ran; both started from 0 that day, so their final
+// values add up. r's update count already covers the backdated entries since
+// they are recorded as events of that day too.
+func (r *DBRollup) merge(existing DBRollup) {
+	r.FinalValue += existing.FinalValue
+	r.MinValue = min(r.MinValue, existing.MinValue)
+	r.MaxValue = max(r.MaxValue, existing.MaxValue, r.FinalValue)
+}
+
+// archivePeriod stores the closing value of a metric for the period starting
+// at periodStart as part of tx. Min, max and the number of updates are derived
+// from the events recorded during the rollup window of the period that were
+// not reverted. An existing
+// rollup for the same day is merged with the closing value rather than
+// overwritten.
+func archivePeriod(tx *sql.Tx, metricName string, policy ResetPolicy, day DayBoundary, periodStart time.Time, finalValue float64) error {
+	date, windowStart, windowEnd := policy.rollupWindow(periodStart, day)
+
+	statsQuery := `
+	SELECT MIN(value), MAX(value), COUNT(*) FROM metric_events
+	WHERE metric_name = ? AND operation IN (?, ?, ?) AND occurred_at >= ? AND occurred_at < ?
+	AND NOT EXISTS (SELECT 1 FROM metric_events AS undo WHERE undo.reverts = metric_events.id);`
+
+	var minValue, maxValue sql.NullFloat64
+	var count int
+	err := tx.QueryRow(statsQuery, metricName, OpIncrement, OpDecrement, OpUpdate, windowStart.Unix(), windowEnd.Unix()).Scan(&minValue, &maxValue, &count)
+	if err != nil {
+		return fmt.Errorf("failed to compute period stats: %w", err)
+	}
+
+	rollup := DBRollup{
+		MetricName:  metricName,
+		Date:        date,
+		FinalValue:  finalValue,
+		MinValue:    finalValue,
+		MaxValue:    finalValue,
+		UpdateCount: count,
+	}
+	if minValue.Valid && minValue.Float64 < rollup.MinValue {
+		rollup.MinValue = minValue.Float64
+	}
+	if maxValue.Valid && maxValue.Float64 > rollup.MaxValue {
+		rollup.MaxValue = maxValue.Float64
+	}
+
+	var existing DBRollup
+	selectQuery := `SELECT final_value, min_value, max_value, update_count FROM daily_rollups WHERE metric_name = ? AND day = ?;`
+	err = tx.QueryRow(selectQuery, metricName, rollup.Date).Scan(&existing.FinalValue, &existing.MinValue, &existing.MaxValue, &existing.UpdateCount)
+	switch {
+	case err == nil:
+		rollup.merge(existing)
+	case err != sql.ErrNoRows:
+		return fmt.Errorf("failed to read daily rollup: %w", err)
+	}
+
+	return saveRollup(tx, rollup)
+}
+
+// ResetMetric archives the current value of a metric as the closing value of
+// the period starting at periodStart and sets the value back to 0 in one
+// transaction. The metric's last reset moves to the start of the current
+// period, so periods missed in between are not reset again. Metrics without a
+// reset policy cannot be reset.
+func (db *Database) ResetMetric(metricName string, periodStart time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + metricColumns + ` FROM metrics WHERE metric_name = ? AND deleted_at IS NULL;`
+	metric, err := scanMetric(tx.QueryRow(query, metricName))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("metric %s does not exist", metricName)
+		}
+		return fmt.Errorf("failed to read metric: %w", err)
+	}
+	policy, day := metric.Reset, db.cfg.dayOf(metric)
+	if !policy.Resets() {
+		return fmt.Errorf("metric %s has no reset policy", metricName)
+	}
+
+	if err := archivePeriod(tx, metricName, policy, day, policy.PeriodStart(periodStart, day), metric.Value); err != nil {
+		return err
+	}
+
+	now := db.cfg.now()
+	updateQuery := `UPDATE metrics SET value = 0, last_reset = ? WHERE metric_name = ?;`
+	if _, err := tx.Exec(updateQuery, policy.PeriodStart(now, day).Unix(), metricName); err != nil {
+		return fmt.Errorf("failed to reset metric: %w", err)
+	}
+
+	if err := recordEvent(tx, metricName, OpReset, -metric.Value, 0, now, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applyToRollup applies a backdated increment, decrement or update to the
+// archived value of the period containing occurredAt as part of tx, leaving
+// the live value untouched, and returns the archived value before and after.
+// A day without a rollup yet starts from 0, like a freshly reset metric does.
+func applyToRollup(tx *sql.Tx, metric DBMetric, day DayBoundary, operation string, amount float64, occurredAt time.Time) (oldValue, newValue float64, err error) {
+	metricName := metric.MetricName
+	date := metric.Reset.rollupDay(occurredAt, day)
+	rollup := DBRollup{MetricName: metricName, Date: date}
+
+	selectQuery := `SELECT final_value, min_value, max_value, update_count FROM daily_rollups WHERE metric_name = ? AND day = ?;`
+	err = tx.QueryRow(selectQuery, metricName, date).Scan(&rollup.FinalValue, &rollup.MinValue, &rollup.MaxValue, &rollup.UpdateCount)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, 0, fmt.Errorf("failed to read daily rollup: %w", err)
+	}
+
+	oldValue = rollup.FinalValue
+	if err := rollup.apply(operation, amount, metric); err != nil {
+		return 0, 0, err
+	}
+
+	if err := saveRollup(tx, rollup); err != nil {
+		return 0, 0, err
+	}
+	return oldValue, rollup.FinalValue, nil
+}
+
+// saveRollup inserts or replaces a daily rollup as part of tx
+func saveRollup(tx *sql.Tx, rollup DBRollup) error {
+	upsertQuery := `
+	INSERT INTO daily_rollups (metric_name, day, final_value, min_value, max_value, update_count)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (metric_name, day) DO UPDATE SET
+		final_value = excluded.final_value,
+		min_value = excluded.min_value,
+		max_value = excluded.max_value,
+		update_count = excluded.update_count;`
+
+	_, err := tx.Exec(upsertQuery, rollup.MetricName, rollup.Date, rollup.FinalValue, rollup.MinValue, rollup.MaxValue, rollup.UpdateCount)
+	if err != nil {
+		return fmt.Errorf("failed to update daily rollup: %w", err)
+	}
+	return nil
+}
+
+// GetDailyRollups retrieves archived daily values, newest day first. An empty
+// metricName matches every metric; startDate and endDate are inclusive days in
+// DateFormat and may be left empty for an open range.
+func (db *Database) GetDailyRollups(metricName, startDate, endDate string) ([]DBRollup, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	query := `SELECT metric_name, day, final_value, min_value, max_value, update_count FROM daily_rollups WHERE 1 = 1`
+	var args []any
+	if metricName != "" {
+		query += ` AND metric_name = ?`
+		args = append(args, metricName)
+	}
+	if startDate != "" {
+		query += ` AND day >= ?`
+		args = append(args, startDate)
+	}
+	if endDate != "" {
+		query += ` AND day <= ?`
+		args = append(args, endDate)
+	}
+	query += ` ORDER BY day DESC, metric_name;`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily rollups: %w", err)
+	}
+	defer rows.Close()
+
+	var rollups []DBRollup
+	for rows.Next() {
+		var r DBRollup
+		if err := rows.Scan(&r.MetricName, &r.Date, &r.FinalValue, &r.MinValue, &r.MaxValue, &r.UpdateCount); err != nil {
+			return nil, fmt.Errorf("failed to scan daily rollup: %w", err)
+		}
+		rollups = append(rollups, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return rollups, nil
+}
@@ -0,0 +1,102 @@
+// store.go
+package db
+
+import (
+	"log"
+	"time"
+)
+
+// Store is the storage backend used by the gRPC server, the Prometheus
+// exporter and the reset scheduler. Database (SQLite) is the default
+// implementation; MemoryStore and BoltStore are drop-in alternatives.
+type Store interface {
+	AddMetric(metric DBMetric) error
+	GetMetrics() ([]DBMetric, error)
+	GetMetric(metricName string) (*DBMetric, error)
+	UpdateMetric(metricName string, newValue float64, occurredAt time.Time, note string) error
+	IncrementMetric(metricName string, increment float64, occurredAt time.Time, note string) error
+	DecrementMetric(metricName string, decrement float64, occurredAt time.Time, note string) error
+	ApplyEntries(entries []Entry) ([]DBEvent, error)
+	RevertEntries(eventIDs []int64) error
+	EditMetric(metricName string, edit MetricEdit) error
+	DeleteMetric(metricName string) error
+	ListDeletedMetrics() ([]DBMetric, error)
+	RestoreMetric(metricName string) error
+	PurgeMetric(metricName string) error
+	PurgeDeletedMetrics(cutoff time.Time) ([]string, error)
+	ResetMetric(metricName string, periodStart time.Time) error
+	GetMetricHistory(metricName string, start, end time.Time, limit int) ([]DBEvent, error)
+	SearchNotes(text, metricName string, start, end time.Time, limit int) ([]DBEvent, error)
+	GetDailyRollups(metricName, startDate, endDate string) ([]DBRollup, error)
+	ImportMetric(data MetricData, policy ConflictPolicy, dryRun bool) (ImportResult, error)
+	SetLink(link Link) error
+	DeleteLink(source, target string) error
+	GetLinks(metricName string) ([]Link, error)
+	GetJournal() ([]JournalOp, error)
+	SaveJournalOp(op JournalOp, drop []int64) (int64, error)
+	DeleteJournalOps(ids []int64) error
+	Close() error
+}
+
+// resetRescan bounds how long the scheduler sleeps, so it notices new metrics
+// and policy changes without being told about them
+const resetRescan = time.Minute
+
+// runResets resets every metric of store whose last reset lies before the
+// start of its current period at now, and returns when it has to run again.
+// A reset that was due more than resetRescan ago was missed while the server
+// was stopped or asleep; it is caught up the same way, archiving the value
+// into the period the metric was last reset into.
+func runResets(store Store, now time.Time) time.Time {
+	wake := now.Add(resetRescan)
+
+	metrics, err := store.GetMetrics()
+	if err != nil {
+		log.Printf("Failed to retrieve metrics for the reset scheduler: %v", err)
+		return wake
+	}
+
+	for _, metric := range metrics {
+		current := metric.Reset.PeriodStart(now, metric.Day)
+		if current.IsZero() {
+			continue
+		}
+
+		if metric.LastReset.Before(current) {
+			closing := metric.Reset.PeriodStart(metric.LastReset, metric.Day)
+			dueAt := metric.Reset.Next(metric.LastReset, metric.Day)
+
+			// Archive the metric's value into the period that ended and reset it to 0
+			if err := store.ResetMetric(metric.MetricName, closing); err != nil {
+				log.Printf("Failed to reset metric %s: %v", metric.MetricName, err)
+			} else if now.Sub(dueAt) > resetRescan {
+				log.Printf("Caught up missed reset of metric %s: archived %v into %s (due %s, %s)",
+					metric.MetricName, metric.Value, metric.Day.Date(closing), dueAt.Format(time.RFC3339), metric.Reset)
+			} else {
+				log.Printf("Reset metric %s to 0 (%s)", metric.MetricName, metric.Reset)
+			}
+		}
+
+		if next := metric.Reset.Next(now, metric.Day); next.Before(wake) {
+			wake = next
+		}
+	}
+	return wake
+}
+
+// StartResetScheduler resets the metrics of store at the end of each period
+// of their reset policy until stopChan is closed or receives a value. Resets
+// missed while the server was not running are caught up right away.
+func StartResetScheduler(store Store, stopChan chan bool) {
+	for {
+		wake := runResets(store, time.Now())
+		timer := time.NewTimer(time.Until(wake))
+		select {
+		case <-stopChan:
+			timer.Stop()
+			log.Println("Stopping the reset scheduler.")
+			return
+		case <-timer.C:
+		}
+	}
+}
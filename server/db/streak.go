@@ -0,0 +1,138 @@
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Weekdays is a set of days of the week, such as the rest days of a streak
+type Weekdays []time.Weekday
+
+// ParseWeekdays parses a list of English day names or their three letter
+// abbreviations separated by commas or spaces. An empty list is no days.
+func ParseWeekdays(s string) (Weekdays, error) {
+	var days Weekdays
+	for _, name := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		day, err := parseWeekday(name)
+		if err != nil {
+			return nil, err
+		}
+		if !days.Contains(day) {
+			days = append(days, day)
+		}
+	}
+	slices.Sort(days)
+	return days, nil
+}
+
+// Contains reports whether day is one of the days
+func (w Weekdays) Contains(day time.Weekday) bool {
+	return slices.Contains(w, day)
+}
+
+// String returns the text form accepted by ParseWeekdays
+func (w Weekdays) String() string {
+	names := make([]string, len(w))
+	for i, day := range w {
+		names[i] = strings.ToLower(day.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// MarshalText stores the days in their text form
+func (w Weekdays) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// UnmarshalText reads days stored by MarshalText
+func (w *Weekdays) UnmarshalText(text []byte) error {
+	days, err := ParseWeekdays(string(text))
+	if err != nil {
+		return err
+	}
+	*w = days
+	return nil
+}
+
+// Scan reads days stored in the rest_days column
+func (w *Weekdays) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return w.UnmarshalText([]byte(v))
+	case []byte:
+		return w.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into days of the week", src)
+	}
+}
+
+// Value stores the days in the rest_days column in their text form
+func (w Weekdays) Value() (driver.Value, error) {
+	return w.String(), nil
+}
+
+// Streak counts the consecutive days a daily metric met its goal
+type Streak struct {
+	MetricName string
+	Current    int  // Days in a row up to today; today only counts once its goal is met
+	Longest    int  // Most days in a row ever, including the current streak
+	MetToday   bool // Today's value already meets the goal
+}
+
+// HasStreak reports whether m keeps a streak, which requires a goal for each
+// day and thus a daily reset
+func (m DBMetric) HasStreak() bool {
+	return m.Goal.Set() && m.Reset.Kind == ResetDaily
+}
+
+// Streak computes the streaks of m at now from the archived values of its
+// past days and its live value for today. A day without a rollup closed at
+// 0. A rest day on which the goal was missed neither breaks a streak nor adds
+// to it, and neither does today while it is still under way.
+func (m DBMetric) Streak(rollups []DBRollup, now time.Time) Streak {
+	streak := Streak{MetricName: m.MetricName}
+	today := m.Day.Date(now)
+
+	values := make(map[string]float64, len(rollups))
+	first := today
+	for _, r := range rollups {
+		values[r.Date] = r.FinalValue
+		if r.Date < first {
+			first = r.Date
+		}
+	}
+	values[today] = m.Value
+
+	start, err := time.Parse(DateFormat, first)
+	if err != nil {
+		return streak
+	}
+	for day := start; day.Format(DateFormat) <= today; day = day.AddDate(0, 0, 1) {
+		date := day.Format(DateFormat)
+		met := m.Goal.Met(values[date])
+		switch {
+		case met:
+			streak.Current++
+		case date == today, m.RestDays.Contains(day.Weekday()):
+		default:
+			streak.Current = 0
+		}
+		streak.Longest = max(streak.Longest, streak.Current)
+		if date == today {
+			streak.MetToday = met
+		}
+	}
+	return streak
+}
+
+// MetricStreak computes the streaks of metric at now from its rollups in store
+func MetricStreak(store Store, metric DBMetric, now time.Time) (Streak, error) {
+	rollups, err := store.GetDailyRollups(metric.MetricName, "", "")
+	if err != nil {
+		return Streak{}, fmt.Errorf("failed to compute the streak of metric %s: %w", metric.MetricName, err)
+	}
+	return metric.Streak(rollups, now), nil
+}
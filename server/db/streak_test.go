@@ -0,0 +1,49 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreak(t *testing.T) {
+	// Wednesday; the week before had a missed Wednesday and a Saturday
+	// without any entries
+	now := time.Date(2024, 10, 16, 12, 0, 0, 0, time.UTC)
+	day := DayBoundary{Location: time.UTC}
+	rollups := []DBRollup{
+		{Date: "2024-10-07", FinalValue: 8},
+		{Date: "2024-10-08", FinalValue: 9},
+		{Date: "2024-10-09", FinalValue: 2},
+		{Date: "2024-10-10", FinalValue: 8},
+		{Date: "2024-10-11", FinalValue: 8},
+		{Date: "2024-10-13", FinalValue: 8},
+		{Date: "2024-10-14", FinalValue: 8},
+		{Date: "2024-10-15", FinalValue: 10},
+	}
+	weekend, err := ParseWeekdays("sun, Saturday")
+	if err != nil {
+		t.Fatalf("ParseWeekdays failed: %v", err)
+	}
+	if weekend.String() != "sunday,saturday" {
+		t.Errorf("weekend = %q", weekend)
+	}
+
+	tests := []struct {
+		name     string
+		goal     Goal
+		restDays Weekdays
+		today    float64
+		want     Streak
+	}{
+		{"today under way", Goal{GoalAtLeast, 8}, weekend, 3, Streak{Current: 5, Longest: 5}},
+		{"today met", Goal{GoalAtLeast, 8}, weekend, 8, Streak{Current: 6, Longest: 6, MetToday: true}},
+		{"no rest days", Goal{GoalAtLeast, 8}, nil, 8, Streak{Current: 4, Longest: 4, MetToday: true}},
+		{"at most", Goal{GoalAtMost, 8}, nil, 9, Streak{Current: 0, Longest: 6}},
+	}
+	for _, tt := range tests {
+		m := DBMetric{Reset: ResetPolicy{Kind: ResetDaily}, Day: day, Goal: tt.goal, RestDays: tt.restDays, Value: tt.today}
+		if got := m.Streak(rollups, now); got != tt.want {
+			t.Errorf("%s: Streak = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Tags are free-form key/value pairs that categorize a metric beyond its type
+// and unit, such as person=alex or room=kitchen. The exporter turns the keys
+// on its allowlist into Prometheus labels, so keys follow the rules of label
+// names.
+type Tags map[string]string
+
+// ReservedTagKeys are the labels the exporter sets on every metric; tags
+// cannot replace them
+var ReservedTagKeys = []string{"metric_name", "type", "unit", "kind", "reset_daily", "reset_policy"}
+
+var tagKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// CheckTagKey returns an error unless key can name a tag and a Prometheus label
+func CheckTagKey(key string) error {
+	switch {
+	case !tagKeyPattern.MatchString(key):
+		return fmt.Errorf("invalid tag key %q, use letters, digits and underscores and do not start with a digit", key)
+	case strings.HasPrefix(key, "__"):
+		return fmt.Errorf("invalid tag key %q, keys starting with __ are reserved by Prometheus", key)
+	case slices.Contains(ReservedTagKeys, key):
+		return fmt.Errorf("tag key %q is reserved, it is already a label of every metric", key)
+	}
+	return nil
+}
+
+// ParseTags parses key=value pairs separated by commas, such as
+// "person=alex, room=kitchen". A key without a value is kept with an empty
+// value, which a metric rejects but a filter of GetMetrics reads as any value.
+// An empty string is no tags.
+func ParseTags(s string) (Tags, error) {
+	var tags Tags
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if err := CheckTagKey(key); err != nil {
+			return nil, err
+		}
+		if _, ok := tags[key]; ok {
+			return nil, fmt.Errorf("tag %s is given twice", key)
+		}
+		if tags == nil {
+			tags = make(Tags)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// Keys returns the keys of the tags in order
+func (t Tags) Keys() []string {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// String returns the text form accepted by ParseTags, ordered by key
+func (t Tags) String() string {
+	pairs := make([]string, 0, len(t))
+	for _, key := range t.Keys() {
+		pairs = append(pairs, key+"="+t[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// validate rejects tags that could not be written back as text or exported
+func (t Tags) validate() error {
+	for _, key := range t.Keys() {
+		if err := CheckTagKey(key); err != nil {
+			return err
+		}
+		value := t[key]
+		switch {
+		case value == "":
+			return fmt.Errorf("tag %s has no value", key)
+		case strings.Contains(value, ","):
+			return fmt.Errorf("value of tag %s cannot contain a comma", key)
+		case value != strings.TrimSpace(value):
+			return fmt.Errorf("value of tag %s cannot start or end with spaces", key)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the tags include every tag of filter. A filter tag
+// without a value matches any value of its key.
+func (t Tags) Matches(filter Tags) bool {
+	for key, want := range filter {
+		value, ok := t[key]
+		if !ok || want != "" && value != want {
+			return false
+		}
+	}
+	return true
+}
+
+// saveTags replaces the tags of metricName with tags as part of tx
+func saveTags(tx *sql.Tx, metricName string, tags Tags) error {
+	if _, err := tx.Exec(`DELETE FROM metric_tags WHERE metric_name = ?;`, metricName); err != nil {
+		return fmt.Errorf("failed to save tags: %w", err)
+	}
+	for _, key := range tags.Keys() {
+		_, err := tx.Exec(`INSERT INTO metric_tags (metric_name, key, value) VALUES (?, ?, ?);`, metricName, key, tags[key])
+		if err != nil {
+			return fmt.Errorf("failed to save tags: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadTags returns the tags of every metric keyed by metric name.
+// The caller must hold db.mu.
+func (db *Database) loadTags() (map[string]Tags, error) {
+	rows, err := db.conn.Query(`SELECT metric_name, key, value FROM metric_tags;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string]Tags)
+	for rows.Next() {
+		var metricName, key, value string
+		if err := rows.Scan(&metricName, &key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		if tags[metricName] == nil {
+			tags[metricName] = make(Tags)
+		}
+		tags[metricName][key] = value
+	}
+	return tags, rows.Err()
+}
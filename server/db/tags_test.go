@@ -0,0 +1,88 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags(" room=kitchen, person = alex ,,")
+	if err != nil {
+		t.Fatalf("ParseTags failed: %v", err)
+	}
+	if got := tags.String(); got != "person=alex,room=kitchen" {
+		t.Errorf("String() = %q", got)
+	}
+	if tags, err := ParseTags(""); err != nil || tags != nil {
+		t.Errorf("ParseTags(\"\") = %v, %v, want no tags", tags, err)
+	}
+
+	for _, s := range []string{"9lives=yes", "__name__=x", "unit=kg", "room=a,room=b", "my-tag=x"} {
+		if _, err := ParseTags(s); err == nil {
+			t.Errorf("ParseTags(%q) succeeded, want an error", s)
+		}
+	}
+	for _, tags := range []Tags{{"person": ""}, {"person": " alex"}} {
+		if err := tags.validate(); err == nil {
+			t.Errorf("validate(%v) succeeded, want an error", tags)
+		}
+	}
+
+	filter, _ := ParseTags("person")
+	if !tags.Matches(filter) || !tags.Matches(nil) || tags.Matches(Tags{"person": "sam"}) || tags.Matches(Tags{"floor": ""}) {
+		t.Errorf("Matches does not follow the filter rules")
+	}
+}
+
+func TestTagsFollowMetric(t *testing.T) {
+	sqlite, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer sqlite.Close()
+
+	for _, store := range []Store{sqlite, NewMemoryStore()} {
+		check := func(step, name, want string) {
+			t.Helper()
+			m, err := store.GetMetric(name)
+			if err != nil {
+				t.Fatalf("%T: %s: GetMetric failed: %v", store, step, err)
+			}
+			if got := m.Tags.String(); got != want {
+				t.Errorf("%T: %s: tags = %q, want %q", store, step, got, want)
+			}
+		}
+
+		if err := store.AddMetric(DBMetric{MetricName: "Dishes", Type: "House", Tags: Tags{"room": "kitchen"}}); err != nil {
+			t.Fatalf("AddMetric failed: %v", err)
+		}
+		check("add", "Dishes", "room=kitchen")
+
+		if err := store.EditMetric("Dishes", MetricEdit{Type: "Chores"}); err != nil {
+			t.Fatalf("EditMetric failed: %v", err)
+		}
+		check("edit without tags", "Dishes", "room=kitchen")
+
+		tags := Tags{"room": "kitchen", "person": "alex"}
+		if err := store.EditMetric("Dishes", MetricEdit{NewName: "Washing up", Tags: &tags}); err != nil {
+			t.Fatalf("EditMetric failed: %v", err)
+		}
+		check("rename", "Washing up", "person=alex,room=kitchen")
+
+		if err := store.EditMetric("Washing up", MetricEdit{Tags: &Tags{"person": ""}}); err == nil {
+			t.Errorf("%T: EditMetric accepted a tag without a value", store)
+		}
+
+		// A metric added under a purged name starts without tags
+		if err := store.DeleteMetric("Washing up"); err != nil {
+			t.Fatalf("DeleteMetric failed: %v", err)
+		}
+		if err := store.PurgeMetric("Washing up"); err != nil {
+			t.Fatalf("PurgeMetric failed: %v", err)
+		}
+		if err := store.AddMetric(DBMetric{MetricName: "Washing up", Type: "House"}); err != nil {
+			t.Fatalf("AddMetric failed: %v", err)
+		}
+		check("purge and add", "Washing up", "")
+	}
+}
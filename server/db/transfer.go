@@ -0,0 +1,323 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MetricData is a metric together with everything recorded about it, the
+// unit ExportData and ImportData move between servers
+type MetricData struct {
+	Metric  DBMetric
+	Events  []DBEvent  // Oldest first; IDs are assigned anew on import, undo events point at the new ones and fanned out events lose their source
+	Rollups []DBRollup // Oldest day first
+}
+
+// ConflictPolicy decides what importing a metric does when a metric with the
+// same name already exists, in the trash or not
+type ConflictPolicy string
+
+// Conflict policies understood by ParseConflictPolicy
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // Leave the existing metric alone
+	ConflictOverwrite ConflictPolicy = "overwrite" // Replace the existing metric and its history
+	ConflictMerge     ConflictPolicy = "merge"     // Keep the existing metric and add the history it lacks
+)
+
+// ParseConflictPolicy parses the name of a conflict policy. An empty name skips.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	policy := ConflictPolicy(strings.ToLower(strings.TrimSpace(s)))
+	switch policy {
+	case "":
+		return ConflictSkip, nil
+	case ConflictSkip, ConflictOverwrite, ConflictMerge:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q, expected skip, overwrite or merge", s)
+	}
+}
+
+// ImportOutcome says what importing a metric did
+type ImportOutcome string
+
+// Outcomes of ImportMetric
+const (
+	ImportCreated     ImportOutcome = "created"
+	ImportSkipped     ImportOutcome = "skipped"
+	ImportOverwritten ImportOutcome = "overwritten"
+	ImportMerged      ImportOutcome = "merged"
+)
+
+// ImportResult reports what ImportMetric did, or would do in a dry run
+type ImportResult struct {
+	MetricName string
+	Outcome    ImportOutcome
+	Events     int // Events added to the history
+	Rollups    int // Daily rollups added
+}
+
+// outcome returns what importing under p does to a metric that exists or not
+func (p ConflictPolicy) outcome(exists bool) ImportOutcome {
+	switch {
+	case !exists:
+		return ImportCreated
+	case p == ConflictOverwrite:
+		return ImportOverwritten
+	case p == ConflictMerge:
+		return ImportMerged
+	default:
+		return ImportSkipped
+	}
+}
+
+// errDryRun rolls back the transaction of a dry run import
+var errDryRun = errors.New("dry run")
+
+// prepare validates the metric like AddMetric does and points its events and
+// rollups at it
+func (d *MetricData) prepare() error {
+	if d.Metric.MetricName == "" {
+		return fmt.Errorf("metric has no name")
+	}
+	if err := prepareMetric(&d.Metric); err != nil {
+		return err
+	}
+	for i := range d.Events {
+		if d.Events[i].Operation == "" {
+			return fmt.Errorf("event %d has no operation", i+1)
+		}
+		d.Events[i].MetricName = d.Metric.MetricName
+	}
+	for i := range d.Rollups {
+		if _, err := time.Parse(DateFormat, d.Rollups[i].Date); err != nil {
+			return fmt.Errorf("rollup %d has an invalid day: %w", i+1, err)
+		}
+		d.Rollups[i].MetricName = d.Metric.MetricName
+	}
+	return nil
+}
+
+// eventIdentity identifies an event independently of its ID, so a merge can
+// tell which imported events the history already has
+func eventIdentity(e DBEvent) string {
+	return fmt.Sprintf("%s|%d|%g|%g", e.Operation, e.OccurredAt.Unix(), e.Delta, e.Value)
+}
+
+// eventIDs maps the IDs of imported events to the IDs they were stored under,
+// so undo events keep pointing at the events they took back
+type eventIDs map[int64]int64
+
+// add records that the imported event id was stored as stored. Events of CSV
+// bundles written before version 6 have no ID and are left out, so an event
+// pointing at no event stays that way.
+func (ids eventIDs) add(id, stored int64) {
+	if id != 0 {
+		ids[id] = stored
+	}
+}
+
+// ExportMetrics returns every metric of store, including those in the trash,
+// with their history and daily rollups, ordered by name
+func ExportMetrics(store Store) ([]MetricData, error) {
+	live, err := store.GetMetrics()
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := store.ListDeletedMetrics()
+	if err != nil {
+		return nil, err
+	}
+	metrics := append(live, trashed...)
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].MetricName < metrics[j].MetricName })
+
+	data := make([]MetricData, 0, len(metrics))
+	for _, m := range metrics {
+		events, err := store.GetMetricHistory(m.MetricName, time.Time{}, time.Time{}, 0)
+		if err != nil {
+			return nil, err
+		}
+		rollups, err := store.GetDailyRollups(m.MetricName, "", "")
+		if err != nil {
+			return nil, err
+		}
+		// Both come newest first
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+		for i, j := 0, len(rollups)-1; i < j; i, j = i+1, j-1 {
+			rollups[i], rollups[j] = rollups[j], rollups[i]
+		}
+		data = append(data, MetricData{Metric: m, Events: events, Rollups: rollups})
+	}
+	return data, nil
+}
+
+// ImportLinks sets the link rules of a bundle, once its metrics are
+// imported. A link between the same two metrics that already exists keeps
+// its multiplier unless policy overwrites. It returns how many links were set,
+// or would be in a dry run, how many were skipped, and an error for each link
+// that could not be set, such as one to a metric that failed to import. A dry
+// run cannot tell the latter, since the metrics were not imported.
+func ImportLinks(store Store, links []Link, policy ConflictPolicy, dryRun bool) (set, skipped int, errs []error) {
+	existing, err := store.GetLinks("")
+	if err != nil {
+		return 0, 0, []error{fmt.Errorf("failed to read links: %w", err)}
+	}
+	for _, link := range links {
+		exists := slices.ContainsFunc(existing, func(l Link) bool { return l.Source == link.Source && l.Target == link.Target })
+		if exists && policy != ConflictOverwrite {
+			skipped++
+			continue
+		}
+		if !dryRun {
+			if err := store.SetLink(link); err != nil {
+				errs = append(errs, fmt.Errorf("failed to link %s to %s: %w", link.Source, link.Target, err))
+				continue
+			}
+		}
+		set++
+	}
+	return set, skipped, errs
+}
+
+// ImportMetric stores a metric with its history in a single transaction. A
+// metric with the same name is left alone, replaced or merged with according
+// to policy. A dry run reports the same result without changing anything.
+func (db *Database) ImportMetric(data MetricData, policy ConflictPolicy, dryRun bool) (ImportResult, error) {
+	if err := data.prepare(); err != nil {
+		return ImportResult{}, fmt.Errorf("failed to import metric %s: %w", data.Metric.MetricName, err)
+	}
+	metric := data.Metric
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM metrics WHERE metric_name = ?;`, metric.MetricName).Scan(&exists); err != nil {
+		return ImportResult{}, fmt.Errorf("failed to import metric: %w", err)
+	}
+	owner, err := aliasOwner(tx, metric.MetricName)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to import metric: %w", err)
+	}
+	if owner != "" {
+		return ImportResult{}, fmt.Errorf("failed to import metric: %s is an alias of metric %s", metric.MetricName, owner)
+	}
+
+	result := ImportResult{MetricName: metric.MetricName, Outcome: policy.outcome(exists > 0)}
+	known := make(map[string]int64)
+	switch result.Outcome {
+	case ImportSkipped:
+		return result, nil
+	case ImportMerged:
+		history, err := tx.Query(`SELECT `+eventColumns+` FROM metric_events WHERE metric_name = ?;`, metric.MetricName)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("failed to import metric: %w", err)
+		}
+		defer history.Close()
+		for history.Next() {
+			e, err := scanEvent(history)
+			if err != nil {
+				return ImportResult{}, fmt.Errorf("failed to scan metric event: %w", err)
+			}
+			known[eventIdentity(e)] = e.ID
+		}
+		if err := history.Err(); err != nil {
+			return ImportResult{}, fmt.Errorf("row iteration error: %w", err)
+		}
+	default:
+		for _, query := range []string{
+			`DELETE FROM metrics WHERE metric_name = ?;`,
+			`DELETE FROM metric_aliases WHERE metric_name = ?;`,
+		} {
+			if _, err := tx.Exec(query, metric.MetricName); err != nil {
+				return ImportResult{}, fmt.Errorf("failed to import metric: %w", err)
+			}
+		}
+		if err := dropHistory(tx, metric.MetricName); err != nil {
+			return ImportResult{}, fmt.Errorf("failed to import metric: %w", err)
+		}
+		if err := insertMetric(tx, metric, db.cfg.now()); err != nil {
+			return ImportResult{}, fmt.Errorf("failed to import metric: %w", err)
+		}
+		for _, alias := range metric.Aliases {
+			if err := insertAlias(tx, alias, metric.MetricName); err != nil {
+				return ImportResult{}, fmt.Errorf("failed to import metric: %w", err)
+			}
+		}
+	}
+
+	ids := make(eventIDs)
+	for _, e := range data.Events {
+		if id, ok := known[eventIdentity(e)]; ok {
+			ids.add(e.ID, id)
+			continue
+		}
+		e.Reverts = ids[e.Reverts]
+		// The source of a fanned out event is an event of another metric
+		e.Source = 0
+		id, err := insertEvent(tx, e)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		known[eventIdentity(e)] = id
+		ids.add(e.ID, id)
+		result.Events++
+	}
+
+	insertQuery := `
+	INSERT INTO daily_rollups (metric_name, day, final_value, min_value, max_value, update_count)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (metric_name, day) DO NOTHING;`
+	for _, r := range data.Rollups {
+		res, err := tx.Exec(insertQuery, r.MetricName, r.Date, r.FinalValue, r.MinValue, r.MaxValue, r.UpdateCount)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("failed to import daily rollup: %w", err)
+		}
+		added, err := res.RowsAffected()
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("failed to import daily rollup: %w", err)
+		}
+		result.Rollups += int(added)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return ImportResult{}, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return result, nil
+}
+
+// insertAlias makes metricName export alias as part of tx, unless the alias
+// names another metric or is already taken
+func insertAlias(tx *sql.Tx, alias, metricName string) error {
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM metrics WHERE metric_name = ?;`, alias).Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return fmt.Errorf("alias %s is the name of another metric", alias)
+	}
+	owner, err := aliasOwner(tx, alias)
+	if err != nil {
+		return err
+	}
+	if owner != "" {
+		return fmt.Errorf("%s is already an alias of metric %s", alias, owner)
+	}
+	_, err = tx.Exec(`INSERT INTO metric_aliases (alias, metric_name) VALUES (?, ?);`, alias, metricName)
+	return err
+}
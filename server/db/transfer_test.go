@@ -0,0 +1,236 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// populate fills store with a metric that has history, rollups and tags, one
+// derived from it, one linked to it with an undone entry, one with
+// constraints, a goal and an alias, and one in the trash
+func populate(t *testing.T, store Store) {
+	t.Helper()
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	lowest, highest := 1.0, 5.0
+
+	steps := []struct {
+		name string
+		err  error
+	}{
+		{"add water", store.AddMetric(DBMetric{MetricName: "Water", Type: "Health", Unit: "glasses", Kind: KindCounter,
+			Reset: ResetPolicy{Kind: ResetDaily}, Goal: Goal{GoalAtLeast, 8}, RestDays: Weekdays{time.Sunday}, Tags: Tags{"person": "alex", "room": "kitchen"}})},
+		{"add hydration", store.AddMetric(DBMetric{MetricName: "Hydration", Type: "Health", Unit: "ml", Kind: KindDerived, Formula: "Water * 250"})},
+		{"increment water", store.IncrementMetric("Water", 3, now, "after the run, with lemon")},
+		{"backdate water", store.IncrementMetric("Water", 7, yesterday, "")},
+		{"add lemons", store.AddMetric(DBMetric{MetricName: "Lemons", Type: "Food", Unit: "slices", Kind: KindCounter})},
+		{"link lemons", store.SetLink(Link{Source: "Water", Target: "Lemons", Multiplier: 0.5})},
+		{"increment lemons", store.IncrementMetric("Lemons", 20, now, "meant 2")},
+		{"add mood", store.AddMetric(DBMetric{MetricName: "Mood", Type: "Mind", Unit: "stars", Kind: KindRating,
+			Constraints: Constraints{Min: &lowest, Max: &highest}, DayStart: "04:00", Timezone: "Europe/Berlin"})},
+		{"update mood", store.UpdateMetric("Mood", 4, now, "")},
+		{"rename mood", store.EditMetric("Mood", MetricEdit{NewName: "Feeling", KeepAlias: true})},
+		{"add coffee", store.AddMetric(DBMetric{MetricName: "Coffee", Type: "Drink", Unit: "cups, large"})},
+		{"delete coffee", store.DeleteMetric("Coffee")},
+	}
+	for _, step := range steps {
+		if step.err != nil {
+			t.Fatalf("%s failed: %v", step.name, step.err)
+		}
+	}
+
+	typo, err := store.SearchNotes("meant 2", "Lemons", time.Time{}, time.Time{}, 1)
+	if err != nil || len(typo) != 1 {
+		t.Fatalf("SearchNotes = %v, %v, want the entry of lemons", typo, err)
+	}
+	if err := store.RevertEntries([]int64{typo[0].ID}); err != nil {
+		t.Fatalf("undo lemons failed: %v", err)
+	}
+}
+
+// encodeBundle exports store and encodes it as CSV, which writes every time
+// in UTC, so two exports can be compared byte by byte. Event IDs are replaced
+// by their position in the history of the metric.
+func encodeBundle(t *testing.T, store Store) string {
+	t.Helper()
+	bundle, err := NewBundle(store, time.Date(2024, 10, 16, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("NewBundle failed: %v", err)
+	}
+	for i := range bundle.Metrics {
+		events := bundle.Metrics[i].Events
+		positions := make(map[int64]int64)
+		for j := range events {
+			positions[events[j].ID] = int64(j + 1)
+			events[j].ID, events[j].Reverts = int64(j+1), positions[events[j].Reverts]
+		}
+	}
+	var buf bytes.Buffer
+	if err := bundle.Encode(&buf, FormatCSV); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer source.Close()
+	populate(t, source)
+	want := encodeBundle(t, source)
+
+	for _, format := range []BundleFormat{FormatJSON, FormatCSV} {
+		bundle, err := NewBundle(source, time.Now())
+		if err != nil {
+			t.Fatalf("NewBundle failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := bundle.Encode(&buf, format); err != nil {
+			t.Fatalf("%s: Encode failed: %v", format, err)
+		}
+		decoded, err := DecodeBundle(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: DecodeBundle failed: %v", format, err)
+		}
+
+		sqlite, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"))
+		if err != nil {
+			t.Fatalf("NewDatabase failed: %v", err)
+		}
+		defer sqlite.Close()
+		for _, target := range []Store{sqlite, NewMemoryStore()} {
+			for _, data := range decoded.Metrics {
+				result, err := target.ImportMetric(data, ConflictSkip, false)
+				if err != nil {
+					t.Fatalf("%s: ImportMetric %s failed: %v", format, data.Metric.MetricName, err)
+				}
+				if result.Outcome != ImportCreated || result.Events != len(data.Events) || result.Rollups != len(data.Rollups) {
+					t.Errorf("%s: importing %s = %+v", format, data.Metric.MetricName, result)
+				}
+			}
+			if set, skipped, errs := ImportLinks(target, decoded.Links, ConflictSkip, false); set != 1 || skipped != 0 || errs != nil {
+				t.Errorf("%s into %T: ImportLinks = %d set, %d skipped, %v, want 1 set", format, target, set, skipped, errs)
+			}
+			if got := encodeBundle(t, target); got != want {
+				t.Errorf("%s into %T: round trip changed the data\ngot:\n%s\nwant:\n%s", format, target, got, want)
+			}
+		}
+	}
+}
+
+func TestImportConflictPolicies(t *testing.T) {
+	source := NewMemoryStore()
+	populate(t, source)
+	bundle, err := NewBundle(source, time.Now())
+	if err != nil {
+		t.Fatalf("NewBundle failed: %v", err)
+	}
+	var water MetricData
+	for _, data := range bundle.Metrics {
+		if data.Metric.MetricName == "Water" {
+			water = data
+		}
+	}
+
+	now := time.Now()
+	tests := []struct {
+		policy ConflictPolicy
+		dryRun bool
+		want   ImportResult
+		value  float64 // Value of Water afterwards
+		events int     // Events of Water afterwards
+	}{
+		{ConflictSkip, false, ImportResult{MetricName: "Water", Outcome: ImportSkipped}, 5, 2},
+		{ConflictMerge, true, ImportResult{MetricName: "Water", Outcome: ImportMerged, Events: 3, Rollups: 1}, 5, 2},
+		{ConflictMerge, false, ImportResult{MetricName: "Water", Outcome: ImportMerged, Events: 3, Rollups: 1}, 5, 5},
+		{ConflictOverwrite, false, ImportResult{MetricName: "Water", Outcome: ImportOverwritten, Events: 3, Rollups: 1}, 3, 3},
+	}
+	for _, tt := range tests {
+		// Added an hour later than the source, so the add events differ
+		target := NewMemoryStore(WithClock(func() time.Time { return now.Add(time.Hour) }))
+		if err := target.AddMetric(DBMetric{MetricName: "Water", Type: "Health", Unit: "ml"}); err != nil {
+			t.Fatalf("AddMetric failed: %v", err)
+		}
+		if err := target.IncrementMetric("Water", 5, now, ""); err != nil {
+			t.Fatalf("IncrementMetric failed: %v", err)
+		}
+
+		result, err := target.ImportMetric(water, tt.policy, tt.dryRun)
+		if err != nil {
+			t.Fatalf("%s: ImportMetric failed: %v", tt.policy, err)
+		}
+		if result != tt.want {
+			t.Errorf("%s (dry run %t): result %+v, want %+v", tt.policy, tt.dryRun, result, tt.want)
+		}
+
+		metric, err := target.GetMetric("Water")
+		if err != nil {
+			t.Fatalf("GetMetric failed: %v", err)
+		}
+		events, err := target.GetMetricHistory("Water", time.Time{}, time.Time{}, 0)
+		if err != nil {
+			t.Fatalf("GetMetricHistory failed: %v", err)
+		}
+		if metric.Value != tt.value || len(events) != tt.events {
+			t.Errorf("%s (dry run %t): value %g with %d events, want %g with %d", tt.policy, tt.dryRun, metric.Value, len(events), tt.value, tt.events)
+		}
+	}
+
+	// Links that exist keep their multiplier unless overwritten
+	target := NewMemoryStore()
+	populate(t, target)
+	relinked := []Link{{Source: "Water", Target: "Lemons", Multiplier: 2}, {Source: "Water", Target: "Tea", Multiplier: 1}}
+	for _, tt := range []struct {
+		policy     ConflictPolicy
+		dryRun     bool
+		set        int
+		skipped    int
+		multiplier float64
+		failed     int
+	}{
+		{ConflictMerge, false, 0, 1, 0.5, 1},
+		{ConflictOverwrite, true, 2, 0, 0.5, 0},
+		{ConflictOverwrite, false, 1, 0, 2, 1},
+	} {
+		set, skipped, errs := ImportLinks(target, relinked, tt.policy, tt.dryRun)
+		if set != tt.set || skipped != tt.skipped || len(errs) != tt.failed {
+			t.Errorf("%s (dry run %t): ImportLinks = %d set, %d skipped, %v, want %d set, %d skipped and %d failed",
+				tt.policy, tt.dryRun, set, skipped, errs, tt.set, tt.skipped, tt.failed)
+		}
+		for _, err := range errs {
+			if !strings.Contains(err.Error(), "metric Tea does not exist") {
+				t.Errorf("%s: ImportLinks failed with %v, want Tea missing", tt.policy, err)
+			}
+		}
+		links, err := target.GetLinks("Lemons")
+		if err != nil || len(links) != 1 || links[0].Multiplier != tt.multiplier {
+			t.Errorf("%s (dry run %t): links of Lemons = %+v, %v, want a multiplier of %g", tt.policy, tt.dryRun, links, err, tt.multiplier)
+		}
+	}
+
+	if _, err := ParseConflictPolicy("replace"); err == nil {
+		t.Error("ParseConflictPolicy accepted an unknown policy")
+	}
+	newer := fmt.Sprintf(`{"Version": %d}`, BundleVersion+1)
+	if _, err := DecodeBundle(bytes.NewBufferString(newer), FormatJSON); err == nil {
+		t.Error("DecodeBundle accepted a newer bundle")
+	}
+
+	// CSV bundles of older versions lack the columns added since
+	for version := 1; version < BundleVersion; version++ {
+		header := csvHeader(version)
+		row := make([]string, len(header))
+		row[slices.Index(header, "record")], row[slices.Index(header, "value")] = csvBundle, fmt.Sprint(version)
+		old := strings.Join(header, ",") + "\n" + strings.Join(row, ",") + "\n"
+		if b, err := DecodeBundle(bytes.NewBufferString(old), FormatCSV); err != nil || b.Version != version {
+			t.Errorf("DecodeBundle of a version %d CSV bundle = %+v, %v", version, b, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// trash.go
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ListDeletedMetrics retrieves the metrics in the trash, most recently deleted first
+func (db *Database) ListDeletedMetrics() ([]DBMetric, error) {
+	return db.queryMetrics(`deleted_at IS NOT NULL`, `deleted_at DESC`)
+}
+
+// RestoreMetric moves a metric out of the trash with its value intact
+func (db *Database) RestoreMetric(metricName string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var value float64
+	var formula string
+	err = tx.QueryRow(`SELECT value, formula FROM metrics WHERE metric_name = ? AND deleted_at IS NOT NULL;`, metricName).Scan(&value, &formula)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("metric '%s' is not in the trash", metricName)
+		}
+		return fmt.Errorf("failed to restore metric: %w", err)
+	}
+
+	// The metrics a derived metric is computed from may be gone by now
+	if formula != "" {
+		formulas, err := liveFormulas(tx)
+		if err != nil {
+			return fmt.Errorf("failed to restore metric: %w", err)
+		}
+		if _, err := checkFormulaChange(formulas, "", DBMetric{MetricName: metricName, Formula: formula}); err != nil {
+			return fmt.Errorf("failed to restore metric: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE metrics SET deleted_at = NULL WHERE metric_name = ?;`, metricName); err != nil {
+		return fmt.Errorf("failed to restore metric: %w", err)
+	}
+
+	if err := recordEvent(tx, metricName, OpRestore, 0, value, db.cfg.now(), ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeMetric permanently removes a metric from the trash together with its
+// history, daily rollups, aliases, tags and links. Only trashed metrics can be purged.
+func (db *Database) PurgeMetric(metricName string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := purgeMetric(tx, metricName); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// purgeMetric deletes a trashed metric and its history as part of tx
+func purgeMetric(tx *sql.Tx, metricName string) error {
+	var trashed int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM metrics WHERE metric_name = ? AND deleted_at IS NOT NULL;`, metricName).Scan(&trashed)
+	if err != nil {
+		return fmt.Errorf("failed to purge metric: %w", err)
+	}
+	if trashed == 0 {
+		return fmt.Errorf("metric '%s' is not in the trash", metricName)
+	}
+
+	for _, query := range []string{
+		`DELETE FROM metrics WHERE metric_name = ?;`,
+		`DELETE FROM metric_aliases WHERE metric_name = ?;`,
+		`DELETE FROM metric_tags WHERE metric_name = ?;`,
+		`DELETE FROM metric_links WHERE source = ?;`,
+		`DELETE FROM metric_links WHERE target = ?;`,
+	} {
+		if _, err := tx.Exec(query, metricName); err != nil {
+			return fmt.Errorf("failed to purge metric: %w", err)
+		}
+	}
+
+	return dropHistory(tx, metricName)
+}
+
+// dropHistory deletes the recorded events and daily rollups of metricName as
+// part of tx. Purging a metric drops its history, and so does reusing a name
+// whose history outlived its metric (deleted before the trash existed), so a
+// new metric never inherits the past of an old one.
+func dropHistory(tx *sql.Tx, metricName string) error {
+	for _, query := range []string{
+		`DELETE FROM metric_events WHERE metric_name = ?;`,
+		`DELETE FROM daily_rollups WHERE metric_name = ?;`,
+	} {
+		if _, err := tx.Exec(query, metricName); err != nil {
+			return fmt.Errorf("failed to drop history of %s: %w", metricName, err)
+		}
+	}
+	return nil
+}
+
+// PurgeDeletedMetrics permanently removes every metric that was moved to the
+// trash before cutoff and returns their names. The expired metrics are
+// selected in the same transaction that purges them, so a metric restored
+// meanwhile is simply left alone.
+func (db *Database) PurgeDeletedMetrics(cutoff time.Time) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	expired, err := expiredMetrics(tx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range expired {
+		if err := purgeMetric(tx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit purge: %w", err)
+	}
+	return expired, nil
+}
+
+// expiredMetrics returns the names of the metrics moved to the trash before cutoff as part of tx
+func expiredMetrics(tx *sql.Tx, cutoff time.Time) ([]string, error) {
+	rows, err := tx.Query(`SELECT metric_name FROM metrics WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY metric_name;`, cutoff.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the trash: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan metric name: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// StartTrashPurger periodically purges metrics that have been in the trash of
+// store for longer than retention until stopChan is closed. A retention of 0
+// keeps trashed metrics forever.
+func StartTrashPurger(store Store, retention time.Duration, stopChan chan bool) {
+	if retention <= 0 {
+		return
+	}
+
+	purge := func() {
+		purged, err := store.PurgeDeletedMetrics(time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Error purging the trash: %v", err)
+			return
+		}
+		for _, name := range purged {
+			log.Printf("Purged metric %s after %s in the trash", name, retention)
+		}
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	purge()
+	for {
+		select {
+		case <-stopChan:
+			log.Println("Stopping the trash purger.")
+			return
+		case <-ticker.C:
+			purge()
+		}
+	}
+}
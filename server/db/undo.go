@@ -0,0 +1,145 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// revertible reports whether an event records an entry that RevertEntries can
+// take back
+func revertible(e DBEvent) error {
+	if e.Reverted {
+		return fmt.Errorf("entry %d was already undone", e.ID)
+	}
+	switch e.Operation {
+	case OpIncrement, OpDecrement, OpUpdate:
+		return nil
+	}
+	return fmt.Errorf("event %d is a %s, only increments, decrements and updates can be reverted", e.ID, e.Operation)
+}
+
+// revertRollup takes the change of a backdated entry, or of an entry whose
+// period has been reset since, back out of the rollup of its period
+func revertRollup(rollup *DBRollup, e DBEvent) {
+	rollup.FinalValue -= e.Delta
+	rollup.MinValue = min(rollup.MinValue, rollup.FinalValue)
+	rollup.MaxValue = max(rollup.MaxValue, rollup.FinalValue)
+	rollup.UpdateCount = max(rollup.UpdateCount-1, 0)
+}
+
+// RevertEntries takes back the increments, decrements and updates recorded as
+// the given events in one transaction, together with the events fanned out
+// from them through links. Each change is subtracted from the value it went
+// into, the live value or the rollup of its period once that period has been
+// reset, so entries made since are kept; an update is reverted to the value
+// it replaced only when nothing changed the metric after it. The events stay
+// in the history, each marked as reverted by an undo event pointing back at
+// it. The kind and constraints of the metric are not checked, as the metric
+// goes back to a value it already had.
+func (db *Database) RevertEntries(eventIDs []int64) error {
+	now := db.cfg.now()
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reverted := make(map[int64]bool)
+	for _, id := range eventIDs {
+		if reverted[id] {
+			continue
+		}
+		fanned, err := fannedFrom(tx, id)
+		if err != nil {
+			return err
+		}
+		for _, id := range append([]int64{id}, fanned...) {
+			if reverted[id] {
+				continue
+			}
+			if err := db.revertEvent(tx, id, now); err != nil {
+				return err
+			}
+			reverted[id] = true
+		}
+	}
+
+	return tx.Commit()
+}
+
+// fannedFrom returns the events fanned out from the event source that were
+// not reverted yet as part of tx
+func fannedFrom(tx *sql.Tx, source int64) ([]int64, error) {
+	query := `SELECT id FROM metric_events
+	WHERE source = ? AND NOT EXISTS (SELECT 1 FROM metric_events AS undo WHERE undo.reverts = metric_events.id)
+	ORDER BY id;`
+	rows, err := tx.Query(query, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fanned out events: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan metric event: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// revertEvent takes back the event id and records the undo event as part of
+// tx, see RevertEntries
+func (db *Database) revertEvent(tx *sql.Tx, id int64, now time.Time) error {
+	e, err := scanEvent(tx.QueryRow(`SELECT `+eventColumns+` FROM metric_events WHERE id = ?;`, id))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("entry %d is no longer in the history", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read metric event: %w", err)
+	}
+	if err := revertible(e); err != nil {
+		return err
+	}
+
+	query := `SELECT ` + metricColumns + ` FROM metrics WHERE metric_name = ? AND deleted_at IS NULL;`
+	metric, err := scanMetric(tx.QueryRow(query, e.MetricName))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("metric %s does not exist", e.MetricName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read metric: %w", err)
+	}
+
+	var value float64
+	day := db.cfg.dayOf(metric)
+	if isBackdated(metric.Reset, day, e.OccurredAt, now) {
+		rollup := DBRollup{MetricName: e.MetricName, Date: metric.Reset.rollupDay(e.OccurredAt, day)}
+		selectQuery := `SELECT final_value, min_value, max_value, update_count FROM daily_rollups WHERE metric_name = ? AND day = ?;`
+		err := tx.QueryRow(selectQuery, rollup.MetricName, rollup.Date).Scan(&rollup.FinalValue, &rollup.MinValue, &rollup.MaxValue, &rollup.UpdateCount)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to read daily rollup: %w", err)
+		}
+		revertRollup(&rollup, e)
+		if err := saveRollup(tx, rollup); err != nil {
+			return err
+		}
+		value = rollup.FinalValue
+	} else {
+		updateQuery := `UPDATE metrics SET value = value - ? WHERE metric_name = ? RETURNING value;`
+		if err := tx.QueryRow(updateQuery, e.Delta, e.MetricName).Scan(&value); err != nil {
+			return fmt.Errorf("failed to update metric: %w", err)
+		}
+	}
+
+	undo := DBEvent{MetricName: e.MetricName, Operation: OpUndo, Delta: -e.Delta, Value: value, OccurredAt: now, Reverts: id}
+	_, err = insertEvent(tx, undo)
+	return err
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unit is a unit of the registry. Amounts convert between the units of a
+// dimension through its canonical unit, which has a factor of 1.
+type Unit struct {
+	Name      string  // Name the unit was looked up by
+	Dimension string  // mass, volume, time, distance, energy or count
+	Factor    float64 // Size of the unit in the canonical unit of its dimension
+}
+
+// CanonicalUnits names the canonical unit of each dimension of the registry
+var CanonicalUnits = map[string]string{
+	"mass":     "g",
+	"volume":   "ml",
+	"time":     "s",
+	"distance": "m",
+	"energy":   "kcal",
+	"count":    "count",
+}
+
+// unitTable lists the units of the registry with their spellings. Names are
+// matched ignoring case. Calories are food calories, so calorie is a kcal.
+var unitTable = []struct {
+	dimension string
+	factor    float64
+	names     []string
+}{
+	{"mass", 1e-6, []string{"mcg", "µg", "microgram", "micrograms"}},
+	{"mass", 0.001, []string{"mg", "milligram", "milligrams"}},
+	{"mass", 1, []string{"g", "gram", "grams"}},
+	{"mass", 1000, []string{"kg", "kilogram", "kilograms", "kilo", "kilos"}},
+	{"mass", 28.349523125, []string{"oz", "ounce", "ounces"}},
+	{"mass", 453.59237, []string{"lb", "lbs", "pound", "pounds"}},
+
+	{"volume", 1, []string{"ml", "milliliter", "milliliters", "millilitre", "millilitres"}},
+	{"volume", 10, []string{"cl", "centiliter", "centiliters", "centilitre", "centilitres"}},
+	{"volume", 100, []string{"dl", "deciliter", "deciliters", "decilitre", "decilitres"}},
+	{"volume", 1000, []string{"l", "liter", "liters", "litre", "litres"}},
+	{"volume", 4.92892159375, []string{"tsp", "teaspoon", "teaspoons"}},
+	{"volume", 14.78676478125, []string{"tbsp", "tablespoon", "tablespoons"}},
+	{"volume", 29.5735295625, []string{"fl oz", "floz", "fluid ounce", "fluid ounces"}},
+	{"volume", 236.5882365, []string{"cup", "cups"}},
+	{"volume", 473.176473, []string{"pt", "pint", "pints"}},
+	{"volume", 3785.411784, []string{"gal", "gallon", "gallons"}},
+
+	{"time", 0.001, []string{"ms", "millisecond", "milliseconds"}},
+	{"time", 1, []string{"s", "sec", "secs", "second", "seconds"}},
+	{"time", 60, []string{"min", "mins", "minute", "minutes"}},
+	{"time", 3600, []string{"h", "hr", "hrs", "hour", "hours"}},
+	{"time", 86400, []string{"d", "day", "days"}},
+	{"time", 604800, []string{"wk", "week", "weeks"}},
+
+	{"distance", 0.001, []string{"mm", "millimeter", "millimeters", "millimetre", "millimetres"}},
+	{"distance", 0.01, []string{"cm", "centimeter", "centimeters", "centimetre", "centimetres"}},
+	{"distance", 1, []string{"m", "meter", "meters", "metre", "metres"}},
+	{"distance", 1000, []string{"km", "kilometer", "kilometers", "kilometre", "kilometres"}},
+	{"distance", 0.0254, []string{"in", "inch", "inches"}},
+	{"distance", 0.3048, []string{"ft", "foot", "feet"}},
+	{"distance", 0.9144, []string{"yd", "yard", "yards"}},
+	{"distance", 1609.344, []string{"mi", "mile", "miles"}},
+
+	{"energy", 1, []string{"kcal", "kilocalorie", "kilocalories", "calorie", "calories"}},
+	{"energy", 1 / 4.184, []string{"kj", "kilojoule", "kilojoules"}},
+	{"energy", 1 / 4184.0, []string{"j", "joule", "joules"}},
+	{"energy", 3600 / 4184.0, []string{"wh"}},
+	{"energy", 3600000 / 4184.0, []string{"kwh"}},
+
+	{"count", 1, []string{"count", "counts", "x", "times", "pcs", "piece", "pieces", "item", "items"}},
+	{"count", 2, []string{"pair", "pairs"}},
+	{"count", 12, []string{"dozen"}},
+}
+
+// units maps the lowercase spellings of unitTable to their units
+var units = func() map[string]Unit {
+	m := make(map[string]Unit)
+	for _, u := range unitTable {
+		for _, name := range u.names {
+			m[name] = Unit{Name: name, Dimension: u.dimension, Factor: u.factor}
+		}
+	}
+	return m
+}()
+
+// LookupUnit returns the unit of the registry spelled name, ignoring case and
+// surrounding space
+func LookupUnit(name string) (Unit, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	u, ok := units[name]
+	if ok {
+		u.Name = name
+	}
+	return u, ok
+}
+
+// ConvertUnit converts an amount given in unit from to unit to. Amounts in
+// the same unit, spelled alike, are returned as they are even if the unit is
+// not in the registry, so metrics with units of their own such as steps still
+// take entries in them.
+func ConvertUnit(amount float64, from, to string) (float64, error) {
+	if strings.EqualFold(strings.TrimSpace(from), strings.TrimSpace(to)) {
+		return amount, nil
+	}
+	source, ok := LookupUnit(from)
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", from)
+	}
+	target, ok := LookupUnit(to)
+	if !ok {
+		return 0, fmt.Errorf("cannot convert %s to %q, which is not a known unit", source.Name, to)
+	}
+	if source.Dimension != target.Dimension {
+		return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", source.Name, source.Dimension, target.Name, target.Dimension)
+	}
+	return amount * source.Factor / target.Factor, nil
+}
+
+// CanonicalValue converts the value of metric to the canonical unit of the
+// dimension of its unit. It reports false for metrics whose unit is not in the
+// registry.
+func CanonicalValue(metric DBMetric) (value float64, dimension string, ok bool) {
+	u, ok := LookupUnit(metric.Unit)
+	if !ok {
+		return 0, "", false
+	}
+	return metric.Value * u.Factor, u.Dimension, true
+}
+
+// convertEntry converts the amount of an entry given in another unit to the
+// unit of the metric
+func (m DBMetric) convertEntry(entry *Entry) error {
+	if entry.Unit == "" {
+		return nil
+	}
+	amount, err := ConvertUnit(entry.Amount, entry.Unit, m.Unit)
+	if err != nil {
+		return fmt.Errorf("metric %s: %w", m.MetricName, err)
+	}
+	entry.Amount, entry.Unit = amount, ""
+	return nil
+}
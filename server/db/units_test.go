@@ -0,0 +1,111 @@
+package db
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertUnit(t *testing.T) {
+	for _, tt := range []struct {
+		amount   float64
+		from, to string
+		want     float64
+	}{
+		{250, "ml", "l", 0.25},
+		{2, "cups", "ml", 473.176473},
+		{90, "min", "h", 1.5},
+		{1, "Mile", "km", 1.609344},
+		{1, "kg", "lb", 2.2046226218},
+		{100, "kJ", "kcal", 23.9005736138},
+		{3, "dozen", "count", 36},
+		{1200, "steps", "steps", 1200},
+		{5, " G ", "g", 5},
+	} {
+		got, err := ConvertUnit(tt.amount, tt.from, tt.to)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ConvertUnit(%g, %q, %q) = %g, %v, want %g", tt.amount, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ from, to, want string }{
+		{"ml", "g", "cannot convert ml (volume) to g (mass)"},
+		{"furlongs", "m", `unknown unit "furlongs"`},
+		{"cups", "steps", `cannot convert cups to "steps", which is not a known unit`},
+	} {
+		if _, err := ConvertUnit(1, tt.from, tt.to); err == nil || err.Error() != tt.want {
+			t.Errorf("ConvertUnit(1, %q, %q) = %v, want %q", tt.from, tt.to, err, tt.want)
+		}
+	}
+}
+
+func TestEntryUnits(t *testing.T) {
+	sqlite, err := NewDatabase(filepath.Join(t.TempDir(), "kettle.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer sqlite.Close()
+
+	for _, store := range []Store{sqlite, NewMemoryStore()} {
+		value := func(name string) float64 {
+			t.Helper()
+			m, err := store.GetMetric(name)
+			if err != nil {
+				t.Fatalf("%T: GetMetric(%s) failed: %v", store, name, err)
+			}
+			return math.Round(m.Value*1e6) / 1e6
+		}
+		must := func(step string, err error) {
+			t.Helper()
+			if err != nil {
+				t.Fatalf("%T: %s failed: %v", store, step, err)
+			}
+		}
+
+		must("add water", store.AddMetric(DBMetric{MetricName: "water", Type: "Food", Unit: "l", Kind: KindCounter}))
+		must("add tea", store.AddMetric(DBMetric{MetricName: "tea_ml", Type: "Food", Unit: "ml", Kind: KindCounter}))
+		must("add steeping", store.AddMetric(DBMetric{MetricName: "steeping", Type: "Time", Unit: "min", Kind: KindGauge}))
+		must("link water", store.SetLink(Link{Source: "tea_ml", Target: "water", Multiplier: 0.001}))
+
+		events, err := store.ApplyEntries([]Entry{
+			{MetricName: "water", Operation: OpIncrement, Amount: 250, Unit: "ml"},
+			{MetricName: "tea_ml", Operation: OpIncrement, Amount: 2, Unit: "cups"},
+			{MetricName: "steeping", Operation: OpUpdate, Amount: 180, Unit: "s"},
+		})
+		must("apply entries", err)
+		// Links fan out the converted amount
+		for name, want := range map[string]float64{"water": 0.723176, "tea_ml": 473.176473, "steeping": 3} {
+			if got := value(name); got != want {
+				t.Errorf("%T: %s = %g after entries in other units, want %g", store, name, got, want)
+			}
+		}
+		if v, dimension, ok := CanonicalValue(DBMetric{Unit: "l", Value: 0.5}); !ok || v != 500 || dimension != "volume" {
+			t.Errorf("CanonicalValue of 0.5 l = %g %s, %t, want 500 volume", v, dimension, ok)
+		}
+
+		// Incompatible units reject the whole batch
+		_, err = store.ApplyEntries([]Entry{
+			{MetricName: "water", Operation: OpIncrement, Amount: 1, Unit: "l"},
+			{MetricName: "steeping", Operation: OpIncrement, Amount: 1, Unit: "kg"},
+		})
+		if err == nil || !strings.Contains(err.Error(), "metric steeping: cannot convert kg (mass) to min (time)") {
+			t.Errorf("%T: entry in kg for steeping = %v, want an incompatible unit error", store, err)
+		}
+		if got := value("water"); got != 0.723176 {
+			t.Errorf("%T: water = %g after a rejected batch, want 0.723176", store, got)
+		}
+
+		// Reverting undoes the converted amounts
+		var ids []int64
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		must("revert", store.RevertEntries(ids))
+		for _, name := range []string{"water", "tea_ml", "steeping"} {
+			if got := value(name); got != 0 {
+				t.Errorf("%T: %s = %g after the revert, want 0", store, name, got)
+			}
+		}
+	}
+}
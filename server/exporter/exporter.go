@@ -1,8 +1,11 @@
 package exporter
 
 import (
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -11,24 +14,99 @@ import (
 )
 
 type Exporter struct {
-	DB      *db.Database
-	Metrics *prometheus.GaugeVec
+	DB          db.Store
+	Metrics     *prometheus.GaugeVec
+	GoalTargets *prometheus.GaugeVec // Target of each goal, for target lines in Grafana
+	GoalRatios  *prometheus.GaugeVec // Value of each metric with a goal divided by its target
+	Streaks     *prometheus.GaugeVec // Current streak of each daily metric with a goal
+	BestStreaks *prometheus.GaugeVec // Longest streak of each daily metric with a goal
+	Canonical   *prometheus.GaugeVec // Value of each metric with a known unit in the canonical unit of its dimension
+	TagLabels   []string             // Tag keys exported as labels of Metrics
 }
 
-func NewExporter(database *db.Database) *Exporter {
+// ParseTagLabels parses a comma separated allowlist of tag keys to export as
+// labels. Every allowed key adds a label to all metrics, so the list is kept
+// short to bound the number of series.
+func ParseTagLabels(s string) ([]string, error) {
+	var keys []string
+	for _, key := range strings.Split(s, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || slices.Contains(keys, key) {
+			continue
+		}
+		if err := db.CheckTagKey(key); err != nil {
+			return nil, fmt.Errorf("invalid tag label: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// NewExporter registers the gauges of database with Prometheus. The tags of
+// a metric whose keys are in tagLabels become labels of dynamic_metrics;
+// metrics without such a tag get an empty label, which Prometheus drops.
+func NewExporter(database db.Store, tagLabels []string) *Exporter {
 	metrics := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "dynamic_metrics",
 			Help: "Dynamically added metrics",
 		},
-		[]string{"metric_name", "type", "unit", "reset_daily"},
+		append([]string{"metric_name", "type", "unit", "kind", "reset_daily", "reset_policy"}, tagLabels...),
+	)
+
+	goalTargets := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quanti_tea_goal_target",
+			Help: "Target of the goal of a metric for each period",
+		},
+		[]string{"metric_name", "direction"},
+	)
+
+	goalRatios := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quanti_tea_goal_progress_ratio",
+			Help: "Value of a metric in the current period divided by its goal target",
+		},
+		[]string{"metric_name", "direction"},
+	)
+
+	streaks := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quanti_tea_streak_current_days",
+			Help: "Consecutive days up to today a daily metric met its goal",
+		},
+		[]string{"metric_name"},
+	)
+
+	bestStreaks := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quanti_tea_streak_longest_days",
+			Help: "Most consecutive days a daily metric met its goal",
+		},
+		[]string{"metric_name"},
+	)
+
+	// Metrics of the same dimension logged in different units, such as ml and
+	// cups, add up in this gauge
+	canonical := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "quanti_tea_canonical_value",
+			Help: "Value of a metric converted to the canonical unit of the dimension of its unit",
+		},
+		append([]string{"metric_name", "dimension", "unit"}, tagLabels...),
 	)
 
-	prometheus.MustRegister(metrics)
+	prometheus.MustRegister(metrics, goalTargets, goalRatios, streaks, bestStreaks, canonical)
 
 	return &Exporter{
-		DB:      database,
-		Metrics: metrics,
+		DB:          database,
+		Metrics:     metrics,
+		GoalTargets: goalTargets,
+		GoalRatios:  goalRatios,
+		Streaks:     streaks,
+		BestStreaks: bestStreaks,
+		Canonical:   canonical,
+		TagLabels:   tagLabels,
 	}
 }
 
@@ -41,14 +119,58 @@ func (e *Exporter) UpdateMetrics() {
 
 	// Reset existing metrics to avoid stale data
 	e.Metrics.Reset()
+	e.GoalTargets.Reset()
+	e.GoalRatios.Reset()
+	e.Streaks.Reset()
+	e.BestStreaks.Reset()
+	e.Canonical.Reset()
+
+	now := time.Now()
 
 	for _, m := range metrics {
-		e.Metrics.With(prometheus.Labels{
-			"metric_name": m.MetricName,
-			"type":        m.Type,
-			"unit":        m.Unit,
-			"reset_daily": boolToString(m.ResetDaily),
-		}).Set(float64(m.Value))
+		var streak db.Streak
+		if m.HasStreak() {
+			if streak, err = db.MetricStreak(e.DB, m, now); err != nil {
+				log.Printf("Error computing streak: %v", err)
+			}
+		}
+
+		// Renamed metrics are also exported under their aliases so existing
+		// dashboards keep receiving data
+		for _, name := range append([]string{m.MetricName}, m.Aliases...) {
+			labels := prometheus.Labels{
+				"metric_name":  name,
+				"type":         m.Type,
+				"unit":         m.Unit,
+				"kind":         string(m.Kind),
+				"reset_daily":  boolToString(m.Reset.Kind == db.ResetDaily),
+				"reset_policy": m.Reset.String(),
+			}
+			for _, key := range e.TagLabels {
+				labels[key] = m.Tags[key]
+			}
+			e.Metrics.With(labels).Set(float64(m.Value))
+
+			if value, dimension, ok := db.CanonicalValue(m); ok {
+				canonicalLabels := prometheus.Labels{"metric_name": name, "dimension": dimension, "unit": db.CanonicalUnits[dimension]}
+				for _, key := range e.TagLabels {
+					canonicalLabels[key] = m.Tags[key]
+				}
+				e.Canonical.With(canonicalLabels).Set(value)
+			}
+
+			if !m.Goal.Set() {
+				continue
+			}
+			goalLabels := prometheus.Labels{"metric_name": name, "direction": string(m.Goal.Direction)}
+			e.GoalTargets.With(goalLabels).Set(m.Goal.Target)
+			e.GoalRatios.With(goalLabels).Set(m.GoalProgress(now).Ratio)
+
+			if m.HasStreak() {
+				e.Streaks.WithLabelValues(name).Set(float64(streak.Current))
+				e.BestStreaks.WithLabelValues(name).Set(float64(streak.Longest))
+			}
+		}
 	}
 }
 
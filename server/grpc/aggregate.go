@@ -0,0 +1,59 @@
+package grpcSrv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qjs/quanti-tea/server/db"
+
+	pb "github.com/qjs/quanti-tea/server/proto"
+)
+
+// QueryAggregate summarizes the stored history of the selected metrics
+func (s *MetricsServer) QueryAggregate(ctx context.Context, req *pb.QueryAggregateRequest) (*pb.QueryAggregateResponse, error) {
+	tags, err := db.ParseTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	start, err := parseOptionalTime(req.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseOptionalTime(req.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+
+	groups, err := db.Aggregate(s.DB, db.AggregateQuery{
+		MetricNames: req.MetricNames,
+		Type:        req.Type,
+		Tags:        tags,
+		Start:       start,
+		End:         end,
+		Field:       req.Field,
+		GroupBy:     req.GroupBy,
+		Bucket:      req.Bucket,
+		Percentiles: req.Percentiles,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pb.QueryAggregateResponse
+	for _, g := range groups {
+		resp.Groups = append(resp.Groups, &pb.AggregateGroup{
+			MetricName:  g.MetricName,
+			Type:        g.Type,
+			Tags:        g.Tags.String(),
+			Bucket:      g.Bucket,
+			Count:       int64(g.Count),
+			Sum:         g.Sum,
+			Avg:         g.Avg,
+			Min:         g.Min,
+			Max:         g.Max,
+			Percentiles: g.Percentiles,
+		})
+	}
+
+	return &resp, nil
+}
@@ -4,6 +4,7 @@ package grpcSrv
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/qjs/quanti-tea/server/db"
@@ -13,20 +14,80 @@ import (
 
 type MetricsServer struct {
 	pb.UnimplementedMetricsServiceServer
-	DB *db.Database
+	DB      db.Store
+	Backups *db.Backups // Nil when the server was started without a backup directory
+	journal journal     // Recent mutations for Undo and Redo
 }
 
-func NewMetricsServer(database *db.Database) *MetricsServer {
-	return &MetricsServer{DB: database}
+// NewMetricsServer serves database, picking up the undo journal it keeps
+func NewMetricsServer(database db.Store) (*MetricsServer, error) {
+	s := &MetricsServer{DB: database}
+	if err := s.journal.load(database); err != nil {
+		return nil, fmt.Errorf("failed to load the undo journal: %w", err)
+	}
+	return s, nil
 }
 
 func (s *MetricsServer) AddMetric(ctx context.Context, req *pb.AddMetricRequest) (*pb.AddMetricResponse, error) {
+	policy := db.ResetPolicy{Kind: db.ResetNone}
+	if req.ResetDaily {
+		policy.Kind = db.ResetDaily
+	}
+	if req.ResetPolicy != "" {
+		var err error
+		if policy, err = db.ParseResetPolicy(req.ResetPolicy); err != nil {
+			return &pb.AddMetricResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+	}
+
+	kind, err := db.ParseMetricKind(req.Kind)
+	if err != nil {
+		return &pb.AddMetricResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	goal, err := fromPBGoal(req.Goal)
+	if err != nil {
+		return &pb.AddMetricResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	restDays, err := db.ParseWeekdays(req.RestDays)
+	if err != nil {
+		return &pb.AddMetricResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	tags, err := db.ParseTags(req.Tags)
+	if err != nil {
+		return &pb.AddMetricResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
 	metric := db.DBMetric{
-		MetricName: req.MetricName,
-		Type:       req.Type,
-		Unit:       req.Unit,
-		ResetDaily: req.ResetDaily,
-		LastReset:  time.Now(),
+		MetricName:  req.MetricName,
+		Type:        req.Type,
+		Unit:        req.Unit,
+		Kind:        kind,
+		Reset:       policy,
+		DayStart:    req.DayStart,
+		Timezone:    req.Timezone,
+		Constraints: fromPBConstraints(req.Constraints),
+		Goal:        goal,
+		RestDays:    restDays,
+		Tags:        tags,
+		Formula:     req.Formula,
 	}
 
 	if err := s.DB.AddMetric(metric); err != nil {
@@ -35,6 +96,7 @@ func (s *MetricsServer) AddMetric(ctx context.Context, req *pb.AddMetricRequest)
 			Message: err.Error(),
 		}, nil
 	}
+	s.journal.record(&journalOp{db.JournalOp{ClientID: pb.ClientID(ctx), MetricName: metric.MetricName, Kind: db.OpAdd}})
 
 	return &pb.AddMetricResponse{
 		Success: true,
@@ -50,15 +112,110 @@ func (s *MetricsServer) DeleteMetric(ctx context.Context, req *pb.DeleteMetricRe
 			Message: err.Error(),
 		}, nil
 	}
+	s.journal.record(&journalOp{db.JournalOp{ClientID: pb.ClientID(ctx), MetricName: req.MetricName, Kind: db.OpDelete}})
 
 	return &pb.DeleteMetricResponse{
 		Success: true,
-		Message: "Metric deleted successfully.",
+		Message: "Metric moved to the trash.",
+	}, nil
+}
+
+func (s *MetricsServer) EditMetric(ctx context.Context, req *pb.EditMetricRequest) (*pb.EditMetricResponse, error) {
+	edit := db.MetricEdit{
+		NewName:   req.NewName,
+		Type:      req.Type,
+		Unit:      req.Unit,
+		KeepAlias: req.KeepAlias,
+		DayStart:  req.DayStart,
+		Timezone:  req.Timezone,
+		Formula:   req.Formula,
+	}
+	if req.Constraints != nil {
+		constraints := fromPBConstraints(req.Constraints)
+		edit.Constraints = &constraints
+	}
+	if req.ResetDaily != nil {
+		policy := db.ResetPolicy{Kind: db.ResetNone}
+		if *req.ResetDaily {
+			policy.Kind = db.ResetDaily
+		}
+		edit.Reset = &policy
+	}
+	if req.ResetPolicy != "" {
+		policy, err := db.ParseResetPolicy(req.ResetPolicy)
+		if err != nil {
+			return &pb.EditMetricResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+		edit.Reset = &policy
+	}
+	if req.Kind != "" {
+		kind, err := db.ParseMetricKind(req.Kind)
+		if err != nil {
+			return &pb.EditMetricResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+		edit.Kind = kind
+	}
+	if req.Goal != nil {
+		goal, err := fromPBGoal(req.Goal)
+		if err != nil {
+			return &pb.EditMetricResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+		edit.Goal = &goal
+	}
+	if req.RestDays != nil {
+		restDays, err := db.ParseWeekdays(*req.RestDays)
+		if err != nil {
+			return &pb.EditMetricResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+		edit.RestDays = &restDays
+	}
+	if req.Tags != nil {
+		tags, err := db.ParseTags(*req.Tags)
+		if err != nil {
+			return &pb.EditMetricResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+		edit.Tags = &tags
+	}
+
+	if err := s.DB.EditMetric(req.MetricName, edit); err != nil {
+		return &pb.EditMetricResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.EditMetricResponse{
+		Success: true,
+		Message: "Metric edited successfully.",
 	}, nil
 }
 
 func (s *MetricsServer) IncrementMetric(ctx context.Context, req *pb.IncrementMetricRequest) (*pb.IncrementMetricResponse, error) {
-	if err := s.DB.IncrementMetric(req.MetricName, req.Increment); err != nil {
+	occurredAt, err := parseOptionalTime(req.OccurredAt)
+	if err != nil {
+		return &pb.IncrementMetricResponse{
+			Success: false,
+			Message: fmt.Sprintf("invalid occurred_at: %v", err),
+		}, nil
+	}
+
+	entry := db.Entry{MetricName: req.MetricName, Operation: db.OpIncrement, Amount: req.Increment, Unit: req.Unit, OccurredAt: occurredAt, Note: req.Note}
+	if err := s.applyEntry(ctx, entry); err != nil {
 		return &pb.IncrementMetricResponse{
 			Success: false,
 			Message: err.Error(),
@@ -72,29 +229,124 @@ func (s *MetricsServer) IncrementMetric(ctx context.Context, req *pb.IncrementMe
 }
 
 func (s *MetricsServer) GetMetrics(ctx context.Context, req *pb.GetMetricsRequest) (*pb.GetMetricsResponse, error) {
+	filter, err := db.ParseTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	metrics, err := s.DB.GetMetrics()
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
 	var resp pb.GetMetricsResponse
 	for _, m := range metrics {
-		resp.Metrics = append(resp.Metrics, &pb.Metric{
-			MetricName: m.MetricName,
-			Type:       m.Type,
-			Unit:       m.Unit,
-			Value:      m.Value,
-			ResetDaily: m.ResetDaily,
-			LastReset:  m.LastReset.Format(time.RFC3339),
-		})
+		if !m.Tags.Matches(filter) {
+			continue
+		}
+		metric := toPBMetric(m)
+		if m.HasStreak() {
+			streak, err := db.MetricStreak(s.DB, m, now)
+			if err != nil {
+				return nil, err
+			}
+			metric.Streak = toPBStreak(streak)
+		}
+		resp.Metrics = append(resp.Metrics, metric)
 	}
 
 	return &resp, nil
 }
 
+// fromPBConstraints converts the constraints of a request; nil yields the
+// defaults, which only reject negative values
+func fromPBConstraints(c *pb.Constraints) db.Constraints {
+	if c == nil {
+		return db.Constraints{}
+	}
+	return db.Constraints{
+		Min:           c.Min,
+		Max:           c.Max,
+		Integer:       c.IntegerOnly,
+		Step:          c.Step,
+		AllowNegative: c.AllowNegative,
+	}
+}
+
+// fromPBGoal converts the goal of a request; nil yields no goal
+func fromPBGoal(g *pb.Goal) (db.Goal, error) {
+	if g == nil {
+		return db.Goal{}, nil
+	}
+	direction, err := db.ParseGoalDirection(g.Direction)
+	if err != nil {
+		return db.Goal{}, err
+	}
+	return db.Goal{Direction: direction, Target: g.Target}, nil
+}
+
+// toPBGoal converts a stored goal; no goal yields nil
+func toPBGoal(g db.Goal) *pb.Goal {
+	if !g.Set() {
+		return nil
+	}
+	return &pb.Goal{Direction: string(g.Direction), Target: g.Target}
+}
+
+// toPBStreak converts a computed streak
+func toPBStreak(s db.Streak) *pb.Streak {
+	return &pb.Streak{
+		MetricName: s.MetricName,
+		Current:    int32(s.Current),
+		Longest:    int32(s.Longest),
+		MetToday:   s.MetToday,
+	}
+}
+
+// toPBMetric converts a stored metric into its protobuf message
+func toPBMetric(m db.DBMetric) *pb.Metric {
+	metric := &pb.Metric{
+		MetricName:  m.MetricName,
+		Type:        m.Type,
+		Unit:        m.Unit,
+		Kind:        string(m.Kind),
+		Value:       m.Value,
+		ResetDaily:  m.Reset.Kind == db.ResetDaily,
+		LastReset:   m.LastReset.Format(time.RFC3339),
+		Aliases:     m.Aliases,
+		ResetPolicy: m.Reset.String(),
+		DayStart:    m.DayStart,
+		Timezone:    m.Timezone,
+		Constraints: &pb.Constraints{
+			Min:           m.Constraints.Min,
+			Max:           m.Constraints.Max,
+			IntegerOnly:   m.Constraints.Integer,
+			Step:          m.Constraints.Step,
+			AllowNegative: m.Constraints.AllowNegative,
+		},
+		Goal:     toPBGoal(m.Goal),
+		RestDays: m.RestDays.String(),
+		Tags:     m.Tags.String(),
+		Formula:  m.Formula,
+	}
+	if !m.DeletedAt.IsZero() {
+		metric.DeletedAt = m.DeletedAt.Format(time.RFC3339)
+	}
+	return metric
+}
+
 func (s *MetricsServer) UpdateMetric(ctx context.Context, req *pb.UpdateMetricRequest) (*pb.UpdateMetricResponse, error) {
-	err := s.DB.UpdateMetric(req.MetricName, req.NewValue)
+	occurredAt, err := parseOptionalTime(req.OccurredAt)
 	if err != nil {
+		return &pb.UpdateMetricResponse{
+			Success: false,
+			Message: fmt.Sprintf("invalid occurred_at: %v", err),
+		}, nil
+	}
+
+	entry := db.Entry{MetricName: req.MetricName, Operation: db.OpUpdate, Amount: req.NewValue, Unit: req.Unit, OccurredAt: occurredAt, Note: req.Note}
+	if err := s.applyEntry(ctx, entry); err != nil {
 		return &pb.UpdateMetricResponse{
 			Success: false,
 			Message: err.Error(),
@@ -108,8 +360,16 @@ func (s *MetricsServer) UpdateMetric(ctx context.Context, req *pb.UpdateMetricRe
 }
 
 func (s *MetricsServer) DecrementMetric(ctx context.Context, req *pb.DecrementMetricRequest) (*pb.DecrementMetricResponse, error) {
-	err := s.DB.DecrementMetric(req.MetricName, req.Decrement)
+	occurredAt, err := parseOptionalTime(req.OccurredAt)
 	if err != nil {
+		return &pb.DecrementMetricResponse{
+			Success: false,
+			Message: fmt.Sprintf("invalid occurred_at: %v", err),
+		}, nil
+	}
+
+	entry := db.Entry{MetricName: req.MetricName, Operation: db.OpDecrement, Amount: req.Decrement, Unit: req.Unit, OccurredAt: occurredAt, Note: req.Note}
+	if err := s.applyEntry(ctx, entry); err != nil {
 		return &pb.DecrementMetricResponse{
 			Success: false,
 			Message: err.Error(),
@@ -121,3 +381,260 @@ func (s *MetricsServer) DecrementMetric(ctx context.Context, req *pb.DecrementMe
 		Message: "Metric decremented successfully.",
 	}, nil
 }
+
+func (s *MetricsServer) GetMetricHistory(ctx context.Context, req *pb.GetMetricHistoryRequest) (*pb.GetMetricHistoryResponse, error) {
+	start, err := parseOptionalTime(req.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseOptionalTime(req.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+
+	events, err := s.DB.GetMetricHistory(req.MetricName, start, end, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pb.GetMetricHistoryResponse
+	for _, e := range events {
+		resp.Events = append(resp.Events, toPBEvent(e))
+	}
+
+	return &resp, nil
+}
+
+// SearchNotes returns the entries whose note contains the query, newest first
+func (s *MetricsServer) SearchNotes(ctx context.Context, req *pb.SearchNotesRequest) (*pb.SearchNotesResponse, error) {
+	start, err := parseOptionalTime(req.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseOptionalTime(req.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+
+	events, err := s.DB.SearchNotes(req.Query, req.MetricName, start, end, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pb.SearchNotesResponse
+	for _, e := range events {
+		resp.Events = append(resp.Events, toPBEvent(e))
+	}
+
+	return &resp, nil
+}
+
+// toPBEvent converts an event of the history to its protobuf form
+func toPBEvent(e db.DBEvent) *pb.MetricEvent {
+	return &pb.MetricEvent{
+		Id:           e.ID,
+		MetricName:   e.MetricName,
+		Operation:    e.Operation,
+		Delta:        e.Delta,
+		Value:        e.Value,
+		OccurredAt:   e.OccurredAt.Format(time.RFC3339),
+		Note:         e.Note,
+		Reverts:      e.Reverts,
+		Reverted:     e.Reverted,
+		Source:       e.Source,
+		SourceMetric: e.SourceName,
+	}
+}
+
+func (s *MetricsServer) GetDailyRollups(ctx context.Context, req *pb.GetDailyRollupsRequest) (*pb.GetDailyRollupsResponse, error) {
+	for _, date := range []string{req.StartDate, req.EndDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(db.DateFormat, date); err != nil {
+			return nil, fmt.Errorf("invalid date %q: %w", date, err)
+		}
+	}
+
+	rollups, err := s.DB.GetDailyRollups(req.MetricName, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pb.GetDailyRollupsResponse
+	for _, r := range rollups {
+		resp.Rollups = append(resp.Rollups, &pb.DailyRollup{
+			MetricName:  r.MetricName,
+			Date:        r.Date,
+			FinalValue:  r.FinalValue,
+			MinValue:    r.MinValue,
+			MaxValue:    r.MaxValue,
+			UpdateCount: int32(r.UpdateCount),
+		})
+	}
+
+	return &resp, nil
+}
+
+// GetGoalProgress reports how far the current period of a metric, or of every
+// metric with a goal, is toward its goal
+func (s *MetricsServer) GetGoalProgress(ctx context.Context, req *pb.GetGoalProgressRequest) (*pb.GetGoalProgressResponse, error) {
+	var metrics []db.DBMetric
+	if req.MetricName != "" {
+		metric, err := s.DB.GetMetric(req.MetricName)
+		if err != nil {
+			return nil, err
+		}
+		if !metric.Goal.Set() {
+			return nil, fmt.Errorf("metric %s has no goal", req.MetricName)
+		}
+		metrics = append(metrics, *metric)
+	} else {
+		all, err := s.DB.GetMetrics()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range all {
+			if m.Goal.Set() {
+				metrics = append(metrics, m)
+			}
+		}
+	}
+
+	now := time.Now()
+	var resp pb.GetGoalProgressResponse
+	for _, m := range metrics {
+		progress := m.GoalProgress(now)
+		resp.Progress = append(resp.Progress, &pb.GoalProgress{
+			MetricName:  progress.MetricName,
+			Goal:        toPBGoal(progress.Goal),
+			Value:       progress.Value,
+			Ratio:       progress.Ratio,
+			Met:         progress.Met,
+			PeriodStart: formatOptionalTime(progress.PeriodStart),
+			PeriodEnd:   formatOptionalTime(progress.PeriodEnd),
+		})
+	}
+
+	return &resp, nil
+}
+
+// GetStreaks reports the streaks of a metric, or of every daily metric with a goal
+func (s *MetricsServer) GetStreaks(ctx context.Context, req *pb.GetStreaksRequest) (*pb.GetStreaksResponse, error) {
+	var metrics []db.DBMetric
+	if req.MetricName != "" {
+		metric, err := s.DB.GetMetric(req.MetricName)
+		if err != nil {
+			return nil, err
+		}
+		if !metric.HasStreak() {
+			return nil, fmt.Errorf("metric %s has no streak, it needs a goal and a daily reset", req.MetricName)
+		}
+		metrics = append(metrics, *metric)
+	} else {
+		all, err := s.DB.GetMetrics()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range all {
+			if m.HasStreak() {
+				metrics = append(metrics, m)
+			}
+		}
+	}
+
+	now := time.Now()
+	var resp pb.GetStreaksResponse
+	for _, m := range metrics {
+		streak, err := db.MetricStreak(s.DB, m, now)
+		if err != nil {
+			return nil, err
+		}
+		resp.Streaks = append(resp.Streaks, toPBStreak(streak))
+	}
+
+	return &resp, nil
+}
+
+func (s *MetricsServer) ListDeletedMetrics(ctx context.Context, req *pb.ListDeletedMetricsRequest) (*pb.ListDeletedMetricsResponse, error) {
+	metrics, err := s.DB.ListDeletedMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pb.ListDeletedMetricsResponse
+	for _, m := range metrics {
+		resp.Metrics = append(resp.Metrics, toPBMetric(m))
+	}
+
+	return &resp, nil
+}
+
+func (s *MetricsServer) RestoreMetric(ctx context.Context, req *pb.RestoreMetricRequest) (*pb.RestoreMetricResponse, error) {
+	if err := s.DB.RestoreMetric(req.MetricName); err != nil {
+		return &pb.RestoreMetricResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.RestoreMetricResponse{
+		Success: true,
+		Message: "Metric restored successfully.",
+	}, nil
+}
+
+func (s *MetricsServer) PurgeMetric(ctx context.Context, req *pb.PurgeMetricRequest) (*pb.PurgeMetricResponse, error) {
+	if err := s.DB.PurgeMetric(req.MetricName); err != nil {
+		return &pb.PurgeMetricResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.PurgeMetricResponse{
+		Success: true,
+		Message: "Metric purged permanently.",
+	}, nil
+}
+
+// CreateBackup writes a snapshot of the store into the backup directory
+func (s *MetricsServer) CreateBackup(ctx context.Context, req *pb.CreateBackupRequest) (*pb.CreateBackupResponse, error) {
+	if s.Backups == nil {
+		return &pb.CreateBackupResponse{
+			Success: false,
+			Message: "Backups are disabled, start the server with -backup-dir.",
+		}, nil
+	}
+
+	path, err := s.Backups.Create(s.DB, time.Now())
+	if err != nil {
+		return &pb.CreateBackupResponse{
+			Success: false,
+			Message: err.Error(),
+			Path:    path,
+		}, nil
+	}
+
+	return &pb.CreateBackupResponse{
+		Success: true,
+		Message: "Backup created.",
+		Path:    path,
+	}, nil
+}
+
+// parseOptionalTime parses an RFC3339 timestamp, treating an empty string as the zero time
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// formatOptionalTime formats t as RFC3339, turning the zero time into an empty string
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
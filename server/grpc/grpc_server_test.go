@@ -0,0 +1,1272 @@
+package grpcSrv
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qjs/quanti-tea/server/db"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/qjs/quanti-tea/server/proto"
+)
+
+// stores lists every Store implementation the server is tested against
+var stores = map[string]func(dir string) (db.Store, error){
+	"sqlite": func(dir string) (db.Store, error) { return db.NewDatabase(filepath.Join(dir, "kettle.db")) },
+	"bolt":   func(dir string) (db.Store, error) { return db.NewBoltStore(filepath.Join(dir, "kettle.bolt")) },
+	"memory": func(string) (db.Store, error) { return db.NewMemoryStore(), nil },
+}
+
+// forEachStore runs test once per Store implementation with a fresh, empty store
+func forEachStore(t *testing.T, test func(t *testing.T, s *MetricsServer)) {
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store, err := open(t.TempDir())
+			if err != nil {
+				t.Fatalf("failed to create %s store: %v", name, err)
+			}
+			t.Cleanup(func() { store.Close() })
+
+			s, err := NewMetricsServer(store)
+			if err != nil {
+				t.Fatalf("NewMetricsServer failed: %v", err)
+			}
+			test(t, s)
+		})
+	}
+}
+
+// result is satisfied by the response of every mutation RPC
+type result interface {
+	GetSuccess() bool
+	GetMessage() string
+}
+
+// succeeds returns a check, taking the results of a mutation RPC, that fails
+// the test unless the RPC succeeded
+func succeeds(t *testing.T) func(resp result, err error) {
+	return func(resp result, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("RPC returned an error: %v", err)
+		}
+		if !resp.GetSuccess() {
+			t.Fatalf("RPC failed: %s", resp.GetMessage())
+		}
+	}
+}
+
+// fails returns a check, taking the results of a mutation RPC, that fails the
+// test unless the RPC was rejected
+func fails(t *testing.T) func(resp result, err error) {
+	return func(resp result, err error) {
+		t.Helper()
+		if err == nil && resp.GetSuccess() {
+			t.Fatal("RPC succeeded, want it to be rejected")
+		}
+	}
+}
+
+// getMetric returns the live metric called name, or nil if there is none
+func getMetric(t *testing.T, s *MetricsServer, name string) *pb.Metric {
+	t.Helper()
+	resp, err := s.GetMetrics(context.Background(), &pb.GetMetricsRequest{})
+	if err != nil {
+		t.Fatalf("GetMetrics failed: %v", err)
+	}
+	for _, m := range resp.Metrics {
+		if m.MetricName == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// history returns the recorded events of a metric, newest first
+func history(t *testing.T, s *MetricsServer, name string) []*pb.MetricEvent {
+	t.Helper()
+	resp, err := s.GetMetricHistory(context.Background(), &pb.GetMetricHistoryRequest{MetricName: name})
+	if err != nil {
+		t.Fatalf("GetMetricHistory failed: %v", err)
+	}
+	return resp.Events
+}
+
+// rollups returns the archived daily values of a metric, newest day first
+func rollups(t *testing.T, s *MetricsServer, name string) []*pb.DailyRollup {
+	t.Helper()
+	resp, err := s.GetDailyRollups(context.Background(), &pb.GetDailyRollupsRequest{MetricName: name})
+	if err != nil {
+		t.Fatalf("GetDailyRollups failed: %v", err)
+	}
+	return resp.Rollups
+}
+
+// daysAgo returns noon of the day n days before today as an RFC3339 occurred_at
+func daysAgo(n int) string {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day()-n, 12, 0, 0, 0, time.Local).Format(time.RFC3339)
+}
+
+// resetYesterday performs the reset of a daily metric that the scheduler runs
+// at midnight, closing the previous day
+func resetYesterday(t *testing.T, s *MetricsServer, name string) {
+	t.Helper()
+	if err := s.DB.ResetMetric(name, time.Now().AddDate(0, 0, -1)); err != nil {
+		t.Fatalf("ResetMetric failed: %v", err)
+	}
+}
+
+// dateDaysAgo returns the day n days before today in db.DateFormat
+func dateDaysAgo(n int) string {
+	return time.Now().AddDate(0, 0, -n).Format(db.DateFormat)
+}
+
+func TestConcurrentIncrementsAndDecrements(t *testing.T) {
+	forEachStore(t, testConcurrentIncrementsAndDecrements)
+}
+
+func testConcurrentIncrementsAndDecrements(t *testing.T, s *MetricsServer) {
+	const (
+		workers    = 16
+		iterations = 10
+	)
+
+	ctx := context.Background()
+
+	addResp, err := s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups"})
+	if err != nil || !addResp.Success {
+		t.Fatalf("AddMetric failed: %v %v", err, addResp.GetMessage())
+	}
+
+	// Every worker adds 2 and then removes 1, so its own contribution never goes
+	// negative and every decrement must succeed regardless of interleaving.
+	var wg sync.WaitGroup
+	errs := make(chan string, workers*iterations*2)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				incResp, err := s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 2})
+				if err != nil || !incResp.Success {
+					errs <- "increment: " + incResp.GetMessage()
+				}
+				decResp, err := s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "coffee", Decrement: 1})
+				if err != nil || !decResp.Success {
+					errs <- "decrement: " + decResp.GetMessage()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for msg := range errs {
+		t.Errorf("concurrent entry failed: %s", msg)
+	}
+
+	resp, err := s.GetMetrics(ctx, &pb.GetMetricsRequest{})
+	if err != nil {
+		t.Fatalf("GetMetrics failed: %v", err)
+	}
+	if len(resp.Metrics) != 1 {
+		t.Fatalf("expected 1 metric, got %d", len(resp.Metrics))
+	}
+	if got, want := resp.Metrics[0].Value, float64(workers*iterations); got != want {
+		t.Errorf("final value = %v, want %v", got, want)
+	}
+
+	history, err := s.GetMetricHistory(ctx, &pb.GetMetricHistoryRequest{MetricName: "coffee"})
+	if err != nil {
+		t.Fatalf("GetMetricHistory failed: %v", err)
+	}
+	// One add event plus an increment and a decrement per iteration
+	if got, want := len(history.Events), 1+workers*iterations*2; got != want {
+		t.Errorf("history has %d events, want %d", got, want)
+	}
+}
+
+func TestDecrementBelowZeroIsRejected(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		if _, err := s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "water", Type: "Health", Unit: "glasses"}); err != nil {
+			t.Fatalf("AddMetric failed: %v", err)
+		}
+
+		resp, err := s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "water", Decrement: 1})
+		if err != nil {
+			t.Fatalf("DecrementMetric returned an error: %v", err)
+		}
+		if resp.Success {
+			t.Fatal("decrementing below zero succeeded")
+		}
+	})
+}
+
+func TestDeleteRestoreAndPurge(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		if _, err := s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "tea", Type: "Food", Unit: "cups"}); err != nil {
+			t.Fatalf("AddMetric failed: %v", err)
+		}
+		if _, err := s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "tea", Increment: 3}); err != nil {
+			t.Fatalf("IncrementMetric failed: %v", err)
+		}
+
+		if resp, _ := s.DeleteMetric(ctx, &pb.DeleteMetricRequest{MetricName: "tea"}); !resp.Success {
+			t.Fatalf("DeleteMetric failed: %s", resp.Message)
+		}
+		metrics, err := s.GetMetrics(ctx, &pb.GetMetricsRequest{})
+		if err != nil || len(metrics.Metrics) != 0 {
+			t.Fatalf("trashed metric is still listed: %v %v", metrics.GetMetrics(), err)
+		}
+		if resp, _ := s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "tea", Type: "Food", Unit: "cups"}); resp.Success {
+			t.Fatal("re-adding a trashed metric succeeded")
+		}
+
+		trash, err := s.ListDeletedMetrics(ctx, &pb.ListDeletedMetricsRequest{})
+		if err != nil || len(trash.Metrics) != 1 || trash.Metrics[0].DeletedAt == "" {
+			t.Fatalf("unexpected trash: %v %v", trash.GetMetrics(), err)
+		}
+
+		if resp, _ := s.RestoreMetric(ctx, &pb.RestoreMetricRequest{MetricName: "tea"}); !resp.Success {
+			t.Fatalf("RestoreMetric failed: %s", resp.Message)
+		}
+		metrics, err = s.GetMetrics(ctx, &pb.GetMetricsRequest{})
+		if err != nil || len(metrics.Metrics) != 1 || metrics.Metrics[0].Value != 3 {
+			t.Fatalf("restored metric lost its value: %v %v", metrics.GetMetrics(), err)
+		}
+
+		if resp, _ := s.PurgeMetric(ctx, &pb.PurgeMetricRequest{MetricName: "tea"}); resp.Success {
+			t.Fatal("purging a metric outside the trash succeeded")
+		}
+		s.DeleteMetric(ctx, &pb.DeleteMetricRequest{MetricName: "tea"})
+		if resp, _ := s.PurgeMetric(ctx, &pb.PurgeMetricRequest{MetricName: "tea"}); !resp.Success {
+			t.Fatalf("PurgeMetric failed: %s", resp.Message)
+		}
+		trash, err = s.ListDeletedMetrics(ctx, &pb.ListDeletedMetricsRequest{})
+		if err != nil || len(trash.Metrics) != 0 {
+			t.Fatalf("purged metric is still in the trash: %v %v", trash.GetMetrics(), err)
+		}
+		if resp, _ := s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "tea", Type: "Food", Unit: "cups"}); !resp.Success {
+			t.Fatalf("re-adding a purged metric failed: %s", resp.Message)
+		}
+	})
+}
+
+func TestPurgeDropsHistory(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups", ResetDaily: true}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 2}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 1, OccurredAt: daysAgo(1)}))
+		succeeds(t)(s.DeleteMetric(ctx, &pb.DeleteMetricRequest{MetricName: "coffee"}))
+		succeeds(t)(s.PurgeMetric(ctx, &pb.PurgeMetricRequest{MetricName: "coffee"}))
+
+		if events := history(t, s, "coffee"); len(events) != 0 {
+			t.Errorf("purged metric left %d events behind", len(events))
+		}
+		if days := rollups(t, s, "coffee"); len(days) != 0 {
+			t.Errorf("purged metric left %d rollups behind", len(days))
+		}
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups"}))
+		if events := history(t, s, "coffee"); len(events) != 1 || events[0].Operation != "add" {
+			t.Errorf("re-added metric inherited history: %v", events)
+		}
+	})
+}
+
+func TestEditMetricRenameCarriesData(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups", ResetDaily: true}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 2}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 1, OccurredAt: daysAgo(1)}))
+
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "coffee", NewName: "espresso", Type: "Drink"}))
+
+		if getMetric(t, s, "coffee") != nil {
+			t.Fatal("old name is still listed")
+		}
+		m := getMetric(t, s, "espresso")
+		if m == nil {
+			t.Fatal("renamed metric is missing")
+		}
+		if m.Value != 2 || m.Type != "Drink" || m.Unit != "cups" || !m.ResetDaily || m.ResetPolicy != "daily" || len(m.Aliases) != 0 {
+			t.Errorf("unexpected renamed metric: %v", m)
+		}
+
+		if events := history(t, s, "coffee"); len(events) != 0 {
+			t.Errorf("%d events are left under the old name", len(events))
+		}
+		// add, increment, backdated increment and edit
+		if events := history(t, s, "espresso"); len(events) != 4 || events[0].Operation != "edit" {
+			t.Errorf("unexpected history after rename: %v", events)
+		}
+
+		if days := rollups(t, s, "coffee"); len(days) != 0 {
+			t.Errorf("%d rollups are left under the old name", len(days))
+		}
+		if days := rollups(t, s, "espresso"); len(days) != 1 || days[0].FinalValue != 1 {
+			t.Errorf("unexpected rollups after rename: %v", days)
+		}
+
+		// A reset daily flag that is not sent is left alone, one that is sent is applied
+		off := false
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "espresso", ResetDaily: &off}))
+		if m := getMetric(t, s, "espresso"); m.ResetDaily || m.ResetPolicy != "none" || m.Type != "Drink" {
+			t.Errorf("unexpected metric after editing reset daily: %v", m)
+		}
+	})
+}
+
+func TestEditMetricKeepAlias(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups"}))
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "coffee", NewName: "espresso", KeepAlias: true}))
+
+		if m := getMetric(t, s, "espresso"); !slices.Equal(m.GetAliases(), []string{"coffee"}) {
+			t.Fatalf("aliases = %v, want [coffee]", m.GetAliases())
+		}
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups"}))
+
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "espresso", NewName: "ristretto", KeepAlias: true}))
+		if m := getMetric(t, s, "ristretto"); !slices.Equal(m.GetAliases(), []string{"coffee", "espresso"}) {
+			t.Fatalf("aliases = %v, want [coffee espresso]", m.GetAliases())
+		}
+
+		// Renaming back onto an alias drops that alias
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "ristretto", NewName: "coffee"}))
+		if m := getMetric(t, s, "coffee"); !slices.Equal(m.GetAliases(), []string{"espresso"}) {
+			t.Fatalf("aliases = %v, want [espresso]", m.GetAliases())
+		}
+
+		// An alias of one metric cannot become the name of another
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "tea", Type: "Food", Unit: "cups"}))
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "tea", NewName: "espresso"}))
+	})
+}
+
+func TestEditMetricRejectsTakenNames(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups"}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 2}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "tea", Type: "Food", Unit: "cups"}))
+
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "coffee", NewName: "tea"}))
+
+		succeeds(t)(s.DeleteMetric(ctx, &pb.DeleteMetricRequest{MetricName: "tea"}))
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "coffee", NewName: "tea"}))
+
+		// Trashed metrics cannot be edited either
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "tea", Type: "Drink"}))
+
+		if m := getMetric(t, s, "coffee"); m == nil || m.Value != 2 || len(history(t, s, "coffee")) != 2 {
+			t.Errorf("rejected renames changed the metric: %v", m)
+		}
+	})
+}
+
+func TestMetricTags(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "dishes", Type: "House", Unit: "loads", Tags: "room=kitchen, person=alex"}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "vacuum", Type: "House", Unit: "rooms", Tags: "person=sam"}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "water", Type: "Health", Unit: "glasses"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "plants", Type: "House", Unit: "pots", Tags: "unit=pots"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "plants", Type: "House", Unit: "pots", Tags: "room"}))
+
+		if m := getMetric(t, s, "dishes"); m.GetTags() != "person=alex,room=kitchen" {
+			t.Errorf("tags = %q, want person=alex,room=kitchen", m.GetTags())
+		}
+
+		filtered := func(filter string) []string {
+			t.Helper()
+			resp, err := s.GetMetrics(ctx, &pb.GetMetricsRequest{Tags: filter})
+			if err != nil {
+				t.Fatalf("GetMetrics(%q) failed: %v", filter, err)
+			}
+			var names []string
+			for _, m := range resp.Metrics {
+				names = append(names, m.MetricName)
+			}
+			return names
+		}
+		for filter, want := range map[string][]string{
+			"":                         {"dishes", "vacuum", "water"},
+			"person":                   {"dishes", "vacuum"},
+			"person=sam":               {"vacuum"},
+			"person=alex,room=kitchen": {"dishes"},
+			"room=garden":              nil,
+		} {
+			if got := filtered(filter); !slices.Equal(got, want) {
+				t.Errorf("GetMetrics(%q) = %v, want %v", filter, got, want)
+			}
+		}
+		if _, err := s.GetMetrics(ctx, &pb.GetMetricsRequest{Tags: "9=x"}); err == nil {
+			t.Error("GetMetrics accepted an invalid filter")
+		}
+
+		// Unset tags are kept, empty tags are removed
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "vacuum", Unit: "hours"}))
+		if m := getMetric(t, s, "vacuum"); m.GetTags() != "person=sam" {
+			t.Errorf("tags after an edit without tags = %q, want person=sam", m.GetTags())
+		}
+		none := ""
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "vacuum", Tags: &none}))
+		if m := getMetric(t, s, "vacuum"); m.GetTags() != "" {
+			t.Errorf("tags after removing them = %q", m.GetTags())
+		}
+	})
+}
+
+func TestEntryNotes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+		yesterday := time.Now().AddDate(0, 0, -1).Format(time.RFC3339)
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "headache", Type: "Health", Unit: "times", ResetDaily: true}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "mood", Type: "Mind", Unit: "stars"}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "headache", Increment: 1, Note: "  after skipping lunch "}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "headache", Increment: 1, OccurredAt: yesterday, Note: "Skipped LUNCH again"}))
+		succeeds(t)(s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "headache", Decrement: 1}))
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 2, Note: "headache all afternoon"}))
+		fails(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "headache", Increment: 1, Note: strings.Repeat("x", db.MaxNoteLength+1)}))
+
+		history, err := s.GetMetricHistory(ctx, &pb.GetMetricHistoryRequest{MetricName: "headache", Limit: 2})
+		if err != nil {
+			t.Fatalf("GetMetricHistory failed: %v", err)
+		}
+		if len(history.Events) != 2 || history.Events[0].Note != "" || history.Events[1].Note != "after skipping lunch" {
+			t.Errorf("history = %v, want the decrement without a note, then the trimmed note", history.Events)
+		}
+
+		search := func(req *pb.SearchNotesRequest) []string {
+			t.Helper()
+			resp, err := s.SearchNotes(ctx, req)
+			if err != nil {
+				t.Fatalf("SearchNotes(%v) failed: %v", req, err)
+			}
+			var notes []string
+			for _, e := range resp.Events {
+				notes = append(notes, e.Note)
+			}
+			return notes
+		}
+		for _, tt := range []struct {
+			req  *pb.SearchNotesRequest
+			want []string
+		}{
+			{&pb.SearchNotesRequest{}, []string{"headache all afternoon", "after skipping lunch", "Skipped LUNCH again"}},
+			{&pb.SearchNotesRequest{Query: "lunch"}, []string{"after skipping lunch", "Skipped LUNCH again"}},
+			{&pb.SearchNotesRequest{Query: "Lunch", Limit: 1}, []string{"after skipping lunch"}},
+			{&pb.SearchNotesRequest{Query: "headache"}, []string{"headache all afternoon"}},
+			{&pb.SearchNotesRequest{MetricName: "mood", Query: "lunch"}, nil},
+			{&pb.SearchNotesRequest{End: time.Now().Add(-time.Hour).Format(time.RFC3339)}, []string{"Skipped LUNCH again"}},
+		} {
+			if got := search(tt.req); !slices.Equal(got, tt.want) {
+				t.Errorf("SearchNotes(%v) = %q, want %q", tt.req, got, tt.want)
+			}
+		}
+		if _, err := s.SearchNotes(ctx, &pb.SearchNotesRequest{Start: "yesterday"}); err == nil {
+			t.Error("SearchNotes accepted an invalid start")
+		}
+	})
+}
+
+func TestDailyResetArchivesRollup(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups", ResetDaily: true}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "books", Type: "Brain", Unit: "count"}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 3}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "books", Increment: 1}))
+
+		resetYesterday(t, s, "coffee")
+		if err := s.DB.ResetMetric("books", time.Now().AddDate(0, 0, -1)); err == nil {
+			t.Error("a metric without a reset policy was reset")
+		}
+
+		if m := getMetric(t, s, "coffee"); m.Value != 0 {
+			t.Errorf("daily metric value after reset = %v, want 0", m.Value)
+		}
+		if m := getMetric(t, s, "books"); m.Value != 1 {
+			t.Errorf("persistent metric value after reset = %v, want 1", m.Value)
+		}
+
+		// The scheduler runs at midnight, so the value closes the previous day
+		want := &pb.DailyRollup{MetricName: "coffee", Date: dateDaysAgo(1), FinalValue: 3, MinValue: 3, MaxValue: 3}
+		if days := rollups(t, s, "coffee"); len(days) != 1 || !sameRollup(days[0], want) {
+			t.Errorf("rollups = %v, want [%v]", days, want)
+		}
+		if days := rollups(t, s, "books"); len(days) != 0 {
+			t.Errorf("persistent metric was archived: %v", days)
+		}
+		if events := history(t, s, "coffee"); events[0].Operation != "reset" || events[0].Delta != -3 {
+			t.Errorf("latest event = %v, want a reset of -3", events[0])
+		}
+	})
+}
+
+func TestDailyResetMergesBackdatedRollup(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups", ResetDaily: true}))
+
+		// Entries for yesterday logged before the reset of yesterday has run
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 3, OccurredAt: daysAgo(1)}))
+		succeeds(t)(s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "coffee", Decrement: 2, OccurredAt: daysAgo(1)}))
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "coffee", NewValue: 4, OccurredAt: daysAgo(1)}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 3}))
+
+		resetYesterday(t, s, "coffee")
+
+		want := &pb.DailyRollup{MetricName: "coffee", Date: dateDaysAgo(1), FinalValue: 7, MinValue: 0, MaxValue: 7, UpdateCount: 3}
+		if days := rollups(t, s, "coffee"); len(days) != 1 || !sameRollup(days[0], want) {
+			t.Errorf("rollups = %v, want [%v]", days, want)
+		}
+
+		// A second reset of the same day has nothing left to add
+		resetYesterday(t, s, "coffee")
+		if days := rollups(t, s, "coffee"); len(days) != 1 || !sameRollup(days[0], want) {
+			t.Errorf("rollups after a second reset = %v, want [%v]", days, want)
+		}
+	})
+}
+
+func TestResetPolicies(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "gym", Type: "Health", Unit: "sessions", ResetPolicy: "weekly:sunday"}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "water", Type: "Health", Unit: "glasses", ResetPolicy: "hourly"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "spending", Type: "House", Unit: "EUR", ResetPolicy: "fortnightly"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "spending", Type: "House", Unit: "EUR", ResetPolicy: "cron:every day"}))
+
+		if m := getMetric(t, s, "gym"); m.ResetPolicy != "weekly:sunday" || m.ResetDaily {
+			t.Errorf("unexpected weekly metric: %v", m)
+		}
+
+		// The policy sent with an edit takes precedence over the reset daily flag
+		on := true
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "gym", ResetDaily: &on, ResetPolicy: "cron:0 6 * * 1"}))
+		if m := getMetric(t, s, "gym"); m.ResetPolicy != "cron:0 6 * * 1" {
+			t.Errorf("reset policy after edit = %q, want cron:0 6 * * 1", m.ResetPolicy)
+		}
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "gym", ResetPolicy: "weekly:someday"}))
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "gym", ResetPolicy: "weekly:sunday"}))
+
+		// Backdated entries go to the rollup of the day their period started
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "gym", Increment: 1, OccurredAt: daysAgo(8)}))
+		eightDaysAgo := time.Now().AddDate(0, 0, -8)
+		sunday := eightDaysAgo.AddDate(0, 0, -int(eightDaysAgo.Weekday())).Format(db.DateFormat)
+		if days := rollups(t, s, "gym"); len(days) != 1 || days[0].Date != sunday || days[0].FinalValue != 1 {
+			t.Errorf("rollups = %v, want 1 on %s", days, sunday)
+		}
+
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 2, OccurredAt: daysAgo(1)}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 3, OccurredAt: daysAgo(1)}))
+		if days := rollups(t, s, "water"); len(days) != 1 || days[0].Date != dateDaysAgo(1) || days[0].FinalValue != 5 {
+			t.Errorf("hourly rollups = %v, want 5 on %s", days, dateDaysAgo(1))
+		}
+	})
+}
+
+func TestMetricDayBoundary(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "sleep", Type: "Health", Unit: "hours", ResetPolicy: "daily", DayStart: "04:00", Timezone: "Europe/Berlin"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "naps", Type: "Health", Unit: "count", DayStart: "25:00"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "naps", Type: "Health", Unit: "count", Timezone: "Europe/Atlantis"}))
+
+		if m := getMetric(t, s, "sleep"); m.DayStart != "04:00" || m.Timezone != "Europe/Berlin" {
+			t.Errorf("day boundary = %q %q, want 04:00 Europe/Berlin", m.DayStart, m.Timezone)
+		}
+
+		// Unset fields keep the current boundary, empty ones go back to the server's
+		empty := ""
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "sleep", Unit: "minutes"}))
+		if m := getMetric(t, s, "sleep"); m.DayStart != "04:00" || m.Timezone != "Europe/Berlin" {
+			t.Errorf("day boundary after edit = %q %q, want it unchanged", m.DayStart, m.Timezone)
+		}
+		bad := "noon"
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "sleep", DayStart: &bad}))
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "sleep", Timezone: &empty}))
+		if m := getMetric(t, s, "sleep"); m.DayStart != "04:00" || m.Timezone != "" {
+			t.Errorf("day boundary after clearing the zone = %q %q, want 04:00 and the server's zone", m.DayStart, m.Timezone)
+		}
+	})
+}
+
+func TestMetricConstraints(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+		one, five := 1.0, 5.0
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "mood", Type: "Health", Unit: "points", ResetDaily: true,
+			Constraints: &pb.Constraints{Min: &one, Max: &five, IntegerOnly: true}}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "water", Type: "Health", Unit: "l",
+			Constraints: &pb.Constraints{Step: 0.25}}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "broken", Type: "Health", Unit: "points",
+			Constraints: &pb.Constraints{Min: &five, Max: &one}}))
+
+		if m := getMetric(t, s, "mood"); m.Constraints.GetMax() != 5 || !m.Constraints.IntegerOnly || m.Constraints.AllowNegative {
+			t.Errorf("mood constraints = %v", m.Constraints)
+		}
+		if m := getMetric(t, s, "water"); m.Constraints.Min != nil || m.Constraints.Max != nil {
+			t.Errorf("water has bounds: %v", m.Constraints)
+		}
+
+		// Every kind of entry is checked, including backdated ones
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 4}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 6}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 3.5}))
+		fails(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "mood", Increment: 2}))
+		fails(t)(s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "mood", Decrement: 4}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 9, OccurredAt: daysAgo(1)}))
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 2, OccurredAt: daysAgo(1)}))
+		if m := getMetric(t, s, "mood"); m.Value != 4 {
+			t.Errorf("mood = %v after rejected entries, want 4", m.Value)
+		}
+
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 0.75}))
+		fails(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 0.1}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "water", NewValue: -0.25}))
+
+		// New constraints must fit the current value and replace the old ones
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "water", Constraints: &pb.Constraints{IntegerOnly: true}}))
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "water", Constraints: &pb.Constraints{AllowNegative: true}}))
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "water", NewValue: -0.1}))
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "water", Unit: "litres"}))
+		if m := getMetric(t, s, "water"); !m.Constraints.AllowNegative {
+			t.Error("an edit without constraints dropped them")
+		}
+	})
+}
+
+func TestMetricKinds(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "steps", Type: "Health", Unit: "steps", Kind: "counter", ResetDaily: true}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "weight", Type: "Health", Unit: "kg"}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "flossed", Type: "Health", Unit: "", Kind: "boolean"}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "mood", Type: "Health", Unit: "stars", Kind: "rating"}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "reading", Type: "Hobby", Unit: "min", Kind: "duration"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "broken", Type: "Health", Unit: "", Kind: "histogram"}))
+
+		if m := getMetric(t, s, "weight"); m.Kind != "gauge" {
+			t.Errorf("weight kind = %q, want the gauge default", m.Kind)
+		}
+
+		// Counters only go up until they are reset
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "steps", Increment: 500}))
+		fails(t)(s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "steps", Decrement: 1}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "steps", NewValue: 100}))
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "steps", NewValue: 800}))
+		resetYesterday(t, s, "steps")
+		if m := getMetric(t, s, "steps"); m.Value != 0 {
+			t.Errorf("steps = %v after the reset, want 0", m.Value)
+		}
+
+		// Booleans are 0 or 1
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "flossed", NewValue: 1}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "flossed", NewValue: 2}))
+		fails(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "flossed", Increment: 1}))
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "flossed", NewValue: 0}))
+
+		// Ratings are whole numbers from 1 to 5 unless set otherwise
+		m := getMetric(t, s, "mood")
+		if m.Constraints.GetMin() != 1 || m.Constraints.GetMax() != 5 || !m.Constraints.IntegerOnly {
+			t.Errorf("mood constraints = %v, want whole numbers from 1 to 5", m.Constraints)
+		}
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 4}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 4.5}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "mood", NewValue: 6}))
+
+		// Durations are stored in seconds
+		if m := getMetric(t, s, "reading"); m.Unit != "seconds" {
+			t.Errorf("reading unit = %q, want seconds", m.Unit)
+		}
+		amount, err := pb.ParseValue(pb.KindDuration, "1h30m")
+		if err != nil {
+			t.Fatalf("ParseValue failed: %v", err)
+		}
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "reading", Increment: amount}))
+		if m := getMetric(t, s, "reading"); m.Value != 5400 {
+			t.Errorf("reading = %v, want 5400 seconds", m.Value)
+		}
+
+		// A new kind must fit the current value, an edit without one keeps it
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "weight", NewValue: 72}))
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "weight", Kind: "boolean"}))
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "weight", Kind: "histogram"}))
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "weight", Kind: "counter"}))
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "weight", Unit: "kilograms"}))
+		if m := getMetric(t, s, "weight"); m.Kind != "counter" {
+			t.Errorf("weight kind = %q after the edits, want counter", m.Kind)
+		}
+	})
+}
+
+func TestGoalProgress(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "water", Type: "Health", Unit: "glasses", ResetPolicy: "daily",
+			Goal: &pb.Goal{Direction: "at_least", Target: 8}}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "alcohol", Type: "Health", Unit: "drinks", ResetPolicy: "weekly",
+			Goal: &pb.Goal{Direction: "at-most", Target: 2}}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "books", Type: "Hobby", Unit: "books"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "broken", Type: "Health", Unit: "", Goal: &pb.Goal{Direction: "about", Target: 1}}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "broken", Type: "Health", Unit: "", Goal: &pb.Goal{Direction: "at_least"}}))
+
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 6}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "alcohol", Increment: 3}))
+
+		resp, err := s.GetGoalProgress(ctx, &pb.GetGoalProgressRequest{})
+		if err != nil {
+			t.Fatalf("GetGoalProgress failed: %v", err)
+		}
+		progress := map[string]*pb.GoalProgress{}
+		for _, p := range resp.Progress {
+			progress[p.MetricName] = p
+		}
+		if len(progress) != 2 || progress["books"] != nil {
+			t.Fatalf("progress covers %v, want water and alcohol", progress)
+		}
+		if p := progress["water"]; p.Ratio != 0.75 || p.Met || p.Goal.Direction != "at_least" {
+			t.Errorf("water progress = %v, want 0.75 and not met", p)
+		}
+		if p := progress["alcohol"]; p.Ratio != 1.5 || p.Met || p.Goal.Direction != "at_most" {
+			t.Errorf("alcohol progress = %v, want 1.5 and broken", p)
+		}
+
+		// The period of the goal is the period of the metric's reset
+		start, err := time.Parse(time.RFC3339, progress["alcohol"].PeriodStart)
+		if err != nil {
+			t.Fatalf("invalid period start: %v", err)
+		}
+		end, err := time.Parse(time.RFC3339, progress["alcohol"].PeriodEnd)
+		if err != nil {
+			t.Fatalf("invalid period end: %v", err)
+		}
+		if start.Weekday() != time.Monday || end.Sub(start) < 6*24*time.Hour || !start.Before(time.Now()) || !end.After(time.Now()) {
+			t.Errorf("alcohol period = %v to %v, want the current week", start, end)
+		}
+
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 2}))
+		resp, err = s.GetGoalProgress(ctx, &pb.GetGoalProgressRequest{MetricName: "water"})
+		if err != nil {
+			t.Fatalf("GetGoalProgress failed: %v", err)
+		}
+		if len(resp.Progress) != 1 || !resp.Progress[0].Met {
+			t.Errorf("water progress = %v after 8 glasses, want met", resp.Progress)
+		}
+		if _, err := s.GetGoalProgress(ctx, &pb.GetGoalProgressRequest{MetricName: "books"}); err == nil {
+			t.Error("GetGoalProgress of a metric without goal succeeded")
+		}
+
+		// Edits keep the goal unless they replace or remove it
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "water", Unit: "cups"}))
+		if m := getMetric(t, s, "water"); m.Goal.GetTarget() != 8 {
+			t.Errorf("water goal = %v after an edit without goal", m.Goal)
+		}
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "books", Goal: &pb.Goal{Direction: "at_least", Target: 12}}))
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "water", Goal: &pb.Goal{}}))
+		if m := getMetric(t, s, "water"); m.Goal != nil {
+			t.Errorf("water goal = %v after removing it", m.Goal)
+		}
+		if p := getMetric(t, s, "books"); p.Goal.GetTarget() != 12 {
+			t.Errorf("books goal = %v", p.Goal)
+		}
+	})
+}
+
+func TestStreaks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "water", Type: "Health", Unit: "glasses", ResetPolicy: "daily",
+			Goal: &pb.Goal{Direction: "at_least", Target: 8}}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "books", Type: "Hobby", Unit: "books", ResetPolicy: "monthly",
+			Goal: &pb.Goal{Direction: "at_least", Target: 2}}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "broken", Type: "Health", Unit: "", RestDays: "someday"}))
+
+		// Met three days ago and the last two days, missed four days ago
+		for n, glasses := range map[int]float64{4: 3, 3: 8, 2: 9, 1: 8} {
+			succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "water", NewValue: glasses, OccurredAt: daysAgo(n)}))
+		}
+
+		resp, err := s.GetStreaks(ctx, &pb.GetStreaksRequest{})
+		if err != nil {
+			t.Fatalf("GetStreaks failed: %v", err)
+		}
+		if len(resp.Streaks) != 1 || resp.Streaks[0].MetricName != "water" {
+			t.Fatalf("streaks = %v, want only water", resp.Streaks)
+		}
+		if got := resp.Streaks[0]; got.Current != 3 || got.Longest != 3 || got.MetToday {
+			t.Errorf("water streak = %v, want 3 days not met today", got)
+		}
+
+		// Today counts once it is met
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 8}))
+		if m := getMetric(t, s, "water"); m.Streak.GetCurrent() != 4 || !m.Streak.GetMetToday() {
+			t.Errorf("water streak = %v after meeting today's goal, want 4 days", m.Streak)
+		}
+		if m := getMetric(t, s, "books"); m.Streak != nil {
+			t.Errorf("monthly metric has a streak: %v", m.Streak)
+		}
+		if _, err := s.GetStreaks(ctx, &pb.GetStreaksRequest{MetricName: "books"}); err == nil {
+			t.Error("GetStreaks of a monthly metric succeeded")
+		}
+
+		// A day without entries breaks the streak unless it is a rest day
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "water", NewValue: 0, OccurredAt: daysAgo(2)}))
+		if m := getMetric(t, s, "water"); m.Streak.GetCurrent() != 2 || m.Streak.GetLongest() != 2 {
+			t.Errorf("water streak = %v after missing a day, want 2 days", m.Streak)
+		}
+		restDay := strings.ToLower(time.Now().AddDate(0, 0, -2).Weekday().String())
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "water", RestDays: &restDay}))
+		if m := getMetric(t, s, "water"); m.RestDays != restDay || m.Streak.GetCurrent() != 3 {
+			t.Errorf("water streak = %v with rest days %q, want 3 days", m.Streak, m.RestDays)
+		}
+	})
+}
+
+func TestGetDailyRollupsFilters(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		for _, name := range []string{"coffee", "tea"} {
+			succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: name, Type: "Food", Unit: "cups", ResetDaily: true}))
+			for n := 1; n <= 3; n++ {
+				succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: name, Increment: float64(n), OccurredAt: daysAgo(n)}))
+			}
+		}
+
+		resp, err := s.GetDailyRollups(ctx, &pb.GetDailyRollupsRequest{MetricName: "coffee", StartDate: dateDaysAgo(2), EndDate: dateDaysAgo(1)})
+		if err != nil {
+			t.Fatalf("GetDailyRollups failed: %v", err)
+		}
+		if len(resp.Rollups) != 2 || resp.Rollups[0].Date != dateDaysAgo(1) || resp.Rollups[1].Date != dateDaysAgo(2) {
+			t.Errorf("filtered rollups = %v, want days 1 and 2 ago, newest first", resp.Rollups)
+		}
+
+		resp, err = s.GetDailyRollups(ctx, &pb.GetDailyRollupsRequest{StartDate: dateDaysAgo(3), EndDate: dateDaysAgo(3)})
+		if err != nil {
+			t.Fatalf("GetDailyRollups failed: %v", err)
+		}
+		if len(resp.Rollups) != 2 || resp.Rollups[0].MetricName != "coffee" || resp.Rollups[1].MetricName != "tea" || resp.Rollups[0].FinalValue != 3 {
+			t.Errorf("rollups of every metric = %v, want coffee and tea of 3 days ago", resp.Rollups)
+		}
+
+		if _, err := s.GetDailyRollups(ctx, &pb.GetDailyRollupsRequest{StartDate: "yesterday"}); err == nil {
+			t.Error("an invalid start date was accepted")
+		}
+	})
+}
+
+// sameRollup reports whether two rollups hold the same values
+func sameRollup(a, b *pb.DailyRollup) bool {
+	return a.MetricName == b.MetricName && a.Date == b.Date && a.FinalValue == b.FinalValue &&
+		a.MinValue == b.MinValue && a.MaxValue == b.MaxValue && a.UpdateCount == b.UpdateCount
+}
+
+func TestGetMetricHistoryFilters(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+		now := time.Now().Truncate(time.Second)
+		at := func(ago time.Duration) string { return now.Add(-ago).Format(time.RFC3339) }
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "books", Type: "Brain", Unit: "count"}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "pages", Type: "Brain", Unit: "count"}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "books", Increment: 1, OccurredAt: at(3 * time.Hour)}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "books", Increment: 2, OccurredAt: at(1 * time.Hour)}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "books", Increment: 4, OccurredAt: at(2 * time.Hour)}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "pages", Increment: 8, OccurredAt: at(90 * time.Minute)}))
+
+		deltas := func(events []*pb.MetricEvent) []float64 {
+			var d []float64
+			for _, e := range events {
+				d = append(d, e.Delta)
+			}
+			return d
+		}
+
+		// Newest first by occurred_at, not by the order the entries were logged
+		if got, want := deltas(history(t, s, "books")), []float64{0, 2, 4, 1}; !slices.Equal(got, want) {
+			t.Errorf("history deltas = %v, want %v", got, want)
+		}
+
+		// start is inclusive and end is exclusive
+		resp, err := s.GetMetricHistory(ctx, &pb.GetMetricHistoryRequest{MetricName: "books", Start: at(2 * time.Hour), End: at(1 * time.Hour)})
+		if err != nil {
+			t.Fatalf("GetMetricHistory failed: %v", err)
+		}
+		if got, want := deltas(resp.Events), []float64{4}; !slices.Equal(got, want) {
+			t.Errorf("bounded history deltas = %v, want %v", got, want)
+		}
+
+		// An empty name returns every metric, and limit keeps the newest events
+		resp, err = s.GetMetricHistory(ctx, &pb.GetMetricHistoryRequest{End: at(30 * time.Minute), Limit: 2})
+		if err != nil {
+			t.Fatalf("GetMetricHistory failed: %v", err)
+		}
+		if got, want := deltas(resp.Events), []float64{2, 8}; !slices.Equal(got, want) {
+			t.Errorf("limited history deltas = %v, want %v", got, want)
+		}
+		if resp.Events[1].MetricName != "pages" || resp.Events[1].Value != 8 || resp.Events[1].OccurredAt != at(90*time.Minute) {
+			t.Errorf("unexpected event %v", resp.Events[1])
+		}
+
+		if _, err := s.GetMetricHistory(ctx, &pb.GetMetricHistoryRequest{Start: "yesterday"}); err == nil {
+			t.Error("an invalid start was accepted")
+		}
+	})
+}
+
+func TestBackdatedEntries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups", ResetDaily: true}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "books", Type: "Brain", Unit: "count"}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 1}))
+
+		// A daily metric sends an entry for an earlier day to that day's rollup
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 2, OccurredAt: daysAgo(2)}))
+		if m := getMetric(t, s, "coffee"); m.Value != 1 {
+			t.Errorf("live value = %v after a backdated entry, want 1", m.Value)
+		}
+		want := &pb.DailyRollup{MetricName: "coffee", Date: dateDaysAgo(2), FinalValue: 2, MinValue: 0, MaxValue: 2, UpdateCount: 1}
+		if days := rollups(t, s, "coffee"); len(days) != 1 || !sameRollup(days[0], want) {
+			t.Errorf("rollups = %v, want [%v]", days, want)
+		}
+		if events := history(t, s, "coffee"); len(events) != 3 || events[2].OccurredAt != daysAgo(2) || events[2].Value != 2 {
+			t.Errorf("backdated entry is not in the history at its time: %v", events)
+		}
+
+		// A day's archived value cannot go negative either
+		fails(t)(s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "coffee", Decrement: 3, OccurredAt: daysAgo(2)}))
+		fails(t)(s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "coffee", Decrement: 1, OccurredAt: daysAgo(3)}))
+		if days := rollups(t, s, "coffee"); len(days) != 1 || days[0].FinalValue != 2 {
+			t.Errorf("rejected decrements changed the rollups: %v", days)
+		}
+
+		// A metric without a daily reset has a single running value
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "books", Increment: 2, OccurredAt: daysAgo(2)}))
+		if m := getMetric(t, s, "books"); m.Value != 2 {
+			t.Errorf("persistent metric value = %v after a backdated entry, want 2", m.Value)
+		}
+		if days := rollups(t, s, "books"); len(days) != 0 {
+			t.Errorf("persistent metric got rollups: %v", days)
+		}
+		if events := history(t, s, "books"); events[len(events)-1].OccurredAt != daysAgo(2) {
+			t.Errorf("backdated entry is not the oldest event: %v", events)
+		}
+
+		// Entries cannot be logged ahead of time
+		future := time.Now().Add(time.Hour).Format(time.RFC3339)
+		fails(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "books", Increment: 1, OccurredAt: future}))
+		fails(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "books", Increment: 1, OccurredAt: "yesterday"}))
+		if m := getMetric(t, s, "books"); m.Value != 2 {
+			t.Errorf("rejected entries changed the value to %v", m.Value)
+		}
+	})
+}
+
+func TestPurgeDeletedMetricsSkipsRestored(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+
+		for _, name := range []string{"coffee", "tea", "water"} {
+			succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: name, Type: "Food", Unit: "cups"}))
+			succeeds(t)(s.DeleteMetric(ctx, &pb.DeleteMetricRequest{MetricName: name}))
+		}
+		succeeds(t)(s.RestoreMetric(ctx, &pb.RestoreMetricRequest{MetricName: "tea"}))
+
+		purged, err := s.DB.PurgeDeletedMetrics(time.Now().Add(time.Second))
+		if err != nil {
+			t.Fatalf("PurgeDeletedMetrics failed: %v", err)
+		}
+		if !slices.Equal(purged, []string{"coffee", "water"}) {
+			t.Errorf("purged %v, want [coffee water]", purged)
+		}
+		if getMetric(t, s, "tea") == nil {
+			t.Error("restored metric was purged")
+		}
+
+		trash, err := s.ListDeletedMetrics(ctx, &pb.ListDeletedMetricsRequest{})
+		if err != nil || len(trash.Metrics) != 0 {
+			t.Errorf("trash after purging = %v (%v), want it empty", trash.GetMetrics(), err)
+		}
+	})
+}
+
+func TestUndoRedo(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		tui := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pb.ClientIDKey, "tui"))
+		web := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pb.ClientIDKey, "web"))
+		value := func(name string) float64 {
+			t.Helper()
+			m := getMetric(t, s, name)
+			if m == nil {
+				t.Fatalf("metric %s does not exist", name)
+			}
+			return m.Value
+		}
+
+		// A typo is taken back without losing the entries made since
+		succeeds(t)(s.AddMetric(tui, &pb.AddMetricRequest{MetricName: "coffee", Type: "Food", Unit: "cups", ResetDaily: true}))
+		succeeds(t)(s.IncrementMetric(tui, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 100}))
+		succeeds(t)(s.IncrementMetric(web, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 1}))
+		succeeds(t)(s.Undo(tui, &pb.UndoRequest{ClientId: "tui"}))
+		if v := value("coffee"); v != 1 {
+			t.Errorf("value after undoing the typo = %v, want 1", v)
+		}
+		if events := history(t, s, "coffee"); len(events) != 4 || events[0].Operation != db.OpUndo || events[0].Delta != -100 ||
+			events[0].Reverts != events[2].Id || !events[2].Reverted || events[1].Reverted {
+			t.Errorf("history after undo = %v, want the add, the typo marked as reverted, the entry of web and an undo of the typo", events)
+		}
+		succeeds(t)(s.Redo(tui, &pb.RedoRequest{ClientId: "tui"}))
+		if v := value("coffee"); v != 101 {
+			t.Errorf("value after redo = %v, want 101", v)
+		}
+		succeeds(t)(s.Undo(tui, &pb.UndoRequest{ClientId: "tui"}))
+
+		// A backdated entry is taken out of the rollup of its day
+		succeeds(t)(s.IncrementMetric(tui, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 2, OccurredAt: daysAgo(2)}))
+		succeeds(t)(s.Undo(tui, &pb.UndoRequest{ClientId: "tui"}))
+		if days := rollups(t, s, "coffee"); len(days) != 1 || days[0].FinalValue != 0 {
+			t.Errorf("rollups after undoing a backdated entry = %v, want a final value of 0", days)
+		}
+		if v := value("coffee"); v != 1 {
+			t.Errorf("undoing a backdated entry changed the live value to %v", v)
+		}
+
+		// Undo can be scoped to a metric, and moves an added metric to the trash
+		succeeds(t)(s.AddMetric(tui, &pb.AddMetricRequest{MetricName: "tea", Type: "Food", Unit: "cups"}))
+		succeeds(t)(s.IncrementMetric(tui, &pb.IncrementMetricRequest{MetricName: "tea", Increment: 3}))
+		succeeds(t)(s.UpdateMetric(tui, &pb.UpdateMetricRequest{MetricName: "coffee", NewValue: 5}))
+		succeeds(t)(s.Undo(tui, &pb.UndoRequest{ClientId: "tui", MetricName: "tea"}))
+		if tea, coffee := value("tea"), value("coffee"); tea != 0 || coffee != 5 {
+			t.Errorf("after undoing tea, tea = %v and coffee = %v, want 0 and 5", tea, coffee)
+		}
+		succeeds(t)(s.Undo(tui, &pb.UndoRequest{ClientId: "tui", MetricName: "tea"}))
+		fails(t)(s.Undo(tui, &pb.UndoRequest{ClientId: "tui", MetricName: "tea"}))
+		if getMetric(t, s, "tea") != nil {
+			t.Error("undoing the add of tea left it out of the trash")
+		}
+		succeeds(t)(s.Redo(tui, &pb.RedoRequest{ClientId: "tui", MetricName: "tea"}))
+		if len(history(t, s, "tea")) != 5 {
+			t.Errorf("history of tea = %v, want its add and entry kept through the trash", history(t, s, "tea"))
+		}
+		succeeds(t)(s.Undo(tui, &pb.UndoRequest{}))
+		if v := value("coffee"); v != 1 {
+			t.Errorf("value after undoing the update = %v, want 1", v)
+		}
+
+		// Deleting and adding are undone too
+		succeeds(t)(s.DeleteMetric(web, &pb.DeleteMetricRequest{MetricName: "coffee"}))
+		succeeds(t)(s.Undo(web, &pb.UndoRequest{ClientId: "web"}))
+		if v := value("coffee"); v != 1 {
+			t.Errorf("restored value = %v, want 1", v)
+		}
+		succeeds(t)(s.Redo(web, &pb.RedoRequest{ClientId: "web"}))
+		if getMetric(t, s, "coffee") != nil {
+			t.Error("redo did not delete the metric again")
+		}
+		succeeds(t)(s.Undo(web, &pb.UndoRequest{ClientId: "web"}))
+
+		succeeds(t)(s.AddMetric(web, &pb.AddMetricRequest{MetricName: "water", Type: "Health", Unit: "glasses", Tags: "room=kitchen"}))
+		succeeds(t)(s.Undo(web, &pb.UndoRequest{ClientId: "web"}))
+		trash, err := s.ListDeletedMetrics(web, &pb.ListDeletedMetricsRequest{})
+		if getMetric(t, s, "water") != nil || err != nil || len(trash.Metrics) != 1 {
+			t.Errorf("undoing an add did not move the metric to the trash, trash = %v (%v)", trash.GetMetrics(), err)
+		}
+		succeeds(t)(s.Redo(web, &pb.RedoRequest{ClientId: "web"}))
+		if m := getMetric(t, s, "water"); m == nil || m.Tags != "room=kitchen" {
+			t.Errorf("redo added %v, want water with its tags", m)
+		}
+
+		// A new change drops what the same client could redo
+		succeeds(t)(s.Undo(web, &pb.UndoRequest{ClientId: "web"}))
+		succeeds(t)(s.IncrementMetric(web, &pb.IncrementMetricRequest{MetricName: "coffee", Increment: 1}))
+		fails(t)(s.Redo(web, &pb.RedoRequest{ClientId: "web"}))
+		fails(t)(s.Undo(web, &pb.UndoRequest{ClientId: "phone"}))
+
+		// The journal is kept by the store and survives a restart
+		restarted, err := NewMetricsServer(s.DB)
+		if err != nil {
+			t.Fatalf("NewMetricsServer failed: %v", err)
+		}
+		succeeds(t)(restarted.Undo(web, &pb.UndoRequest{ClientId: "web"}))
+		if v := value("coffee"); v != 1 {
+			t.Errorf("value after undoing across a restart = %v, want 1", v)
+		}
+		succeeds(t)(restarted.Redo(web, &pb.RedoRequest{ClientId: "web"}))
+		succeeds(t)(restarted.Undo(web, &pb.UndoRequest{ClientId: "web"}))
+	})
+}
+
+func TestDerivedMetrics(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := context.Background()
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "espresso", Type: "Food", Unit: "cups", Kind: pb.KindCounter}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "drip_coffee", Type: "Food", Unit: "cups", Kind: pb.KindCounter}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "caffeine_mg", Type: "Food", Unit: "mg", Kind: pb.KindDerived,
+			Formula: "espresso*63 + drip_coffee*95"}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "espresso", Increment: 2}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "drip_coffee", Increment: 1}))
+
+		m := getMetric(t, s, "caffeine_mg")
+		if m.Value != 221 || m.Kind != pb.KindDerived || m.Formula != "espresso*63 + drip_coffee*95" {
+			t.Errorf("caffeine_mg = %g %s %q, want 221 derived from its formula", m.Value, m.Kind, m.Formula)
+		}
+
+		// Derived metrics are read-only and their formulas are checked
+		fails(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "caffeine_mg", Increment: 1}))
+		fails(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "caffeine_mg", NewValue: 0}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "tea_mg", Kind: pb.KindDerived, Formula: "tea*40"}))
+		fails(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "broken", Kind: pb.KindDerived, Formula: "espresso +"}))
+		fails(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "caffeine_mg", Formula: "caffeine_mg + 1"}))
+		fails(t)(s.DeleteMetric(ctx, &pb.DeleteMetricRequest{MetricName: "espresso"}))
+
+		succeeds(t)(s.EditMetric(ctx, &pb.EditMetricRequest{MetricName: "caffeine_mg", Formula: "max(espresso*63, drip_coffee*95)"}))
+		if m := getMetric(t, s, "caffeine_mg"); m.Value != 126 {
+			t.Errorf("caffeine_mg = %g after editing its formula, want 126", m.Value)
+		}
+	})
+}
+
+func TestLinks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pb.ClientIDKey, "tui"))
+		values := func() [3]float64 {
+			t.Helper()
+			return [3]float64{getMetric(t, s, "latte").Value, getMetric(t, s, "caffeine_mg").Value, getMetric(t, s, "dairy_servings").Value}
+		}
+		for _, name := range []string{"latte", "caffeine_mg", "dairy_servings"} {
+			succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: name, Type: "Food", Unit: "units", Kind: pb.KindCounter}))
+		}
+		succeeds(t)(s.SetLink(ctx, &pb.SetLinkRequest{Link: &pb.Link{Source: "latte", Target: "caffeine_mg", Multiplier: 75}}))
+		succeeds(t)(s.SetLink(ctx, &pb.SetLinkRequest{Link: &pb.Link{Source: "latte", Target: "dairy_servings", Multiplier: 1}}))
+		fails(t)(s.SetLink(ctx, &pb.SetLinkRequest{Link: &pb.Link{Source: "latte", Target: "tea", Multiplier: 1}}))
+		fails(t)(s.SetLink(ctx, &pb.SetLinkRequest{Link: &pb.Link{Source: "latte", Target: "dairy_servings"}}))
+
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "latte", Increment: 1}))
+		if got := values(); got != [3]float64{1, 75, 1} {
+			t.Errorf("after a latte = %v, want [1 75 1]", got)
+		}
+
+		// Undo takes back the fanned out changes with the entry, redo brings them back
+		succeeds(t)(s.Undo(ctx, &pb.UndoRequest{ClientId: "tui"}))
+		if got := values(); got != [3]float64{0, 0, 0} {
+			t.Errorf("after undoing the latte = %v, want [0 0 0]", got)
+		}
+		succeeds(t)(s.Redo(ctx, &pb.RedoRequest{ClientId: "tui"}))
+		if got := values(); got != [3]float64{1, 75, 1} {
+			t.Errorf("after redoing the latte = %v, want [1 75 1]", got)
+		}
+		succeeds(t)(s.Undo(ctx, &pb.UndoRequest{ClientId: "tui"}))
+		if got := values(); got != [3]float64{0, 0, 0} {
+			t.Errorf("after undoing the redone latte = %v, want [0 0 0]", got)
+		}
+
+		resp, err := s.GetLinks(ctx, &pb.GetLinksRequest{MetricName: "dairy_servings"})
+		if err != nil || len(resp.Links) != 1 || resp.Links[0].Source != "latte" || resp.Links[0].Multiplier != 1 {
+			t.Errorf("GetLinks(dairy_servings) = %v, %v, want the link from latte", resp, err)
+		}
+		succeeds(t)(s.DeleteLink(ctx, &pb.DeleteLinkRequest{Source: "latte", Target: "dairy_servings"}))
+		fails(t)(s.DeleteLink(ctx, &pb.DeleteLinkRequest{Source: "latte", Target: "dairy_servings"}))
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "latte", Increment: 2}))
+		if got := values(); got != [3]float64{2, 150, 0} {
+			t.Errorf("after unlinking dairy_servings = %v, want [2 150 0]", got)
+		}
+	})
+}
+
+func TestEntryUnits(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pb.ClientIDKey, "tui"))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "water", Type: "Food", Unit: "ml", Kind: pb.KindGauge}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "weight", Type: "Health", Unit: "kg", Kind: pb.KindGauge}))
+
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 2, Unit: "cups"}))
+		succeeds(t)(s.DecrementMetric(ctx, &pb.DecrementMetricRequest{MetricName: "water", Decrement: 0.1, Unit: "L"}))
+		succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "weight", NewValue: 154, Unit: "lb"}))
+		if got := math.Round(getMetric(t, s, "water").Value*1000) / 1000; got != 373.176 {
+			t.Errorf("water = %g after 2 cups less 0.1 L, want 373.176 ml", got)
+		}
+		if got := math.Round(getMetric(t, s, "weight").Value*1000) / 1000; got != 69.853 {
+			t.Errorf("weight = %g after 154 lb, want 69.853 kg", got)
+		}
+
+		resp, err := s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 1, Unit: "kg"})
+		if err != nil || resp.Success || !strings.Contains(resp.Message, "cannot convert kg (mass) to ml (volume)") {
+			t.Errorf("increment water in kg = %v, %v, want an incompatible unit error", resp, err)
+		}
+		fails(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "water", Increment: 1, Unit: "gulps"}))
+	})
+}
+
+func TestQueryAggregate(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *MetricsServer) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pb.ClientIDKey, "tui"))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "sleep", Type: "Health", Unit: "h", Kind: pb.KindGauge, Tags: "person=alex"}))
+		succeeds(t)(s.AddMetric(ctx, &pb.AddMetricRequest{MetricName: "naps", Type: "Health", Unit: "h", Kind: pb.KindCounter, Tags: "person=sam"}))
+		for i, hours := range []float64{7, 8, 6, 9} {
+			occurredAt := time.Date(2024, 10, 14+i, 7, 0, 0, 0, time.UTC).Format(time.RFC3339)
+			succeeds(t)(s.UpdateMetric(ctx, &pb.UpdateMetricRequest{MetricName: "sleep", NewValue: hours, OccurredAt: occurredAt}))
+		}
+		succeeds(t)(s.IncrementMetric(ctx, &pb.IncrementMetricRequest{MetricName: "naps", Increment: 0.5, OccurredAt: "2024-10-15T14:00:00Z"}))
+
+		resp, err := s.QueryAggregate(ctx, &pb.QueryAggregateRequest{
+			Type: "Health", Start: "2024-10-14T00:00:00Z", End: "2024-10-17T00:00:00Z",
+			GroupBy: []string{"metric", "tag:person"}, Percentiles: []float64{50},
+		})
+		if err != nil {
+			t.Fatalf("QueryAggregate failed: %v", err)
+		}
+		var got []string
+		for _, g := range resp.Groups {
+			got = append(got, fmt.Sprintf("%s %s %d %g %g %v", g.MetricName, g.Tags, g.Count, g.Sum, g.Avg, g.Percentiles))
+		}
+		if want := []string{"naps person=sam 1 0.5 0.5 [0.5]", "sleep person=alex 3 21 7 [7]"}; !slices.Equal(got, want) {
+			t.Errorf("QueryAggregate = %q, want %q", got, want)
+		}
+
+		for _, req := range []*pb.QueryAggregateRequest{
+			{Bucket: "fortnight"},
+			{Start: "last week"},
+			{Tags: "person=alex,person=sam"},
+			{MetricNames: []string{"tea"}},
+		} {
+			if _, err := s.QueryAggregate(ctx, req); err == nil {
+				t.Errorf("QueryAggregate(%v) succeeded, want an error", req)
+			}
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package grpcSrv
+
+import (
+	"context"
+
+	"github.com/qjs/quanti-tea/server/db"
+
+	pb "github.com/qjs/quanti-tea/server/proto"
+)
+
+// SetLink adds a link between two metrics or changes its multiplier
+func (s *MetricsServer) SetLink(ctx context.Context, req *pb.SetLinkRequest) (*pb.SetLinkResponse, error) {
+	link := db.Link{
+		Source:     req.GetLink().GetSource(),
+		Target:     req.GetLink().GetTarget(),
+		Multiplier: req.GetLink().GetMultiplier(),
+	}
+	if err := s.DB.SetLink(link); err != nil {
+		return &pb.SetLinkResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.SetLinkResponse{
+		Success: true,
+		Message: "Link saved.",
+	}, nil
+}
+
+// DeleteLink removes a link, the entries it fanned out are kept
+func (s *MetricsServer) DeleteLink(ctx context.Context, req *pb.DeleteLinkRequest) (*pb.DeleteLinkResponse, error) {
+	if err := s.DB.DeleteLink(req.Source, req.Target); err != nil {
+		return &pb.DeleteLinkResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteLinkResponse{
+		Success: true,
+		Message: "Link deleted.",
+	}, nil
+}
+
+// GetLinks lists the links from or to a metric, or every link
+func (s *MetricsServer) GetLinks(ctx context.Context, req *pb.GetLinksRequest) (*pb.GetLinksResponse, error) {
+	links, err := s.DB.GetLinks(req.MetricName)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pb.GetLinksResponse
+	for _, link := range links {
+		resp.Links = append(resp.Links, &pb.Link{
+			Source:     link.Source,
+			Target:     link.Target,
+			Multiplier: link.Multiplier,
+		})
+	}
+	return &resp, nil
+}
@@ -0,0 +1,165 @@
+package grpcSrv
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/qjs/quanti-tea/server/db"
+	"github.com/qjs/quanti-tea/server/importer"
+
+	pb "github.com/qjs/quanti-tea/server/proto"
+)
+
+// ExportData streams a bundle of every metric with its history, including
+// the metrics in the trash
+func (s *MetricsServer) ExportData(req *pb.ExportDataRequest, stream pb.MetricsService_ExportDataServer) error {
+	format, err := db.ParseBundleFormat(req.Format)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := db.NewBundle(s.DB, time.Now())
+	if err != nil {
+		return err
+	}
+
+	w := pb.NewChunkWriter(func(data []byte) error {
+		return stream.Send(&pb.DataChunk{Data: data})
+	})
+	return bundle.Encode(w, format)
+}
+
+// ImportData reads a bundle written by ExportData and imports its metrics
+// one by one, so a metric that fails to import does not hold back the others,
+// and then its links
+func (s *MetricsServer) ImportData(stream pb.MetricsService_ImportDataServer) error {
+	fail := func(message string) error {
+		return stream.SendAndClose(&pb.ImportDataResponse{Success: false, Message: message})
+	}
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return fail("No data received.")
+	}
+	if err != nil {
+		return err
+	}
+	format, err := db.ParseBundleFormat(first.Format)
+	if err != nil {
+		return fail(err.Error())
+	}
+	policy, err := db.ParseConflictPolicy(first.ConflictPolicy)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	pending := first.Data
+	r := pb.NewChunkReader(func() ([]byte, error) {
+		if pending != nil {
+			data := pending
+			pending = nil
+			return data, nil
+		}
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return req.Data, nil
+	})
+	bundle, err := db.DecodeBundle(r, format)
+	if err != nil {
+		return fail(err.Error())
+	}
+	// Read whatever follows the bundle so the client can finish sending
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+
+	resp := &pb.ImportDataResponse{Success: true}
+	counts := make(map[string]int)
+	for _, data := range bundle.Metrics {
+		result, err := s.DB.ImportMetric(data, policy, first.DryRun)
+		if err != nil {
+			resp.Success = false
+			counts["failed"]++
+			resp.Results = append(resp.Results, &pb.ImportResult{
+				MetricName: data.Metric.MetricName,
+				Outcome:    "failed",
+				Error:      err.Error(),
+			})
+			continue
+		}
+		counts[string(result.Outcome)]++
+		resp.Results = append(resp.Results, &pb.ImportResult{
+			MetricName: result.MetricName,
+			Outcome:    string(result.Outcome),
+			Events:     int32(result.Events),
+			Rollups:    int32(result.Rollups),
+		})
+	}
+
+	resp.Message = fmt.Sprintf("%d metrics: %d created, %d overwritten, %d merged, %d skipped, %d failed.",
+		len(bundle.Metrics), counts[string(db.ImportCreated)], counts[string(db.ImportOverwritten)],
+		counts[string(db.ImportMerged)], counts[string(db.ImportSkipped)], counts["failed"])
+	if len(bundle.Links) > 0 {
+		set, skipped, errs := db.ImportLinks(s.DB, bundle.Links, policy, first.DryRun)
+		for _, err := range errs {
+			resp.Success = false
+			resp.Results = append(resp.Results, &pb.ImportResult{Outcome: "failed", Error: err.Error()})
+		}
+		resp.Message += fmt.Sprintf(" %d links: %d set, %d skipped, %d failed.", len(bundle.Links), set, skipped, len(errs))
+	}
+	if first.DryRun {
+		resp.Message = "Dry run, nothing was changed. " + resp.Message
+	}
+	return stream.SendAndClose(resp)
+}
+
+// ImportEntries loads the export of another self-tracking app through the
+// regular entry path, creating the metrics it needs
+func (s *MetricsServer) ImportEntries(stream pb.MetricsService_ImportEntriesServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return stream.SendAndClose(&pb.ImportEntriesResponse{Success: false, Message: "No data received."})
+	}
+	if err != nil {
+		return err
+	}
+	adapter, err := importer.NewAdapter(first.Source, first.Mapping)
+	if err != nil {
+		return stream.SendAndClose(&pb.ImportEntriesResponse{Success: false, Message: err.Error()})
+	}
+
+	pending := first.Data
+	r := pb.NewChunkReader(func() ([]byte, error) {
+		if pending != nil {
+			data := pending
+			pending = nil
+			return data, nil
+		}
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return req.Data, nil
+	})
+	report, err := importer.Import(s.DB, adapter, r, time.Now())
+	if err != nil {
+		return stream.SendAndClose(&pb.ImportEntriesResponse{Success: false, Message: err.Error()})
+	}
+
+	resp := &pb.ImportEntriesResponse{
+		Success:        true,
+		Message:        fmt.Sprintf("Imported %d of %d rows, skipped %d and rejected %d.", report.Imported, report.Rows, report.Skipped, report.Rejected),
+		Rows:           int32(report.Rows),
+		Imported:       int32(report.Imported),
+		Skipped:        int32(report.Skipped),
+		Rejected:       int32(report.Rejected),
+		CreatedMetrics: report.Created,
+	}
+	for _, issue := range report.Issues {
+		resp.Issues = append(resp.Issues, &pb.ImportIssue{Line: int32(issue.Line), Rejected: issue.Rejected, Reason: issue.Reason})
+	}
+	return stream.SendAndClose(resp)
+}
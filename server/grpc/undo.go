@@ -0,0 +1,241 @@
+package grpcSrv
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/qjs/quanti-tea/server/db"
+
+	pb "github.com/qjs/quanti-tea/server/proto"
+)
+
+// journalSize bounds the number of mutations kept for Undo and Redo
+const journalSize = 100
+
+// journalOp is a mutation made through the server that Undo can revert
+type journalOp struct {
+	db.JournalOp
+}
+
+// describe names the op for the messages of Undo and Redo
+func (op *journalOp) describe() string {
+	switch op.Kind {
+	case db.OpAdd:
+		return fmt.Sprintf("adding %s", op.MetricName)
+	case db.OpDelete:
+		return fmt.Sprintf("deleting %s", op.MetricName)
+	}
+	amount := fmt.Sprintf("%g", op.Entry.Amount)
+	if unit := op.Entry.Unit; unit != "" {
+		amount += " " + unit
+	}
+	if op.Kind == db.OpUpdate {
+		return fmt.Sprintf("the update of %s to %s", op.MetricName, amount)
+	}
+	return fmt.Sprintf("the %s of %s by %s", op.Kind, op.MetricName, amount)
+}
+
+// inScope reports whether the op was made by clientID on metricName, an empty
+// string matching any client or metric
+func (op *journalOp) inScope(clientID, metricName string) bool {
+	return (clientID == "" || op.ClientID == clientID) && (metricName == "" || op.MetricName == metricName)
+}
+
+// journal keeps the most recent mutations, oldest first, in memory and in the
+// store, so Undo and Redo keep working after a restart
+type journal struct {
+	mu      sync.Mutex
+	store   db.Store
+	ops     []*journalOp
+	undoSeq int
+}
+
+// load reads the journal kept by store
+func (j *journal) load(store db.Store) error {
+	ops, err := store.GetJournal()
+	if err != nil {
+		return err
+	}
+	j.store = store
+	for _, op := range ops {
+		j.ops = append(j.ops, &journalOp{op})
+		j.undoSeq = max(j.undoSeq, op.Undone)
+	}
+	return nil
+}
+
+// save stores the current state of op and drops the ops dropped from the
+// journal. A journal that cannot be saved keeps working from memory until the
+// server restarts.
+func (j *journal) save(op *journalOp, dropped ...*journalOp) {
+	var ids []int64
+	for _, o := range dropped {
+		ids = append(ids, o.ID)
+	}
+	id, err := j.store.SaveJournalOp(op.JournalOp, ids)
+	if err != nil {
+		log.Printf("Failed to save the undo journal: %v", err)
+		return
+	}
+	op.ID = id
+}
+
+// record appends an op. The ops the same client has undone can no longer be
+// redone, like in any editor.
+func (j *journal) record(op *journalOp) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var ops, dropped []*journalOp
+	for _, o := range j.ops {
+		if o.Undone == 0 || o.ClientID != op.ClientID {
+			ops = append(ops, o)
+		} else {
+			dropped = append(dropped, o)
+		}
+	}
+	ops = append(ops, op)
+	if len(ops) > journalSize {
+		dropped = append(dropped, ops[:len(ops)-journalSize]...)
+		ops = ops[len(ops)-journalSize:]
+	}
+	j.ops = ops
+	j.save(op, dropped...)
+}
+
+// remove drops an op that can no longer be undone or redone
+func (j *journal) remove(op *journalOp) {
+	for i, o := range j.ops {
+		if o == op {
+			j.ops = append(j.ops[:i], j.ops[i+1:]...)
+			if err := j.store.DeleteJournalOps([]int64{op.ID}); err != nil {
+				log.Printf("Failed to save the undo journal: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// applyEntry performs an increment, decrement or update and records it in the
+// journal under the client id of ctx. The changes it fanned out to linked
+// metrics point at its event, so they are undone with it.
+func (s *MetricsServer) applyEntry(ctx context.Context, entry db.Entry) error {
+	events, err := s.DB.ApplyEntries([]db.Entry{entry})
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", entry.Operation, err)
+	}
+
+	entry.OccurredAt = events[0].OccurredAt
+	s.journal.record(&journalOp{db.JournalOp{
+		ClientID:   pb.ClientID(ctx),
+		MetricName: entry.MetricName,
+		Kind:       entry.Operation,
+		Entry:      entry,
+		EventID:    events[0].ID,
+	}})
+	return nil
+}
+
+// undo reverts op in the store. An added metric goes to the trash, where it
+// can be restored from, like any deleted metric.
+func (s *MetricsServer) undo(op *journalOp) error {
+	switch op.Kind {
+	case db.OpAdd:
+		return s.DB.DeleteMetric(op.MetricName)
+	case db.OpDelete:
+		return s.DB.RestoreMetric(op.MetricName)
+	}
+	return s.DB.RevertEntries([]int64{op.EventID})
+}
+
+// redo applies op to the store again
+func (s *MetricsServer) redo(op *journalOp) error {
+	switch op.Kind {
+	case db.OpAdd:
+		return s.DB.RestoreMetric(op.MetricName)
+	case db.OpDelete:
+		return s.DB.DeleteMetric(op.MetricName)
+	}
+	events, err := s.DB.ApplyEntries([]db.Entry{op.Entry})
+	if err != nil {
+		return err
+	}
+	op.EventID = events[0].ID
+	return nil
+}
+
+// Undo reverts the most recent mutation in the journal, optionally only among
+// those of a client or a metric. An op that fails to revert, because the
+// metric has been purged or renamed since for example, is dropped from the
+// journal so the next Undo moves on to the one before it.
+func (s *MetricsServer) Undo(ctx context.Context, req *pb.UndoRequest) (*pb.UndoResponse, error) {
+	s.journal.mu.Lock()
+	defer s.journal.mu.Unlock()
+
+	var op *journalOp
+	for i := len(s.journal.ops) - 1; i >= 0; i-- {
+		if o := s.journal.ops[i]; o.Undone == 0 && o.inScope(req.ClientId, req.MetricName) {
+			op = o
+			break
+		}
+	}
+	if op == nil {
+		return &pb.UndoResponse{
+			Success: false,
+			Message: "Nothing to undo.",
+		}, nil
+	}
+
+	if err := s.undo(op); err != nil {
+		s.journal.remove(op)
+		return &pb.UndoResponse{
+			Success: false,
+			Message: fmt.Sprintf("Could not undo %s: %v", op.describe(), err),
+		}, nil
+	}
+	s.journal.undoSeq++
+	op.Undone = s.journal.undoSeq
+	s.journal.save(op)
+
+	return &pb.UndoResponse{
+		Success: true,
+		Message: fmt.Sprintf("Undid %s.", op.describe()),
+	}, nil
+}
+
+// Redo applies the most recently undone mutation again, optionally only among
+// those of a client or a metric
+func (s *MetricsServer) Redo(ctx context.Context, req *pb.RedoRequest) (*pb.RedoResponse, error) {
+	s.journal.mu.Lock()
+	defer s.journal.mu.Unlock()
+
+	var op *journalOp
+	for _, o := range s.journal.ops {
+		if o.Undone != 0 && o.inScope(req.ClientId, req.MetricName) && (op == nil || o.Undone > op.Undone) {
+			op = o
+		}
+	}
+	if op == nil {
+		return &pb.RedoResponse{
+			Success: false,
+			Message: "Nothing to redo.",
+		}, nil
+	}
+
+	if err := s.redo(op); err != nil {
+		s.journal.remove(op)
+		return &pb.RedoResponse{
+			Success: false,
+			Message: fmt.Sprintf("Could not redo %s: %v", op.describe(), err),
+		}, nil
+	}
+	op.Undone = 0
+	s.journal.save(op)
+
+	return &pb.RedoResponse{
+		Success: true,
+		Message: fmt.Sprintf("Redid %s.", op.describe()),
+	}, nil
+}
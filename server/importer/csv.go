@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/qjs/quanti-tea/server/db"
+
+	pb "github.com/qjs/quanti-tea/server/proto"
+)
+
+// Mapping describes the columns of a generic CSV file and the settings of the
+// metrics it creates. It is read from a JSON mapping file; every field is
+// optional.
+type Mapping struct {
+	Timestamp       string                    `json:"timestamp"`        // Column holding when an entry happened, default "timestamp"
+	TimestampFormat string                    `json:"timestamp_format"` // Go time layout of the timestamps, see csvTimeLayouts for the default
+	Metric          string                    `json:"metric"`           // Column holding the metric name, default "metric"
+	Value           string                    `json:"value"`            // Column holding the value, default "value"
+	Note            string                    `json:"note"`             // Column holding a note kept with each entry, default none
+	Operation       string                    `json:"operation"`        // "increment" (default) adds each value, "update" sets it
+	Defaults        MetricSettings            `json:"defaults"`         // Settings of created metrics
+	Metrics         map[string]MetricSettings `json:"metrics"`          // Settings of created metrics by name, over Defaults
+}
+
+// MetricSettings are the settings a metric is created with. Empty fields
+// fall back to the defaults of the mapping, then to type "Imported", unit
+// "units", a daily reset and a counter, or a gauge for updates.
+type MetricSettings struct {
+	Type  string `json:"type"`
+	Unit  string `json:"unit"`
+	Kind  string `json:"kind"`
+	Reset string `json:"reset"`
+}
+
+// csvTimeLayouts are tried in order when the mapping sets no timestamp
+// format. Layouts without a zone are read in the zone of the metric.
+var csvTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", time.DateOnly}
+
+// csvAdapter reads one entry per row of a CSV file described by a Mapping
+type csvAdapter struct {
+	mapping Mapping
+	op      string
+}
+
+func newCSVAdapter(data []byte) (Adapter, error) {
+	var m Mapping
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("invalid mapping file: %w", err)
+		}
+	}
+	if m.Timestamp == "" {
+		m.Timestamp = "timestamp"
+	}
+	if m.Metric == "" {
+		m.Metric = "metric"
+	}
+	if m.Value == "" {
+		m.Value = "value"
+	}
+
+	a := csvAdapter{mapping: m}
+	switch strings.ToLower(m.Operation) {
+	case "", db.OpIncrement:
+		a.op = db.OpIncrement
+	case db.OpUpdate:
+		a.op = db.OpUpdate
+	default:
+		return nil, fmt.Errorf("unknown operation %q in mapping file, expected increment or update", m.Operation)
+	}
+
+	// Reject bad settings before reading a single row
+	for _, name := range append([]string{""}, keys(m.Metrics)...) {
+		if _, err := a.template(name); err != nil {
+			return nil, fmt.Errorf("invalid settings for metric %q in mapping file: %w", name, err)
+		}
+	}
+	return a, nil
+}
+
+func keys(m map[string]MetricSettings) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+// template returns the settings a metric is created with
+func (a csvAdapter) template(name string) (db.DBMetric, error) {
+	s := a.mapping.Metrics[name]
+	pick := func(values ...string) string {
+		for _, v := range values {
+			if v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	kind := db.KindCounter
+	if a.op == db.OpUpdate {
+		kind = db.KindGauge
+	}
+
+	metric := db.DBMetric{
+		MetricName: name,
+		Type:       pick(s.Type, a.mapping.Defaults.Type, "Imported"),
+		Unit:       pick(s.Unit, a.mapping.Defaults.Unit, "units"),
+	}
+	var err error
+	if metric.Kind, err = db.ParseMetricKind(pick(s.Kind, a.mapping.Defaults.Kind, string(kind))); err != nil {
+		return metric, err
+	}
+	if metric.Reset, err = db.ParseResetPolicy(pick(s.Reset, a.mapping.Defaults.Reset, string(db.ResetDaily))); err != nil {
+		return metric, err
+	}
+	return metric, nil
+}
+
+// timestamp parses when an entry happened and reports whether it is a wall
+// clock time without a zone
+func (a csvAdapter) timestamp(s string) (t time.Time, floating, dateOnly bool, err error) {
+	layouts := csvTimeLayouts
+	if a.mapping.TimestampFormat != "" {
+		layouts = []string{a.mapping.TimestampFormat}
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			hasZone := strings.Contains(layout, "Z07") || strings.Contains(layout, "-07") || strings.Contains(layout, "MST")
+			return t, !hasZone, layout == time.DateOnly, nil
+		}
+	}
+	return time.Time{}, false, false, fmt.Errorf("invalid timestamp %q", s)
+}
+
+func (a csvAdapter) Read(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the CSV file is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	needed := []string{a.mapping.Timestamp, a.mapping.Metric, a.mapping.Value}
+	if a.mapping.Note != "" {
+		needed = append(needed, a.mapping.Note)
+	}
+	for _, required := range needed {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the CSV file has no %s column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []Row
+	for n, record := range records[1:] {
+		row := Row{Line: n + 2}
+		name, value := field(record, a.mapping.Metric), field(record, a.mapping.Value)
+		switch {
+		case name == "":
+			row.Err = fmt.Errorf("no metric name")
+		case value == "":
+			row.Skip = "no value"
+		default:
+			row.Err = a.readEntry(&row, name, value, field(record, a.mapping.Timestamp), field(record, a.mapping.Note))
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readEntry adds the entry of a row to it
+func (a csvAdapter) readEntry(row *Row, name, value, timestamp, note string) error {
+	metric, err := a.template(name)
+	if err != nil {
+		return err
+	}
+	amount, err := pb.ParseValue(string(metric.Kind), value)
+	if err != nil {
+		return err
+	}
+	at, floating, dateOnly, err := a.timestamp(timestamp)
+	if err != nil {
+		return err
+	}
+	row.Entries = append(row.Entries, Entry{
+		Metric:    metric,
+		Operation: a.op,
+		Value:     amount,
+		At:        at,
+		Floating:  floating,
+		DateOnly:  dateOnly,
+		Note:      note,
+	})
+	return nil
+}
@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/qjs/quanti-tea/server/db"
+)
+
+// daylioAdapter reads the CSV export of Daylio. The mood of each entry
+// becomes a rating on the "Mood" metric and every activity logged with it an
+// increment of a counter named after the activity.
+type daylioAdapter struct{}
+
+// daylioMoods rates the five default moods of Daylio from 1 to 5
+var daylioMoods = map[string]float64{
+	"awful": 1,
+	"bad":   2,
+	"meh":   3,
+	"good":  4,
+	"rad":   5,
+}
+
+// daylioActivitySeparator separates the activities of an entry
+const daylioActivitySeparator = "|"
+
+// Time layouts Daylio writes depending on the phone's settings
+var daylioClocks = []string{"15:04", "3:04 PM", "3:04PM"}
+
+func (daylioAdapter) Read(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Daylio export: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the Daylio export is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"full_date", "time", "mood", "activities"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("not a Daylio export, missing the %s column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	mood := db.DBMetric{
+		MetricName: "Mood",
+		Type:       "Mood",
+		Unit:       "rating",
+		Kind:       db.KindRating,
+		Reset:      db.ResetPolicy{Kind: db.ResetDaily},
+	}
+
+	var rows []Row
+	for n, record := range records[1:] {
+		row := Row{Line: n + 2}
+		at, err := daylioTime(field(record, "full_date"), field(record, "time"))
+		if err != nil {
+			row.Err = err
+			rows = append(rows, row)
+			continue
+		}
+
+		name := strings.ToLower(field(record, "mood"))
+		rating, ok := daylioMoods[name]
+		if !ok {
+			row.Err = fmt.Errorf("unknown mood %q, only the five default moods can be imported", name)
+			rows = append(rows, row)
+			continue
+		}
+		note := field(record, "note")
+		if title := field(record, "note_title"); title != "" {
+			note = strings.TrimSpace(title + "\n" + note)
+		}
+		row.Entries = append(row.Entries, Entry{Metric: mood, Operation: db.OpUpdate, Value: rating, At: at, Floating: true, Note: note})
+
+		for _, activity := range strings.Split(field(record, "activities"), daylioActivitySeparator) {
+			activity = strings.TrimSpace(activity)
+			if activity == "" {
+				continue
+			}
+			row.Entries = append(row.Entries, Entry{
+				Metric:    dailyMetric(activity, "Activity", "times", db.KindCounter),
+				Operation: db.OpIncrement,
+				Value:     1,
+				At:        at,
+				Floating:  true,
+			})
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// daylioTime combines the date and time columns of an entry into a wall
+// clock time
+func daylioTime(date, clock string) (time.Time, error) {
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", date)
+	}
+	for _, layout := range daylioClocks {
+		if t, err := time.Parse(layout, strings.ToUpper(clock)); err == nil {
+			return day.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", clock)
+}
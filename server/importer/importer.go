@@ -0,0 +1,252 @@
+// Package importer loads the exports of other self-tracking apps into a
+// store. An Adapter turns the rows of an export into entries, and Import
+// writes them through the regular increment and update path of the store,
+// backdated to when they happened, creating the metrics they need.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qjs/quanti-tea/server/db"
+)
+
+// Entry is a single value read from an export
+type Entry struct {
+	Metric    db.DBMetric // Name of the metric and the settings it is created with if it does not exist
+	Operation string      // db.OpIncrement or db.OpUpdate
+	Value     float64
+	At        time.Time // When the entry happened, see Floating and DateOnly
+	Floating  bool      // At is a wall clock time in the time zone of the metric's day boundary
+	DateOnly  bool      // Only the day of At is known; the entry is placed at midday
+	Note      string    // Kept with the entry in the history
+}
+
+// Row is a row of an export with the entries it holds
+type Row struct {
+	Line    int
+	Entries []Entry
+	Skip    string // Why the row holds nothing to import, e.g. an unchecked habit
+	Err     error  // Why the row cannot be read
+}
+
+// Adapter reads the export of one app
+type Adapter interface {
+	Read(r io.Reader) ([]Row, error)
+}
+
+// NewAdapter returns the adapter for source: "loop" for Loop Habit Tracker,
+// "daylio" for Daylio or "csv" for a generic CSV file described by mapping,
+// which may be empty for the default columns
+func NewAdapter(source string, mapping []byte) (Adapter, error) {
+	switch strings.ToLower(strings.TrimSpace(source)) {
+	case "loop":
+		return loopAdapter{}, nil
+	case "daylio":
+		return daylioAdapter{}, nil
+	case "csv":
+		return newCSVAdapter(mapping)
+	default:
+		return nil, fmt.Errorf("unknown source %q, expected loop, daylio or csv", source)
+	}
+}
+
+// Issue is a row that was skipped or rejected
+type Issue struct {
+	Line     int
+	Rejected bool
+	Reason   string
+}
+
+// Report summarizes an import
+type Report struct {
+	Rows     int
+	Imported int
+	Skipped  int
+	Rejected int
+	Created  []string // Metrics created for the import
+	Issues   []Issue  // Skipped and rejected rows in file order
+}
+
+// errDuplicate marks an entry whose event is already in the history, so
+// importing the same export twice does not count it twice
+var errDuplicate = fmt.Errorf("already imported")
+
+// Import reads r with adapter and writes its entries to store in the order
+// they happened. A row that cannot be read, or whose entries the store
+// rejects, is reported without stopping the import; entries of a rejected row
+// that were accepted before are kept.
+func Import(store db.Store, adapter Adapter, r io.Reader, now time.Time) (Report, error) {
+	rows, err := adapter.Read(r)
+	if err != nil {
+		return Report{}, err
+	}
+
+	type pending struct {
+		row   int
+		entry Entry
+	}
+	var entries []pending
+	for i, row := range rows {
+		if row.Skip != "" || row.Err != nil {
+			continue
+		}
+		for _, e := range row.Entries {
+			entries = append(entries, pending{row: i, entry: e})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].entry.At.Before(entries[j].entry.At) })
+
+	w := writer{store: store, now: now, metrics: make(map[string]*db.DBMetric), failed: make(map[string]error), claimed: make(map[int64]bool)}
+	errs := make([]error, len(rows))
+	written := make([]bool, len(rows))
+	for _, p := range entries {
+		err := w.write(p.entry)
+		switch {
+		case err == nil:
+			written[p.row] = true
+		case err == errDuplicate:
+		case errs[p.row] == nil:
+			errs[p.row] = err
+		}
+	}
+
+	report := Report{Rows: len(rows), Created: w.created}
+	for i, row := range rows {
+		issue := Issue{Line: row.Line}
+		switch {
+		case row.Err != nil:
+			issue.Rejected, issue.Reason = true, row.Err.Error()
+		case row.Skip != "":
+			issue.Reason = row.Skip
+		case errs[i] != nil:
+			issue.Rejected, issue.Reason = true, errs[i].Error()
+		case len(row.Entries) == 0:
+			issue.Reason = "nothing to import"
+		case !written[i]:
+			issue.Reason = errDuplicate.Error()
+		default:
+			report.Imported++
+			continue
+		}
+		if issue.Rejected {
+			report.Rejected++
+		} else {
+			report.Skipped++
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	return report, nil
+}
+
+// writer applies entries to a store, creating missing metrics once
+type writer struct {
+	store   db.Store
+	now     time.Time
+	metrics map[string]*db.DBMetric
+	failed  map[string]error // Metrics that could not be created
+	claimed map[int64]bool   // Events written by this import or already matched to one of its entries
+	created []string
+}
+
+// metric returns the metric an entry goes to, creating it from the entry's
+// settings if needed
+func (w *writer) metric(template db.DBMetric) (*db.DBMetric, error) {
+	name := template.MetricName
+	if m, ok := w.metrics[name]; ok {
+		return m, nil
+	}
+	if err, ok := w.failed[name]; ok {
+		return nil, err
+	}
+
+	m, err := w.store.GetMetric(name)
+	if err != nil {
+		if err := w.store.AddMetric(template); err != nil {
+			w.failed[name] = err
+			return nil, err
+		}
+		if m, err = w.store.GetMetric(name); err != nil {
+			return nil, err
+		}
+		w.created = append(w.created, name)
+	}
+	w.metrics[name] = m
+	return m, nil
+}
+
+// occurredAt places an entry in time, using the zone of the metric's day
+// boundary for wall clock times
+func (w *writer) occurredAt(e Entry, m *db.DBMetric) time.Time {
+	if !e.Floating && !e.DateOnly {
+		return e.At
+	}
+	loc := m.Day.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	year, month, day := e.At.Date()
+	at := time.Date(year, month, day, e.At.Hour(), e.At.Minute(), e.At.Second(), 0, loc)
+	if e.DateOnly {
+		at = time.Date(year, month, day, 12, 0, 0, 0, loc)
+		if at.After(w.now) {
+			at = w.now
+		}
+	}
+	return at
+}
+
+// write applies one entry, or returns errDuplicate if the history had it
+// before the import started. Each event of that history that was not undone
+// stands for one entry, so identical entries of the same file are all written
+// on the first import and all skipped on the next.
+func (w *writer) write(e Entry) error {
+	m, err := w.metric(e.Metric)
+	if err != nil {
+		return err
+	}
+	at := w.occurredAt(e, m)
+
+	history, err := w.store.GetMetricHistory(m.MetricName, at, at.Add(time.Second), 0)
+	if err != nil {
+		return err
+	}
+	for _, event := range history {
+		if w.claimed[event.ID] || event.Reverted {
+			continue
+		}
+		if event.Operation == e.Operation && (e.Operation == db.OpIncrement && event.Delta == e.Value || e.Operation == db.OpUpdate && event.Value == e.Value) {
+			w.claimed[event.ID] = true
+			return errDuplicate
+		}
+	}
+
+	switch e.Operation {
+	case db.OpIncrement, db.OpUpdate:
+	default:
+		return fmt.Errorf("unknown operation %q", e.Operation)
+	}
+	events, err := w.store.ApplyEntries([]db.Entry{{MetricName: m.MetricName, Operation: e.Operation, Amount: e.Value, OccurredAt: at, Note: e.Note}})
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		w.claimed[event.ID] = true
+	}
+	return nil
+}
+
+// dailyMetric returns the settings of a metric created by an import, which
+// resets every day like the trackers the entries come from
+func dailyMetric(name, metricType, unit string, kind db.MetricKind) db.DBMetric {
+	return db.DBMetric{
+		MetricName: name,
+		Type:       metricType,
+		Unit:       unit,
+		Kind:       kind,
+		Reset:      db.ResetPolicy{Kind: db.ResetDaily},
+	}
+}
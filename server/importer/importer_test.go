@@ -0,0 +1,210 @@
+package importer
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qjs/quanti-tea/server/db"
+)
+
+// newStore returns a memory store whose clock stands at now and whose days
+// start at midnight UTC
+func newStore(now time.Time) *db.MemoryStore {
+	return db.NewMemoryStore(db.WithClock(func() time.Time { return now }), db.WithDayBoundary(db.DayBoundary{Location: time.UTC}))
+}
+
+// runImport imports input from source and fails the test on errors
+func runImport(t *testing.T, store db.Store, source, mapping, input string, now time.Time) Report {
+	t.Helper()
+	adapter, err := NewAdapter(source, []byte(mapping))
+	if err != nil {
+		t.Fatalf("NewAdapter(%s) failed: %v", source, err)
+	}
+	report, err := Import(store, adapter, strings.NewReader(input), now)
+	if err != nil {
+		t.Fatalf("Import(%s) failed: %v", source, err)
+	}
+	return report
+}
+
+// checkValues compares the current value and the archived days of metrics
+func checkValues(t *testing.T, store db.Store, want map[string]float64, rollups map[string]string) {
+	t.Helper()
+	for name, value := range want {
+		metric, err := store.GetMetric(name)
+		if err != nil {
+			t.Errorf("GetMetric(%s) failed: %v", name, err)
+			continue
+		}
+		if metric.Value != value {
+			t.Errorf("%s = %g, want %g", name, metric.Value, value)
+		}
+
+		days, err := store.GetDailyRollups(name, "", "")
+		if err != nil {
+			t.Fatalf("GetDailyRollups failed: %v", err)
+		}
+		var got []string
+		for _, r := range days {
+			got = append(got, r.Date+"="+strconv.FormatFloat(r.FinalValue, 'g', -1, 64))
+		}
+		if strings.Join(got, " ") != rollups[name] {
+			t.Errorf("%s rollups = %v, want %s", name, got, rollups[name])
+		}
+	}
+}
+
+func TestImportLoop(t *testing.T) {
+	now := time.Date(2024, 10, 16, 18, 0, 0, 0, time.UTC)
+	store := newStore(now)
+	input := "Date,Meditate,Water,\n" +
+		"2024-10-16,2,3.5,\n" +
+		"2024-10-15,1,0,\n" +
+		"2024-10-14,0,2,\n" +
+		"2024-10-13,YES_MANUAL,,\n" +
+		"yesterday,2,1,\n"
+
+	report := runImport(t, store, "loop", "", input, now)
+	want := Report{Rows: 5, Imported: 3, Skipped: 1, Rejected: 1, Created: []string{"Meditate", "Water"}, Issues: []Issue{
+		{Line: 3, Reason: "no habit checked"},
+		{Line: 6, Rejected: true, Reason: `invalid date "yesterday"`},
+	}}
+	if !sameReport(report, want) {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	checkValues(t, store,
+		map[string]float64{"Meditate": 1, "Water": 3.5},
+		map[string]string{"Meditate": "2024-10-13=1", "Water": "2024-10-14=2"})
+
+	meditate, _ := store.GetMetric("Meditate")
+	if meditate.Kind != db.KindBoolean {
+		t.Errorf("Meditate was created as a %s", meditate.Kind)
+	}
+
+	// Importing the same export again changes nothing
+	report = runImport(t, store, "loop", "", input, now)
+	if report.Imported != 0 || report.Skipped != 4 || report.Rejected != 1 {
+		t.Errorf("second import = %+v", report)
+	}
+	checkValues(t, store,
+		map[string]float64{"Meditate": 1, "Water": 3.5},
+		map[string]string{"Meditate": "2024-10-13=1", "Water": "2024-10-14=2"})
+}
+
+func TestImportDaylio(t *testing.T) {
+	now := time.Date(2024, 10, 16, 23, 0, 0, 0, time.UTC)
+	store := newStore(now)
+	input := "full_date,date,weekday,time,mood,activities,note_title,note\n" +
+		"2024-10-16,October 16,Wednesday,21:30,good,reading | tea,,\n" +
+		"2024-10-16,October 16,Wednesday,8:15 am,meh,tea,,\n" +
+		"2024-10-15,October 15,Tuesday,22:00,rad,,,\"long day\"\n" +
+		"2024-10-14,October 14,Monday,20:00,ecstatic,tea,,\n"
+
+	report := runImport(t, store, "daylio", "", input, now)
+	want := Report{Rows: 4, Imported: 3, Rejected: 1, Created: []string{"Mood", "tea", "reading"}, Issues: []Issue{
+		{Line: 5, Rejected: true, Reason: `unknown mood "ecstatic", only the five default moods can be imported`},
+	}}
+	if !sameReport(report, want) {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	checkValues(t, store,
+		map[string]float64{"Mood": 4, "tea": 2, "reading": 1},
+		map[string]string{"Mood": "2024-10-15=5", "tea": "", "reading": ""})
+
+	notes, err := store.SearchNotes("", "", time.Time{}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+	if len(notes) != 1 || notes[0].MetricName != "Mood" || notes[0].Note != "long day" {
+		t.Errorf("notes = %+v, want the note of the mood of October 15", notes)
+	}
+}
+
+func TestImportGenericCSV(t *testing.T) {
+	now := time.Date(2024, 10, 16, 18, 0, 0, 0, time.UTC)
+	store := newStore(now)
+	mapping := `{
+		"timestamp": "When", "metric": "What", "value": "Amount",
+		"timestamp_format": "02/01/2006 15:04",
+		"metrics": {"Sleep": {"kind": "duration", "type": "Health"}}
+	}`
+	input := "When,What,Amount\n" +
+		"16/10/2024 07:00,Sleep,7h30m\n" +
+		"15/10/2024 07:00,Sleep,6h\n" +
+		"16/10/2024 09:00,Coffee,2\n" +
+		"16/10/2024 10:00,Coffee,\n" +
+		"soon,Coffee,1\n" +
+		"16/10/2024 11:00,Coffee,lots\n"
+
+	report := runImport(t, store, "csv", mapping, input, now)
+	want := Report{Rows: 6, Imported: 3, Skipped: 1, Rejected: 2, Created: []string{"Sleep", "Coffee"}, Issues: []Issue{
+		{Line: 5, Reason: "no value"},
+		{Line: 6, Rejected: true, Reason: `invalid timestamp "soon"`},
+		{Line: 7, Rejected: true, Reason: `"lots" is not a number`},
+	}}
+	if !sameReport(report, want) {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	checkValues(t, store,
+		map[string]float64{"Sleep": 27000, "Coffee": 2},
+		map[string]string{"Sleep": "2024-10-15=21600", "Coffee": ""})
+
+	sleep, _ := store.GetMetric("Sleep")
+	if sleep.Kind != db.KindDuration || sleep.Type != "Health" || sleep.Unit != "seconds" {
+		t.Errorf("Sleep was created as %+v", sleep)
+	}
+
+	if _, err := NewAdapter("csv", []byte(`{"defaults": {"kind": "thermometer"}}`)); err == nil {
+		t.Error("NewAdapter accepted an unknown kind in the mapping")
+	}
+	if _, err := NewAdapter("strava", nil); err == nil {
+		t.Error("NewAdapter accepted an unknown source")
+	}
+}
+
+func TestImportIdenticalRows(t *testing.T) {
+	now := time.Date(2024, 10, 16, 18, 0, 0, 0, time.UTC)
+	store := newStore(now)
+	mapping := `{"timestamp": "When", "metric": "What", "value": "Amount", "timestamp_format": "2006-01-02 15:04"}`
+	// Two coffees in the same minute are two entries, not a duplicate
+	input := "When,What,Amount\n" +
+		"2024-10-16 09:00,Coffee,1\n" +
+		"2024-10-16 09:00,Coffee,1\n"
+
+	report := runImport(t, store, "csv", mapping, input, now)
+	if want := (Report{Rows: 2, Imported: 2, Created: []string{"Coffee"}}); !sameReport(report, want) {
+		t.Errorf("first import = %+v, want %+v", report, want)
+	}
+	checkValues(t, store, map[string]float64{"Coffee": 2}, map[string]string{"Coffee": ""})
+
+	report = runImport(t, store, "csv", mapping, input, now)
+	want := Report{Rows: 2, Skipped: 2, Issues: []Issue{{Line: 2, Reason: "already imported"}, {Line: 3, Reason: "already imported"}}}
+	if !sameReport(report, want) {
+		t.Errorf("second import = %+v, want %+v", report, want)
+	}
+	checkValues(t, store, map[string]float64{"Coffee": 2}, map[string]string{"Coffee": ""})
+
+	// A file with one more identical row only adds that one
+	report = runImport(t, store, "csv", mapping, input+"2024-10-16 09:00,Coffee,1\n", now)
+	if report.Imported != 1 || report.Skipped != 2 {
+		t.Errorf("third import = %+v, want 1 imported and 2 skipped", report)
+	}
+	checkValues(t, store, map[string]float64{"Coffee": 3}, map[string]string{"Coffee": ""})
+}
+
+func sameReport(a, b Report) bool {
+	if a.Rows != b.Rows || a.Imported != b.Imported || a.Skipped != b.Skipped || a.Rejected != b.Rejected {
+		return false
+	}
+	if strings.Join(a.Created, ",") != strings.Join(b.Created, ",") || len(a.Issues) != len(b.Issues) {
+		return false
+	}
+	for i := range a.Issues {
+		if a.Issues[i] != b.Issues[i] {
+			return false
+		}
+	}
+	return true
+}
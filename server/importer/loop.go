@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qjs/quanti-tea/server/db"
+)
+
+// loopAdapter reads Checkmarks.csv from the CSV export of Loop Habit Tracker:
+// a Date column followed by one column per habit. Yes/no habits hold the
+// state of each day, numerical habits the amount entered that day.
+type loopAdapter struct{}
+
+// Checkmark states written by Loop for yes/no habits
+var loopStates = map[string]string{
+	"-1": "unknown", "UNKNOWN": "unknown",
+	"0": "no", "NO": "no",
+	"1": "auto", "YES_AUTO": "auto",
+	"2": "yes", "YES_MANUAL": "yes",
+	"3": "skip", "SKIP": "skip",
+}
+
+func (loopAdapter) Read(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Loop export: %w", err)
+	}
+	if len(records) == 0 || !strings.EqualFold(strings.TrimSpace(records[0][0]), "date") {
+		return nil, fmt.Errorf("not a Loop Checkmarks.csv, expected a Date column first")
+	}
+	header := records[0]
+
+	// A habit whose column holds anything but checkmark states is numerical
+	numerical := make([]bool, len(header))
+	for _, record := range records[1:] {
+		for i := 1; i < len(record) && i < len(header); i++ {
+			value := strings.TrimSpace(record[i])
+			if _, ok := loopStates[strings.ToUpper(value)]; !ok && value != "" {
+				numerical[i] = true
+			}
+		}
+	}
+
+	var rows []Row
+	for n, record := range records[1:] {
+		row := Row{Line: n + 2}
+		day, err := time.Parse(time.DateOnly, strings.TrimSpace(record[0]))
+		if err != nil {
+			row.Err = fmt.Errorf("invalid date %q", record[0])
+			rows = append(rows, row)
+			continue
+		}
+
+		for i := 1; i < len(record) && i < len(header); i++ {
+			habit := strings.TrimSpace(header[i])
+			value := strings.TrimSpace(record[i])
+			if habit == "" || value == "" {
+				continue
+			}
+
+			if numerical[i] {
+				amount, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					row.Err = fmt.Errorf("invalid amount %q for %s", value, habit)
+					break
+				}
+				if amount <= 0 {
+					continue
+				}
+				row.Entries = append(row.Entries, Entry{
+					Metric:    dailyMetric(habit, "Habit", "units", db.KindCounter),
+					Operation: db.OpIncrement,
+					Value:     amount,
+					At:        day,
+					DateOnly:  true,
+				})
+				continue
+			}
+
+			// Only days checked by hand are entries; Loop fills in the rest
+			if loopStates[strings.ToUpper(value)] != "yes" {
+				continue
+			}
+			row.Entries = append(row.Entries, Entry{
+				Metric:    dailyMetric(habit, "Habit", "done", db.KindBoolean),
+				Operation: db.OpUpdate,
+				Value:     1,
+				At:        day,
+				DateOnly:  true,
+			})
+		}
+		if row.Err == nil && len(row.Entries) == 0 {
+			row.Skip = "no habit checked"
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
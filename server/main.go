@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"net"
 	"os"
@@ -21,17 +22,54 @@ import (
 )
 
 func main() {
+	// Subcommands that work on a database or a running server without
+	// starting the servers
+	if len(os.Args) > 1 {
+		subcommands := map[string]func(args []string) error{
+			"migrate":        runMigrate,
+			"restore":        runRestore,
+			"export":         runExport,
+			"import":         runImport,
+			"import-entries": runImportEntries,
+		}
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				log.Fatalf("%s failed: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
 	// Command-line flags for configuration
 	var (
-		dbPath         = flag.String("db", "kettle.db", "Path to SQLite database file")
+		dbPath         = flag.String("db", "kettle.db", "Path to the database file (SQLite or bbolt)")
+		storeKind      = flag.String("store", "sqlite", "Storage backend: sqlite, bolt or memory")
 		grpcPort       = flag.String("grpc-port", ":50051", "gRPC server port")
 		prometheusAddr = flag.String("prometheus-addr", ":2112", "Prometheus exporter address")
 		webAppPort     = flag.String("webapp-port", ":8005", "Web application port")
+		trashRetention = flag.Duration("trash-retention", 30*24*time.Hour, "How long deleted metrics stay in the trash before being purged (0 keeps them forever)")
+		dayStart       = flag.String("day-start", "00:00", "Time of day (HH:MM) days start at for resets and daily rollups, unless a metric sets its own")
+		timezone       = flag.String("timezone", "", "IANA time zone of -day-start, e.g. Europe/Berlin (default the local zone)")
+		backupDir      = flag.String("backup-dir", "", "Directory for database snapshots; empty disables backups")
+		backupInterval = flag.Duration("backup-interval", 24*time.Hour, "How often a snapshot is written to -backup-dir (0 only backs up on request)")
+		keepDaily      = flag.Int("backup-keep-daily", 7, "Number of days whose newest snapshot is kept")
+		keepWeekly     = flag.Int("backup-keep-weekly", 4, "Number of weeks whose newest snapshot is kept")
+		exportTags     = flag.String("export-tags", "", "Comma separated tag keys exported as Prometheus labels, e.g. person,room")
 	)
 	flag.Parse()
 
+	day, err := db.ParseDayBoundary(*dayStart, *timezone, db.DayBoundary{Location: time.Local})
+	if err != nil {
+		log.Fatalf("Invalid day boundary: %v", err)
+	}
+
+	tagLabels, err := exporter.ParseTagLabels(*exportTags)
+	if err != nil {
+		log.Fatalf("Invalid -export-tags: %v", err)
+	}
+
 	// Initialize Database
-	database, err := db.NewDatabase(*dbPath)
+	database, err := openStore(*storeKind, *dbPath, db.WithDayBoundary(day))
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -40,10 +78,20 @@ func main() {
 	stopChan := make(chan bool)
 
 	// Start the daily reset scheduler
-	go database.StartDailyResetScheduler(stopChan)
+	go db.StartResetScheduler(database, stopChan)
+
+	// Start purging metrics that have outlived the trash retention
+	go db.StartTrashPurger(database, *trashRetention, stopChan)
+
+	// Start writing snapshots into the backup directory
+	var backups *db.Backups
+	if *backupDir != "" {
+		backups = &db.Backups{Dir: *backupDir, KeepDaily: *keepDaily, KeepWeekly: *keepWeekly}
+		go db.StartBackupScheduler(database, backups, *backupInterval, stopChan)
+	}
 
 	// Initialize Prometheus Exporter
-	exporter := exporter.NewExporter(database)
+	exporter := exporter.NewExporter(database, tagLabels)
 	go exporter.Start(*prometheusAddr)
 
 	// Initialize gRPC Server
@@ -53,7 +101,12 @@ func main() {
 	}
 
 	grpcServer := grpc.NewServer()
-	pb.RegisterMetricsServiceServer(grpcServer, grpcSrv.NewMetricsServer(database))
+	metricsServer, err := grpcSrv.NewMetricsServer(database)
+	if err != nil {
+		log.Fatalf("Failed to start the gRPC server: %v", err)
+	}
+	metricsServer.Backups = backups
+	pb.RegisterMetricsServiceServer(grpcServer, metricsServer)
 
 	// Start gRPC server in a separate goroutine
 	go func() {
@@ -101,5 +154,22 @@ func main() {
 		log.Printf("Failed to close gRPC client connection: %v", err)
 	}
 	close(stopChan)
+	if err := database.Close(); err != nil {
+		log.Printf("Failed to close database: %v", err)
+	}
 	log.Println("Servers shut down successfully.")
 }
+
+// openStore opens the storage backend selected with -store
+func openStore(kind, path string, opts ...db.Option) (db.Store, error) {
+	switch kind {
+	case "sqlite":
+		return db.NewDatabase(path, opts...)
+	case "bolt":
+		return db.NewBoltStore(path, opts...)
+	case "memory":
+		return db.NewMemoryStore(opts...), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q, expected sqlite, bolt or memory", kind)
+	}
+}
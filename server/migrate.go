@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/qjs/quanti-tea/server/db"
+)
+
+// runMigrate implements `quanti-tea-steep migrate`, which shows or advances
+// the schema version of a database without starting the servers.
+func runMigrate(args []string) error {
+	latest, err := db.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	var (
+		dbPath = fs.String("db", "kettle.db", "Path to SQLite database file")
+		status = fs.Bool("status", false, "Print the applied and pending migrations and exit")
+		to     = fs.Int("to", latest, "Schema version to migrate to")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s migrate:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	database, err := db.OpenDatabase(*dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	if *status {
+		statuses, err := database.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		version, err := database.SchemaVersion()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Schema version %d (latest %d)\n", version, latest)
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("  %04d %-30s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	}
+
+	if err := database.MigrateTo(*to); err != nil {
+		return err
+	}
+	version, err := database.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Database is at schema version %d\n", version)
+	return nil
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import "io"
+
+// ChunkSize is the most bundle data sent in one DataChunk or ImportDataRequest,
+// well below the default message size limit of gRPC
+const ChunkSize = 32 << 10
+
+// chunkWriter splits everything written to it into chunks of at most ChunkSize
+type chunkWriter func(data []byte) error
+
+func (send chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), ChunkSize)
+		if err := send(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// NewChunkWriter returns a writer that passes the data written to it to send
+// in chunks of at most ChunkSize, for the streams of ExportData and ImportData.
+// send must not keep the slice it is given.
+func NewChunkWriter(send func(data []byte) error) io.Writer {
+	return chunkWriter(send)
+}
+
+// chunkReader reads the chunks returned by recv one after the other
+type chunkReader struct {
+	recv    func() ([]byte, error)
+	pending []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		data, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		r.pending = data
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// NewChunkReader returns a reader over the chunks returned by recv, which
+// returns io.EOF after the last one
+func NewChunkReader(recv func() ([]byte, error)) io.Reader {
+	return &chunkReader{recv: recv}
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// ClientIDKey is the gRPC metadata key that carries the id of the client
+// making a mutation, so Undo and Redo can be scoped to that client
+const ClientIDKey = "quanti-tea-client-id"
+
+// WithClientID returns a context whose outgoing calls carry the client id
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	if clientID == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, ClientIDKey, clientID)
+}
+
+// ClientID returns the client id a call was made with, or an empty string
+func ClientID(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, ClientIDKey)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// NewClientID returns a random client id for a TUI session or a browser
+func NewClientID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,16 @@
+package metrics
+
+// Goal directions, see the direction field of Goal
+const (
+	GoalAtLeast = "at_least"
+	GoalAtMost  = "at_most"
+)
+
+// Ratio returns value divided by the target of the goal the way the server
+// reports it, or 0 for a nil goal
+func (g *Goal) Ratio(value float64) float64 {
+	if g.GetTarget() == 0 {
+		return 0
+	}
+	return value / g.Target
+}
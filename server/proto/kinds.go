@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metric kinds, see the kind field of Metric
+const (
+	KindCounter  = "counter"
+	KindGauge    = "gauge"
+	KindBoolean  = "boolean"
+	KindRating   = "rating"
+	KindDuration = "duration"
+	KindDerived  = "derived"
+)
+
+// Kinds lists the metric kinds in the order clients offer them
+var Kinds = []string{KindGauge, KindCounter, KindBoolean, KindRating, KindDuration, KindDerived}
+
+// ParseValue parses an entry typed for a metric of the given kind: yes or no
+// for booleans, a number or a row of stars ("***") for ratings, a duration
+// such as "1h30m" or a number of seconds for durations, and a number for
+// everything else. Durations are returned in seconds.
+func ParseValue(kind, input string) (float64, error) {
+	input = strings.TrimSpace(input)
+	switch kind {
+	case KindBoolean:
+		switch strings.ToLower(input) {
+		case "y", "yes", "true", "1":
+			return 1, nil
+		case "n", "no", "false", "0":
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%q is not yes or no", input)
+	case KindRating:
+		if stars := strings.Count(input, "*") + strings.Count(input, "★"); stars > 0 && strings.Trim(input, "*★") == "" {
+			return float64(stars), nil
+		}
+	case KindDuration:
+		if d, err := time.ParseDuration(input); err == nil {
+			return d.Seconds(), nil
+		}
+		seconds, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a duration, use e.g. 1h30m", input)
+		}
+		return seconds, nil
+	}
+
+	value, err := strconv.ParseFloat(input, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", input)
+	}
+	return value, nil
+}
+
+// amountPattern matches a number optionally followed by a unit, e.g. "250ml",
+// "2 cups" or "1.5e3 mg"
+var amountPattern = regexp.MustCompile(`^([-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*(\D.*)?$`)
+
+// ParseAmount parses an entry like ParseValue, except that the number of a
+// gauge, a counter or a duration may be followed by a unit such as "250ml",
+// "2 cups" or "90 min". The unit is returned for the server to convert the
+// amount to the unit of the metric, and is empty when none is given.
+func ParseAmount(kind, input string) (float64, string, error) {
+	switch kind {
+	case KindBoolean, KindRating:
+		value, err := ParseValue(kind, input)
+		return value, "", err
+	case KindDuration:
+		if value, err := ParseValue(kind, input); err == nil {
+			return value, "", nil
+		}
+	}
+
+	match := amountPattern.FindStringSubmatch(strings.TrimSpace(input))
+	if match == nil {
+		value, err := ParseValue(kind, input)
+		return value, "", err
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("%q is not a number", input)
+	}
+	return value, strings.TrimSpace(match[2]), nil
+}
+
+// FormatValue renders a value of a metric of the given kind the way
+// ParseValue reads it: yes or no, stars out of the rating's maximum, or a
+// duration like 1h20m
+func FormatValue(kind string, value float64, c *Constraints) string {
+	switch kind {
+	case KindBoolean:
+		if value != 0 {
+			return "yes"
+		}
+		return "no"
+	case KindRating:
+		stars := int(math.Max(0, math.Round(value)))
+		highest := 5
+		if c.GetMax() > 0 {
+			highest = int(c.GetMax())
+		}
+		return strings.Repeat("★", stars) + strings.Repeat("☆", max(0, highest-stars))
+	case KindDuration:
+		d := (time.Duration(value * float64(time.Second))).Round(time.Second).String()
+		// 1h20m0s reads better as 1h20m
+		if strings.HasSuffix(d, "m0s") {
+			d = strings.TrimSuffix(d, "0s")
+		}
+		if strings.HasSuffix(d, "h0m") {
+			d = strings.TrimSuffix(d, "0m")
+		}
+		return d
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
@@ -19,12 +19,31 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MetricsService_AddMetric_FullMethodName       = "/metrics.MetricsService/AddMetric"
-	MetricsService_IncrementMetric_FullMethodName = "/metrics.MetricsService/IncrementMetric"
-	MetricsService_GetMetrics_FullMethodName      = "/metrics.MetricsService/GetMetrics"
-	MetricsService_UpdateMetric_FullMethodName    = "/metrics.MetricsService/UpdateMetric"
-	MetricsService_DecrementMetric_FullMethodName = "/metrics.MetricsService/DecrementMetric"
-	MetricsService_DeleteMetric_FullMethodName    = "/metrics.MetricsService/DeleteMetric"
+	MetricsService_AddMetric_FullMethodName          = "/metrics.MetricsService/AddMetric"
+	MetricsService_IncrementMetric_FullMethodName    = "/metrics.MetricsService/IncrementMetric"
+	MetricsService_GetMetrics_FullMethodName         = "/metrics.MetricsService/GetMetrics"
+	MetricsService_UpdateMetric_FullMethodName       = "/metrics.MetricsService/UpdateMetric"
+	MetricsService_DecrementMetric_FullMethodName    = "/metrics.MetricsService/DecrementMetric"
+	MetricsService_DeleteMetric_FullMethodName       = "/metrics.MetricsService/DeleteMetric"
+	MetricsService_EditMetric_FullMethodName         = "/metrics.MetricsService/EditMetric"
+	MetricsService_GetMetricHistory_FullMethodName   = "/metrics.MetricsService/GetMetricHistory"
+	MetricsService_GetDailyRollups_FullMethodName    = "/metrics.MetricsService/GetDailyRollups"
+	MetricsService_SearchNotes_FullMethodName        = "/metrics.MetricsService/SearchNotes"
+	MetricsService_ListDeletedMetrics_FullMethodName = "/metrics.MetricsService/ListDeletedMetrics"
+	MetricsService_RestoreMetric_FullMethodName      = "/metrics.MetricsService/RestoreMetric"
+	MetricsService_PurgeMetric_FullMethodName        = "/metrics.MetricsService/PurgeMetric"
+	MetricsService_GetGoalProgress_FullMethodName    = "/metrics.MetricsService/GetGoalProgress"
+	MetricsService_GetStreaks_FullMethodName         = "/metrics.MetricsService/GetStreaks"
+	MetricsService_CreateBackup_FullMethodName       = "/metrics.MetricsService/CreateBackup"
+	MetricsService_ExportData_FullMethodName         = "/metrics.MetricsService/ExportData"
+	MetricsService_ImportData_FullMethodName         = "/metrics.MetricsService/ImportData"
+	MetricsService_ImportEntries_FullMethodName      = "/metrics.MetricsService/ImportEntries"
+	MetricsService_Undo_FullMethodName               = "/metrics.MetricsService/Undo"
+	MetricsService_Redo_FullMethodName               = "/metrics.MetricsService/Redo"
+	MetricsService_SetLink_FullMethodName            = "/metrics.MetricsService/SetLink"
+	MetricsService_DeleteLink_FullMethodName         = "/metrics.MetricsService/DeleteLink"
+	MetricsService_GetLinks_FullMethodName           = "/metrics.MetricsService/GetLinks"
+	MetricsService_QueryAggregate_FullMethodName     = "/metrics.MetricsService/QueryAggregate"
 )
 
 // MetricsServiceClient is the client API for MetricsService service.
@@ -37,6 +56,25 @@ type MetricsServiceClient interface {
 	UpdateMetric(ctx context.Context, in *UpdateMetricRequest, opts ...grpc.CallOption) (*UpdateMetricResponse, error)
 	DecrementMetric(ctx context.Context, in *DecrementMetricRequest, opts ...grpc.CallOption) (*DecrementMetricResponse, error)
 	DeleteMetric(ctx context.Context, in *DeleteMetricRequest, opts ...grpc.CallOption) (*DeleteMetricResponse, error)
+	EditMetric(ctx context.Context, in *EditMetricRequest, opts ...grpc.CallOption) (*EditMetricResponse, error)
+	GetMetricHistory(ctx context.Context, in *GetMetricHistoryRequest, opts ...grpc.CallOption) (*GetMetricHistoryResponse, error)
+	GetDailyRollups(ctx context.Context, in *GetDailyRollupsRequest, opts ...grpc.CallOption) (*GetDailyRollupsResponse, error)
+	SearchNotes(ctx context.Context, in *SearchNotesRequest, opts ...grpc.CallOption) (*SearchNotesResponse, error)
+	ListDeletedMetrics(ctx context.Context, in *ListDeletedMetricsRequest, opts ...grpc.CallOption) (*ListDeletedMetricsResponse, error)
+	RestoreMetric(ctx context.Context, in *RestoreMetricRequest, opts ...grpc.CallOption) (*RestoreMetricResponse, error)
+	PurgeMetric(ctx context.Context, in *PurgeMetricRequest, opts ...grpc.CallOption) (*PurgeMetricResponse, error)
+	GetGoalProgress(ctx context.Context, in *GetGoalProgressRequest, opts ...grpc.CallOption) (*GetGoalProgressResponse, error)
+	GetStreaks(ctx context.Context, in *GetStreaksRequest, opts ...grpc.CallOption) (*GetStreaksResponse, error)
+	CreateBackup(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*CreateBackupResponse, error)
+	ExportData(ctx context.Context, in *ExportDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DataChunk], error)
+	ImportData(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportDataRequest, ImportDataResponse], error)
+	ImportEntries(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportEntriesRequest, ImportEntriesResponse], error)
+	Undo(ctx context.Context, in *UndoRequest, opts ...grpc.CallOption) (*UndoResponse, error)
+	Redo(ctx context.Context, in *RedoRequest, opts ...grpc.CallOption) (*RedoResponse, error)
+	SetLink(ctx context.Context, in *SetLinkRequest, opts ...grpc.CallOption) (*SetLinkResponse, error)
+	DeleteLink(ctx context.Context, in *DeleteLinkRequest, opts ...grpc.CallOption) (*DeleteLinkResponse, error)
+	GetLinks(ctx context.Context, in *GetLinksRequest, opts ...grpc.CallOption) (*GetLinksResponse, error)
+	QueryAggregate(ctx context.Context, in *QueryAggregateRequest, opts ...grpc.CallOption) (*QueryAggregateResponse, error)
 }
 
 type metricsServiceClient struct {
@@ -107,6 +145,211 @@ func (c *metricsServiceClient) DeleteMetric(ctx context.Context, in *DeleteMetri
 	return out, nil
 }
 
+func (c *metricsServiceClient) EditMetric(ctx context.Context, in *EditMetricRequest, opts ...grpc.CallOption) (*EditMetricResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditMetricResponse)
+	err := c.cc.Invoke(ctx, MetricsService_EditMetric_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetMetricHistory(ctx context.Context, in *GetMetricHistoryRequest, opts ...grpc.CallOption) (*GetMetricHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMetricHistoryResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetMetricHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetDailyRollups(ctx context.Context, in *GetDailyRollupsRequest, opts ...grpc.CallOption) (*GetDailyRollupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDailyRollupsResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetDailyRollups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) SearchNotes(ctx context.Context, in *SearchNotesRequest, opts ...grpc.CallOption) (*SearchNotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchNotesResponse)
+	err := c.cc.Invoke(ctx, MetricsService_SearchNotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) ListDeletedMetrics(ctx context.Context, in *ListDeletedMetricsRequest, opts ...grpc.CallOption) (*ListDeletedMetricsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDeletedMetricsResponse)
+	err := c.cc.Invoke(ctx, MetricsService_ListDeletedMetrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) RestoreMetric(ctx context.Context, in *RestoreMetricRequest, opts ...grpc.CallOption) (*RestoreMetricResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreMetricResponse)
+	err := c.cc.Invoke(ctx, MetricsService_RestoreMetric_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) PurgeMetric(ctx context.Context, in *PurgeMetricRequest, opts ...grpc.CallOption) (*PurgeMetricResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeMetricResponse)
+	err := c.cc.Invoke(ctx, MetricsService_PurgeMetric_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetGoalProgress(ctx context.Context, in *GetGoalProgressRequest, opts ...grpc.CallOption) (*GetGoalProgressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetGoalProgressResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetGoalProgress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetStreaks(ctx context.Context, in *GetStreaksRequest, opts ...grpc.CallOption) (*GetStreaksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStreaksResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetStreaks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) CreateBackup(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*CreateBackupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateBackupResponse)
+	err := c.cc.Invoke(ctx, MetricsService_CreateBackup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) ExportData(ctx context.Context, in *ExportDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DataChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricsService_ServiceDesc.Streams[0], MetricsService_ExportData_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportDataRequest, DataChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsService_ExportDataClient = grpc.ServerStreamingClient[DataChunk]
+
+func (c *metricsServiceClient) ImportData(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportDataRequest, ImportDataResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricsService_ServiceDesc.Streams[1], MetricsService_ImportData_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportDataRequest, ImportDataResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsService_ImportDataClient = grpc.ClientStreamingClient[ImportDataRequest, ImportDataResponse]
+
+func (c *metricsServiceClient) ImportEntries(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportEntriesRequest, ImportEntriesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricsService_ServiceDesc.Streams[2], MetricsService_ImportEntries_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportEntriesRequest, ImportEntriesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsService_ImportEntriesClient = grpc.ClientStreamingClient[ImportEntriesRequest, ImportEntriesResponse]
+
+func (c *metricsServiceClient) Undo(ctx context.Context, in *UndoRequest, opts ...grpc.CallOption) (*UndoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UndoResponse)
+	err := c.cc.Invoke(ctx, MetricsService_Undo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) Redo(ctx context.Context, in *RedoRequest, opts ...grpc.CallOption) (*RedoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RedoResponse)
+	err := c.cc.Invoke(ctx, MetricsService_Redo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) SetLink(ctx context.Context, in *SetLinkRequest, opts ...grpc.CallOption) (*SetLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLinkResponse)
+	err := c.cc.Invoke(ctx, MetricsService_SetLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) DeleteLink(ctx context.Context, in *DeleteLinkRequest, opts ...grpc.CallOption) (*DeleteLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteLinkResponse)
+	err := c.cc.Invoke(ctx, MetricsService_DeleteLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetLinks(ctx context.Context, in *GetLinksRequest, opts ...grpc.CallOption) (*GetLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLinksResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) QueryAggregate(ctx context.Context, in *QueryAggregateRequest, opts ...grpc.CallOption) (*QueryAggregateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryAggregateResponse)
+	err := c.cc.Invoke(ctx, MetricsService_QueryAggregate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MetricsServiceServer is the server API for MetricsService service.
 // All implementations must embed UnimplementedMetricsServiceServer
 // for forward compatibility.
@@ -117,6 +360,25 @@ type MetricsServiceServer interface {
 	UpdateMetric(context.Context, *UpdateMetricRequest) (*UpdateMetricResponse, error)
 	DecrementMetric(context.Context, *DecrementMetricRequest) (*DecrementMetricResponse, error)
 	DeleteMetric(context.Context, *DeleteMetricRequest) (*DeleteMetricResponse, error)
+	EditMetric(context.Context, *EditMetricRequest) (*EditMetricResponse, error)
+	GetMetricHistory(context.Context, *GetMetricHistoryRequest) (*GetMetricHistoryResponse, error)
+	GetDailyRollups(context.Context, *GetDailyRollupsRequest) (*GetDailyRollupsResponse, error)
+	SearchNotes(context.Context, *SearchNotesRequest) (*SearchNotesResponse, error)
+	ListDeletedMetrics(context.Context, *ListDeletedMetricsRequest) (*ListDeletedMetricsResponse, error)
+	RestoreMetric(context.Context, *RestoreMetricRequest) (*RestoreMetricResponse, error)
+	PurgeMetric(context.Context, *PurgeMetricRequest) (*PurgeMetricResponse, error)
+	GetGoalProgress(context.Context, *GetGoalProgressRequest) (*GetGoalProgressResponse, error)
+	GetStreaks(context.Context, *GetStreaksRequest) (*GetStreaksResponse, error)
+	CreateBackup(context.Context, *CreateBackupRequest) (*CreateBackupResponse, error)
+	ExportData(*ExportDataRequest, grpc.ServerStreamingServer[DataChunk]) error
+	ImportData(grpc.ClientStreamingServer[ImportDataRequest, ImportDataResponse]) error
+	ImportEntries(grpc.ClientStreamingServer[ImportEntriesRequest, ImportEntriesResponse]) error
+	Undo(context.Context, *UndoRequest) (*UndoResponse, error)
+	Redo(context.Context, *RedoRequest) (*RedoResponse, error)
+	SetLink(context.Context, *SetLinkRequest) (*SetLinkResponse, error)
+	DeleteLink(context.Context, *DeleteLinkRequest) (*DeleteLinkResponse, error)
+	GetLinks(context.Context, *GetLinksRequest) (*GetLinksResponse, error)
+	QueryAggregate(context.Context, *QueryAggregateRequest) (*QueryAggregateResponse, error)
 	mustEmbedUnimplementedMetricsServiceServer()
 }
 
@@ -145,6 +407,63 @@ func (UnimplementedMetricsServiceServer) DecrementMetric(context.Context, *Decre
 func (UnimplementedMetricsServiceServer) DeleteMetric(context.Context, *DeleteMetricRequest) (*DeleteMetricResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteMetric not implemented")
 }
+func (UnimplementedMetricsServiceServer) EditMetric(context.Context, *EditMetricRequest) (*EditMetricResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditMetric not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetMetricHistory(context.Context, *GetMetricHistoryRequest) (*GetMetricHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetricHistory not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetDailyRollups(context.Context, *GetDailyRollupsRequest) (*GetDailyRollupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDailyRollups not implemented")
+}
+func (UnimplementedMetricsServiceServer) SearchNotes(context.Context, *SearchNotesRequest) (*SearchNotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchNotes not implemented")
+}
+func (UnimplementedMetricsServiceServer) ListDeletedMetrics(context.Context, *ListDeletedMetricsRequest) (*ListDeletedMetricsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeletedMetrics not implemented")
+}
+func (UnimplementedMetricsServiceServer) RestoreMetric(context.Context, *RestoreMetricRequest) (*RestoreMetricResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreMetric not implemented")
+}
+func (UnimplementedMetricsServiceServer) PurgeMetric(context.Context, *PurgeMetricRequest) (*PurgeMetricResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeMetric not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetGoalProgress(context.Context, *GetGoalProgressRequest) (*GetGoalProgressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGoalProgress not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetStreaks(context.Context, *GetStreaksRequest) (*GetStreaksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStreaks not implemented")
+}
+func (UnimplementedMetricsServiceServer) CreateBackup(context.Context, *CreateBackupRequest) (*CreateBackupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBackup not implemented")
+}
+func (UnimplementedMetricsServiceServer) ExportData(*ExportDataRequest, grpc.ServerStreamingServer[DataChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportData not implemented")
+}
+func (UnimplementedMetricsServiceServer) ImportData(grpc.ClientStreamingServer[ImportDataRequest, ImportDataResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportData not implemented")
+}
+func (UnimplementedMetricsServiceServer) ImportEntries(grpc.ClientStreamingServer[ImportEntriesRequest, ImportEntriesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportEntries not implemented")
+}
+func (UnimplementedMetricsServiceServer) Undo(context.Context, *UndoRequest) (*UndoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Undo not implemented")
+}
+func (UnimplementedMetricsServiceServer) Redo(context.Context, *RedoRequest) (*RedoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Redo not implemented")
+}
+func (UnimplementedMetricsServiceServer) SetLink(context.Context, *SetLinkRequest) (*SetLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLink not implemented")
+}
+func (UnimplementedMetricsServiceServer) DeleteLink(context.Context, *DeleteLinkRequest) (*DeleteLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLink not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetLinks(context.Context, *GetLinksRequest) (*GetLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLinks not implemented")
+}
+func (UnimplementedMetricsServiceServer) QueryAggregate(context.Context, *QueryAggregateRequest) (*QueryAggregateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryAggregate not implemented")
+}
 func (UnimplementedMetricsServiceServer) mustEmbedUnimplementedMetricsServiceServer() {}
 func (UnimplementedMetricsServiceServer) testEmbeddedByValue()                        {}
 
@@ -274,6 +593,319 @@ func _MetricsService_DeleteMetric_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MetricsService_EditMetric_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).EditMetric(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_EditMetric_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).EditMetric(ctx, req.(*EditMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetMetricHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetricHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetMetricHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetMetricHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetMetricHistory(ctx, req.(*GetMetricHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetDailyRollups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDailyRollupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetDailyRollups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetDailyRollups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetDailyRollups(ctx, req.(*GetDailyRollupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_SearchNotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchNotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).SearchNotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_SearchNotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).SearchNotes(ctx, req.(*SearchNotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_ListDeletedMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeletedMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).ListDeletedMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_ListDeletedMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).ListDeletedMetrics(ctx, req.(*ListDeletedMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_RestoreMetric_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).RestoreMetric(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_RestoreMetric_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).RestoreMetric(ctx, req.(*RestoreMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_PurgeMetric_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).PurgeMetric(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_PurgeMetric_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).PurgeMetric(ctx, req.(*PurgeMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetGoalProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGoalProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetGoalProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetGoalProgress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetGoalProgress(ctx, req.(*GetGoalProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetStreaks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStreaksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetStreaks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetStreaks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetStreaks(ctx, req.(*GetStreaksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_CreateBackup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).CreateBackup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_CreateBackup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).CreateBackup(ctx, req.(*CreateBackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_ExportData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricsServiceServer).ExportData(m, &grpc.GenericServerStream[ExportDataRequest, DataChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsService_ExportDataServer = grpc.ServerStreamingServer[DataChunk]
+
+func _MetricsService_ImportData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MetricsServiceServer).ImportData(&grpc.GenericServerStream[ImportDataRequest, ImportDataResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsService_ImportDataServer = grpc.ClientStreamingServer[ImportDataRequest, ImportDataResponse]
+
+func _MetricsService_ImportEntries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MetricsServiceServer).ImportEntries(&grpc.GenericServerStream[ImportEntriesRequest, ImportEntriesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsService_ImportEntriesServer = grpc.ClientStreamingServer[ImportEntriesRequest, ImportEntriesResponse]
+
+func _MetricsService_Undo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).Undo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_Undo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).Undo(ctx, req.(*UndoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_Redo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).Redo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_Redo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).Redo(ctx, req.(*RedoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_SetLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).SetLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_SetLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).SetLink(ctx, req.(*SetLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_DeleteLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).DeleteLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_DeleteLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).DeleteLink(ctx, req.(*DeleteLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetLinks(ctx, req.(*GetLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_QueryAggregate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryAggregateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).QueryAggregate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_QueryAggregate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).QueryAggregate(ctx, req.(*QueryAggregateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MetricsService_ServiceDesc is the grpc.ServiceDesc for MetricsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -305,7 +937,87 @@ var MetricsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteMetric",
 			Handler:    _MetricsService_DeleteMetric_Handler,
 		},
+		{
+			MethodName: "EditMetric",
+			Handler:    _MetricsService_EditMetric_Handler,
+		},
+		{
+			MethodName: "GetMetricHistory",
+			Handler:    _MetricsService_GetMetricHistory_Handler,
+		},
+		{
+			MethodName: "GetDailyRollups",
+			Handler:    _MetricsService_GetDailyRollups_Handler,
+		},
+		{
+			MethodName: "SearchNotes",
+			Handler:    _MetricsService_SearchNotes_Handler,
+		},
+		{
+			MethodName: "ListDeletedMetrics",
+			Handler:    _MetricsService_ListDeletedMetrics_Handler,
+		},
+		{
+			MethodName: "RestoreMetric",
+			Handler:    _MetricsService_RestoreMetric_Handler,
+		},
+		{
+			MethodName: "PurgeMetric",
+			Handler:    _MetricsService_PurgeMetric_Handler,
+		},
+		{
+			MethodName: "GetGoalProgress",
+			Handler:    _MetricsService_GetGoalProgress_Handler,
+		},
+		{
+			MethodName: "GetStreaks",
+			Handler:    _MetricsService_GetStreaks_Handler,
+		},
+		{
+			MethodName: "CreateBackup",
+			Handler:    _MetricsService_CreateBackup_Handler,
+		},
+		{
+			MethodName: "Undo",
+			Handler:    _MetricsService_Undo_Handler,
+		},
+		{
+			MethodName: "Redo",
+			Handler:    _MetricsService_Redo_Handler,
+		},
+		{
+			MethodName: "SetLink",
+			Handler:    _MetricsService_SetLink_Handler,
+		},
+		{
+			MethodName: "DeleteLink",
+			Handler:    _MetricsService_DeleteLink_Handler,
+		},
+		{
+			MethodName: "GetLinks",
+			Handler:    _MetricsService_GetLinks_Handler,
+		},
+		{
+			MethodName: "QueryAggregate",
+			Handler:    _MetricsService_QueryAggregate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportData",
+			Handler:       _MetricsService_ExportData_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportData",
+			Handler:       _MetricsService_ImportData_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ImportEntries",
+			Handler:       _MetricsService_ImportEntries_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "server/proto/metrics.proto",
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/qjs/quanti-tea/server/db"
+)
+
+// runRestore implements `quanti-tea-steep restore <file>`, which replaces the
+// database with a snapshot written by the backup scheduler or CreateBackup.
+// The server must be stopped while it runs.
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	var (
+		dbPath    = fs.String("db", "kettle.db", "Path to the database file to replace")
+		storeKind = fs.String("store", "sqlite", "Storage backend of the snapshot and the database: sqlite or bolt")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s restore [flags] <file>:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one snapshot file")
+	}
+	snapshot := fs.Arg(0)
+
+	switch *storeKind {
+	case "sqlite":
+		version, err := db.CheckSnapshot(snapshot)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Snapshot is at schema version %d\n", version)
+
+		// A leftover journal would be replayed into the restored file
+		for _, suffix := range []string{"-journal", "-wal"} {
+			if _, err := os.Stat(*dbPath + suffix); err == nil {
+				return fmt.Errorf("%s exists, stop the server before restoring", *dbPath+suffix)
+			}
+		}
+	case "bolt":
+		if err := db.CheckBoltSnapshot(snapshot); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown store %q, expected sqlite or bolt", *storeKind)
+	}
+
+	if err := swapInSnapshot(snapshot, *dbPath); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s from %s\n", *dbPath, snapshot)
+	return nil
+}
+
+// swapInSnapshot copies snapshot next to dbPath and renames it over the
+// database, so the database is either the old file or the complete snapshot.
+// The replaced database is kept as dbPath.bak.
+func swapInSnapshot(snapshot, dbPath string) error {
+	src, err := os.Open(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dbPath), filepath.Base(dbPath)+".restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to flush snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		// A hard link keeps the old file without ever leaving dbPath missing
+		os.Remove(dbPath + ".bak")
+		if err := os.Link(dbPath, dbPath+".bak"); err != nil {
+			return fmt.Errorf("failed to keep the current database: %w", err)
+		}
+		fmt.Printf("Kept the replaced database as %s\n", dbPath+".bak")
+	}
+	if err := os.Rename(tmp.Name(), dbPath); err != nil {
+		return fmt.Errorf("failed to move snapshot into place: %w", err)
+	}
+	return nil
+}
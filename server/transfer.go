@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/qjs/quanti-tea/server/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// dialServer connects to the gRPC server of a running quanti-tea-steep
+func dialServer(addr string) (pb.MetricsServiceClient, *grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	return pb.NewMetricsServiceClient(conn), conn, nil
+}
+
+// formatOf returns the bundle format named by flag, or else the one matching
+// the extension of path
+func formatOf(flag, path string) string {
+	if flag != "" {
+		return flag
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return "csv"
+	}
+	return "json"
+}
+
+// openInput opens the file named by path, or standard input for -
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// upload streams in to the server in chunks through send, which is told
+// whether a chunk is the first of the stream and so carries the options. An
+// empty input is sent as one empty chunk so the options still arrive.
+func upload(in io.Reader, send func(data []byte, first bool) error) error {
+	first := true
+	w := pb.NewChunkWriter(func(data []byte) error {
+		err := send(data, first)
+		first = false
+		return err
+	})
+	// A server that stops reading early reports why in the response
+	if _, err := io.Copy(w, in); err != nil && err != io.EOF {
+		return err
+	}
+	if first {
+		if err := send(nil, true); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
+
+// runExport implements `quanti-tea-steep export`, which writes every metric
+// and its history from a running server to a file
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	var (
+		server = fs.String("server", "localhost:50051", "gRPC server address in the format ip:port")
+		format = fs.String("format", "", "Bundle format: json or csv (default from the extension of -o, else json)")
+		output = fs.String("o", "", "File to write the bundle to (default standard output)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s export:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	client, conn, err := dialServer(*server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := client.ExportData(context.Background(), &pb.ExportDataRequest{Format: formatOf(*format, *output)})
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(chunk.Data); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// runImport implements `quanti-tea-steep import <file>`, which loads a bundle
+// written by export into a running server
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	var (
+		server = fs.String("server", "localhost:50051", "gRPC server address in the format ip:port")
+		format = fs.String("format", "", "Bundle format: json or csv (default from the extension of the file, else json)")
+		policy = fs.String("policy", "skip", "What to do with metrics that already exist: skip, overwrite or merge")
+		dryRun = fs.Bool("dry-run", false, "Report what would be imported without changing anything")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s import [flags] <file>:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one bundle file, or - for standard input")
+	}
+	path := fs.Arg(0)
+
+	in, err := openInput(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	client, conn, err := dialServer(*server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := client.ImportData(context.Background())
+	if err != nil {
+		return err
+	}
+	err = upload(in, func(data []byte, first bool) error {
+		req := &pb.ImportDataRequest{Data: data}
+		if first {
+			req.Format, req.ConflictPolicy, req.DryRun = formatOf(*format, path), *policy, *dryRun
+		}
+		return stream.Send(req)
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Results {
+		if r.Outcome == "failed" && r.MetricName == "" {
+			// A link of the bundle, named in the error
+			fmt.Printf("  %-11s %s\n", r.Outcome, r.Error)
+			continue
+		}
+		if r.Outcome == "failed" {
+			fmt.Printf("  %-11s %s: %s\n", r.Outcome, r.MetricName, r.Error)
+			continue
+		}
+		fmt.Printf("  %-11s %s (%d events, %d rollups)\n", r.Outcome, r.MetricName, r.Events, r.Rollups)
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s", resp.Message)
+	}
+	fmt.Println(resp.Message)
+	return nil
+}
+
+// runImportEntries implements `quanti-tea-steep import-entries <file>`, which
+// loads the export of another self-tracking app into a running server
+func runImportEntries(args []string) error {
+	fs := flag.NewFlagSet("import-entries", flag.ExitOnError)
+	var (
+		server  = fs.String("server", "localhost:50051", "gRPC server address in the format ip:port")
+		source  = fs.String("source", "", "App the file was exported from: loop, daylio or csv")
+		mapping = fs.String("mapping", "", "JSON file describing the columns of a csv source")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s import-entries -source <app> [flags] <file>:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *source == "" {
+		fs.Usage()
+		return fmt.Errorf("expected a -source and exactly one file, or - for standard input")
+	}
+	var mappingData []byte
+	if *mapping != "" {
+		data, err := os.ReadFile(*mapping)
+		if err != nil {
+			return err
+		}
+		mappingData = data
+	}
+
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	client, conn, err := dialServer(*server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := client.ImportEntries(context.Background())
+	if err != nil {
+		return err
+	}
+	err = upload(in, func(data []byte, first bool) error {
+		req := &pb.ImportEntriesRequest{Data: data}
+		if first {
+			req.Source, req.Mapping = *source, mappingData
+		}
+		return stream.Send(req)
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s", resp.Message)
+	}
+	for _, issue := range resp.Issues {
+		outcome := "skipped"
+		if issue.Rejected {
+			outcome = "rejected"
+		}
+		fmt.Printf("  line %-6d %-8s %s\n", issue.Line, outcome, issue.Reason)
+	}
+	if len(resp.CreatedMetrics) > 0 {
+		fmt.Printf("Created %s.\n", strings.Join(resp.CreatedMetrics, ", "))
+	}
+	fmt.Println(resp.Message)
+	return nil
+}
@@ -3,11 +3,16 @@ package webapp
 import (
 	"context"
 	"fmt"
+	"html/template"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/qjs/quanti-tea/server/db"
 	pb "github.com/qjs/quanti-tea/server/proto"
 )
 
@@ -21,6 +26,15 @@ type WebApp struct {
 // NewWebApp initializes the web application with routes and templates
 func NewWebApp(grpcClient pb.MetricsServiceClient) *WebApp {
 	router := gin.Default()
+	router.SetFuncMap(template.FuncMap{
+		"formatValue": pb.FormatValue,
+		"stars":       stars,
+		"percent":     percent,
+		"goalMet":     goalMet,
+		"tagList":     tagList,
+		"formatTime":  formatTime,
+		"formatEvent": formatEvent,
+	})
 	router.LoadHTMLGlob("server/webapp/templates/*")
 
 	app := &WebApp{
@@ -37,9 +51,44 @@ func (app *WebApp) setupRoutes() {
 	app.Router.GET("/", app.getMetrics)
 	app.Router.POST("/add", app.addMetric)
 	app.Router.POST("/delete", app.deleteMetric)
+	app.Router.GET("/edit", app.getEditMetric)
+	app.Router.POST("/edit", app.editMetric)
 	app.Router.POST("/update", app.updateMetric)
 	app.Router.POST("/increment", app.incrementMetric)
 	app.Router.POST("/decrement", app.decrementMetric)
+	app.Router.GET("/metric", app.getMetricDetail)
+	app.Router.GET("/rollups", app.getRollups)
+	app.Router.GET("/trash", app.getTrash)
+	app.Router.POST("/restore", app.restoreMetric)
+	app.Router.POST("/purge", app.purgeMetric)
+	app.Router.POST("/undo", app.undo)
+	app.Router.POST("/redo", app.redo)
+	app.Router.GET("/links", app.getLinks)
+	app.Router.POST("/links", app.setLink)
+	app.Router.POST("/links/delete", app.deleteLink)
+	app.Router.GET("/api/aggregate", app.getAggregate)
+}
+
+// clientIDCookie names the cookie that identifies a browser, so the undo
+// banner only reverts what was done in that browser
+const clientIDCookie = "quanti_tea_client"
+
+// clientID returns the id of the browser making the request, setting a new one
+// on its first mutation
+func clientID(c *gin.Context) string {
+	if id, err := c.Cookie(clientIDCookie); err == nil && id != "" {
+		return id
+	}
+	id := pb.NewClientID()
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(clientIDCookie, id, 365*24*60*60, "/", "", false, true)
+	return id
+}
+
+// mutationContext returns the context of an RPC that changes the metrics,
+// carrying the client id of the browser for Undo and Redo
+func mutationContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(pb.WithClientID(context.Background(), clientID(c)), 5*time.Second)
 }
 
 // getMetrics handles GET requests to display all metrics
@@ -52,6 +101,7 @@ func (app *WebApp) getMetrics(c *gin.Context) {
 
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"Metrics": metrics,
+		"Tags":    c.Query("tags"),
 	})
 }
 
@@ -60,7 +110,12 @@ func (app *WebApp) addMetric(c *gin.Context) {
 	metricName := c.PostForm("metric_name")
 	metricType := c.PostForm("metric_type")
 	metricUnit := c.PostForm("metric_unit")
-	resetDaily := c.PostForm("reset_daily") == "on"
+	kind := c.PostForm("kind")
+	reset := resetFormFromPost(c)
+	dayStart := strings.TrimSpace(c.PostForm("day_start"))
+	timezone := strings.TrimSpace(c.PostForm("timezone"))
+	tags := strings.TrimSpace(c.PostForm("tags"))
+	formula := strings.TrimSpace(c.PostForm("formula"))
 
 	// Validate input
 	if metricName == "" || metricType == "" {
@@ -72,14 +127,19 @@ func (app *WebApp) addMetric(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := mutationContext(c)
 	defer cancel()
 
 	req := &pb.AddMetricRequest{
-		MetricName: metricName,
-		Type:       metricType,
-		Unit:       metricUnit,
-		ResetDaily: resetDaily,
+		MetricName:  metricName,
+		Type:        metricType,
+		Unit:        metricUnit,
+		Kind:        kind,
+		ResetPolicy: reset.policy(),
+		DayStart:    dayStart,
+		Timezone:    timezone,
+		Tags:        tags,
+		Formula:     formula,
 	}
 
 	resp, err := app.GRPCClient.AddMetric(ctx, req)
@@ -112,10 +172,11 @@ func (app *WebApp) addMetric(c *gin.Context) {
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"Metrics": metrics,
 		"Message": "Metric added successfully.",
+		"Undo":    true,
 	})
 }
 
-// addMetric handles POST requests to add a new metric
+// deleteMetric handles POST requests to move a metric to the trash
 func (app *WebApp) deleteMetric(c *gin.Context) {
 	metricName := c.PostForm("metric_name")
 
@@ -129,7 +190,7 @@ func (app *WebApp) deleteMetric(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := mutationContext(c)
 	defer cancel()
 
 	req := &pb.DeleteMetricRequest{
@@ -165,32 +226,289 @@ func (app *WebApp) deleteMetric(c *gin.Context) {
 
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"Metrics": metrics,
-		"Message": "Metric deleted successfully.",
+		"Message": "Metric moved to the trash.",
+		"Undo":    true,
+	})
+}
+
+// getEditMetric handles GET requests to display the edit form of a metric
+func (app *WebApp) getEditMetric(c *gin.Context) {
+	metricName := c.Query("metric_name")
+
+	metrics, err := app.fetchMetrics(c)
+	if err != nil {
+		// Error already handled in fetchMetrics
+		return
+	}
+
+	for _, metric := range metrics {
+		if metric.MetricName == metricName {
+			c.HTML(http.StatusOK, "edit.html", gin.H{
+				"Metric": metric,
+				"Reset":  resetFormFromPolicy(metric.ResetPolicy),
+			})
+			return
+		}
+	}
+
+	c.HTML(http.StatusNotFound, "index.html", gin.H{
+		"Metrics": metrics,
+		"Error":   fmt.Sprintf("Metric '%s' does not exist.", metricName),
+	})
+}
+
+// editMetric handles POST requests to rename a metric or change its settings
+func (app *WebApp) editMetric(c *gin.Context) {
+	metricName := c.PostForm("metric_name")
+	newName := c.PostForm("new_name")
+	metricType := c.PostForm("metric_type")
+	metricUnit := c.PostForm("metric_unit")
+	kind := c.PostForm("kind")
+	reset := resetFormFromPost(c)
+	dayStart := strings.TrimSpace(c.PostForm("day_start"))
+	timezone := strings.TrimSpace(c.PostForm("timezone"))
+	constraints, constraintsErr := constraintsFromPost(c)
+	goal, goalErr := goalFromPost(c, kind)
+	restDays := strings.TrimSpace(c.PostForm("rest_days"))
+	tags := strings.TrimSpace(c.PostForm("tags"))
+	formula := strings.TrimSpace(c.PostForm("formula"))
+	keepAlias := c.PostForm("keep_alias") == "on"
+
+	// The form is shown again with the submitted values if the edit fails
+	submitted := &pb.Metric{
+		MetricName:  metricName,
+		Type:        metricType,
+		Unit:        metricUnit,
+		Kind:        kind,
+		ResetPolicy: reset.policy(),
+		DayStart:    dayStart,
+		Timezone:    timezone,
+		Constraints: constraints,
+		Goal:        goal,
+		RestDays:    restDays,
+		Tags:        tags,
+		Formula:     formula,
+	}
+
+	// Validate input
+	if metricName == "" || newName == "" || metricType == "" {
+		c.HTML(http.StatusBadRequest, "edit.html", gin.H{
+			"Metric":  submitted,
+			"NewName": newName,
+			"Reset":   reset,
+			"Error":   "Metric name and type are required.",
+		})
+		return
+	}
+	for _, err := range []error{constraintsErr, goalErr} {
+		if err != nil {
+			c.HTML(http.StatusBadRequest, "edit.html", gin.H{
+				"Metric":  submitted,
+				"NewName": newName,
+				"Reset":   reset,
+				"Error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.EditMetricRequest{
+		MetricName:  metricName,
+		NewName:     newName,
+		Type:        metricType,
+		Unit:        metricUnit,
+		Kind:        kind,
+		ResetPolicy: reset.policy(),
+		KeepAlias:   keepAlias,
+		DayStart:    &dayStart,
+		Timezone:    &timezone,
+		Constraints: constraints,
+		Goal:        goal,
+		RestDays:    &restDays,
+		Tags:        &tags,
+		Formula:     formula,
+	}
+
+	resp, err := app.GRPCClient.EditMetric(ctx, req)
+	if err != nil {
+		log.Printf("EditMetric RPC failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "edit.html", gin.H{
+			"Metric":  submitted,
+			"NewName": newName,
+			"Reset":   reset,
+			"Error":   fmt.Sprintf("Failed to edit metric: %v", err),
+		})
+		return
+	}
+
+	if !resp.Success {
+		c.HTML(http.StatusBadRequest, "edit.html", gin.H{
+			"Metric":  submitted,
+			"NewName": newName,
+			"Reset":   reset,
+			"Error":   resp.Message,
+		})
+		return
+	}
+
+	// Fetch updated metrics
+	metrics, err := app.fetchMetrics(c)
+	if err != nil {
+		// Error already handled in fetchMetrics
+		return
+	}
+
+	c.HTML(http.StatusOK, "index.html", gin.H{
+		"Metrics": metrics,
+		"Message": "Metric edited successfully.",
 	})
 }
 
+// constraintsFromPost reads the constraint fields of the edit form. Empty
+// bounds and step leave the metric unconstrained in that respect.
+func constraintsFromPost(c *gin.Context) (*pb.Constraints, error) {
+	constraints := &pb.Constraints{
+		IntegerOnly:   c.PostForm("integer_only") == "on",
+		AllowNegative: c.PostForm("allow_negative") == "on",
+	}
+
+	fields := []struct {
+		name, label string
+		dest        **float64
+	}{
+		{"min_value", "minimum", &constraints.Min},
+		{"max_value", "maximum", &constraints.Max},
+	}
+	for _, field := range fields {
+		value := strings.TrimSpace(c.PostForm(field.name))
+		if value == "" {
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return constraints, fmt.Errorf("Invalid %s %q.", field.label, value)
+		}
+		*field.dest = &number
+	}
+
+	if value := strings.TrimSpace(c.PostForm("step")); value != "" {
+		step, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return constraints, fmt.Errorf("Invalid step %q.", value)
+		}
+		constraints.Step = step
+	}
+	return constraints, nil
+}
+
+// goalFromPost reads the goal fields of the edit form. The target is entered
+// the way values of kind are, e.g. 30m for a duration. No direction removes
+// the goal.
+func goalFromPost(c *gin.Context, kind string) (*pb.Goal, error) {
+	direction := c.PostForm("goal_direction")
+	if direction == "" || direction == "none" {
+		return &pb.Goal{}, nil
+	}
+
+	goal := &pb.Goal{Direction: direction}
+	target, err := pb.ParseValue(kind, c.PostForm("goal_target"))
+	if err != nil {
+		return goal, fmt.Errorf("Invalid goal target: %v.", err)
+	}
+	goal.Target = target
+	return goal, nil
+}
+
+// resetForm holds the reset policy fields of the add and edit forms
+type resetForm struct {
+	Kind      string // none, hourly, daily, weekly, monthly or cron
+	WeekStart string // Lowercase day name, used by weekly resets
+	Cron      string // Expression, used by cron resets
+}
+
+// resetFormFromPolicy fills the form fields from the text form of a reset policy
+func resetFormFromPolicy(policy string) resetForm {
+	kind, arg, _ := strings.Cut(policy, ":")
+	form := resetForm{Kind: kind, WeekStart: "monday"}
+	switch kind {
+	case "":
+		form.Kind = "none"
+	case "weekly":
+		if arg != "" {
+			form.WeekStart = arg
+		}
+	case "cron":
+		form.Cron = arg
+	}
+	return form
+}
+
+// resetFormFromPost reads the reset policy fields of a submitted form
+func resetFormFromPost(c *gin.Context) resetForm {
+	return resetForm{
+		Kind:      c.PostForm("reset_kind"),
+		WeekStart: c.PostForm("week_start"),
+		Cron:      strings.TrimSpace(c.PostForm("reset_cron")),
+	}
+}
+
+// policy returns the text form of the reset policy sent to the server
+func (f resetForm) policy() string {
+	switch f.Kind {
+	case "weekly":
+		return "weekly:" + f.WeekStart
+	case "cron":
+		return "cron:" + f.Cron
+	case "":
+		return "none"
+	default:
+		return f.Kind
+	}
+}
+
 // updateMetric handles POST requests to update a metric's value
 func (app *WebApp) updateMetric(c *gin.Context) {
 	metricName := c.PostForm("metric_name")
-	newValueStr := c.PostForm("new_value")
 
-	var newValue float64
-	_, err := fmt.Sscanf(newValueStr, "%f", &newValue)
+	// Values are entered the way the kind of the metric reads them, e.g.
+	// yes/no or 1h30m, and numbers may carry a unit such as 250ml
+	newValue, unit, err := pb.ParseAmount(c.PostForm("kind"), c.PostForm("new_value"))
 	if err != nil {
 		metrics, _ := app.fetchMetrics(c)
 		c.HTML(http.StatusBadRequest, "index.html", gin.H{
 			"Metrics": metrics,
-			"Error":   "Invalid value for update.",
+			"Error":   fmt.Sprintf("Invalid value for update: %v.", err),
 		})
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	occurredAt, err := occurredAtFromForm(c)
+	if err != nil {
+		metrics, _ := app.fetchMetrics(c)
+		c.HTML(http.StatusBadRequest, "index.html", gin.H{
+			"Metrics": metrics,
+			"Error":   "Invalid date/time for entry.",
+		})
+		return
+	}
+
+	ctx, cancel := mutationContext(c)
 	defer cancel()
 
+	// A value in another unit is checked once the server converted it
+	if unit == "" && app.rejectEntry(c, metricName, func(float64) float64 { return newValue }) {
+		return
+	}
+
 	req := &pb.UpdateMetricRequest{
 		MetricName: metricName,
 		NewValue:   newValue,
+		Unit:       unit,
+		OccurredAt: occurredAt,
+		Note:       c.PostForm("note"),
 	}
 
 	resp, err := app.GRPCClient.UpdateMetric(ctx, req)
@@ -223,6 +541,7 @@ func (app *WebApp) updateMetric(c *gin.Context) {
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"Metrics": metrics,
 		"Message": "Metric updated successfully.",
+		"Undo":    true,
 	})
 }
 
@@ -239,12 +558,48 @@ func (app *WebApp) incrementMetric(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// The optional amount is how much time a duration adds or how much to
+	// add to a number, possibly in another unit such as 250ml; without one
+	// metrics count up by 1
+	increment, unit := 1.0, ""
+	if amount := strings.TrimSpace(c.PostForm("amount")); amount != "" {
+		parsed, parsedUnit, err := pb.ParseAmount(c.PostForm("kind"), amount)
+		if err != nil {
+			metrics, _ := app.fetchMetrics(c)
+			c.HTML(http.StatusBadRequest, "index.html", gin.H{
+				"Metrics": metrics,
+				"Error":   fmt.Sprintf("Invalid amount for increment: %v.", err),
+			})
+			return
+		}
+		increment, unit = parsed, parsedUnit
+	}
+
+	occurredAt, err := occurredAtFromForm(c)
+	if err != nil {
+		metrics, _ := app.fetchMetrics(c)
+		c.HTML(http.StatusBadRequest, "index.html", gin.H{
+			"Metrics": metrics,
+			"Error":   "Invalid date/time for entry.",
+		})
+		return
+	}
+
+	ctx, cancel := mutationContext(c)
 	defer cancel()
 
+	// A backdated entry applies to an archived value and an entry in another
+	// unit is converted, the server checks those
+	if occurredAt == "" && unit == "" && app.rejectEntry(c, metricName, func(value float64) float64 { return value + increment }) {
+		return
+	}
+
 	req := &pb.IncrementMetricRequest{
 		MetricName: metricName,
-		Increment:  1, // Increment by 1, not all metrics are a +1 incrementer, TODO: make it an adjustable implementation
+		Increment:  increment,
+		Unit:       unit,
+		OccurredAt: occurredAt,
+		Note:       c.PostForm("note"),
 	}
 
 	resp, err := app.GRPCClient.IncrementMetric(ctx, req)
@@ -277,6 +632,7 @@ func (app *WebApp) incrementMetric(c *gin.Context) {
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"Metrics": metrics,
 		"Message": "Metric incremented successfully.",
+		"Undo":    true,
 	})
 }
 
@@ -293,12 +649,46 @@ func (app *WebApp) decrementMetric(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Like for increments, the optional amount may be in another unit
+	decrement, unit := 1.0, ""
+	if amount := strings.TrimSpace(c.PostForm("amount")); amount != "" {
+		parsed, parsedUnit, err := pb.ParseAmount(c.PostForm("kind"), amount)
+		if err != nil {
+			metrics, _ := app.fetchMetrics(c)
+			c.HTML(http.StatusBadRequest, "index.html", gin.H{
+				"Metrics": metrics,
+				"Error":   fmt.Sprintf("Invalid amount for decrement: %v.", err),
+			})
+			return
+		}
+		decrement, unit = parsed, parsedUnit
+	}
+
+	occurredAt, err := occurredAtFromForm(c)
+	if err != nil {
+		metrics, _ := app.fetchMetrics(c)
+		c.HTML(http.StatusBadRequest, "index.html", gin.H{
+			"Metrics": metrics,
+			"Error":   "Invalid date/time for entry.",
+		})
+		return
+	}
+
+	ctx, cancel := mutationContext(c)
 	defer cancel()
 
+	// A backdated entry applies to an archived value and an entry in another
+	// unit is converted, the server checks those
+	if occurredAt == "" && unit == "" && app.rejectEntry(c, metricName, func(value float64) float64 { return value - decrement }) {
+		return
+	}
+
 	req := &pb.DecrementMetricRequest{
 		MetricName: metricName,
-		Decrement:  1, // Decrement by 1
+		Decrement:  decrement,
+		Unit:       unit,
+		OccurredAt: occurredAt,
+		Note:       c.PostForm("note"),
 	}
 
 	resp, err := app.GRPCClient.DecrementMetric(ctx, req)
@@ -331,15 +721,597 @@ func (app *WebApp) decrementMetric(c *gin.Context) {
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"Metrics": metrics,
 		"Message": "Metric decremented successfully.",
+		"Undo":    true,
+	})
+}
+
+// detailEntries is the number of recent entries and notes the detail page of
+// a metric shows
+const detailEntries = 20
+
+// getMetricDetail handles GET requests to display a metric with its most
+// recent entries and notes. The query, start_date and end_date parameters
+// search its notes.
+func (app *WebApp) getMetricDetail(c *gin.Context) {
+	metricName := c.Query("metric_name")
+
+	metrics, err := app.fetchMetrics(c)
+	if err != nil {
+		// Error already handled in fetchMetrics
+		return
+	}
+
+	var metric *pb.Metric
+	for _, m := range metrics {
+		if m.MetricName == metricName {
+			metric = m
+		}
+	}
+	if metric == nil {
+		c.HTML(http.StatusNotFound, "index.html", gin.H{
+			"Metrics": metrics,
+			"Error":   fmt.Sprintf("Metric '%s' does not exist.", metricName),
+		})
+		return
+	}
+
+	search := gin.H{
+		"Query":     c.Query("query"),
+		"StartDate": c.Query("start_date"),
+		"EndDate":   c.Query("end_date"),
+	}
+	data := gin.H{"Metric": metric, "Search": search}
+
+	req := &pb.SearchNotesRequest{MetricName: metricName, Query: c.Query("query"), Limit: detailEntries}
+	req.Start, err = dayStart(c.Query("start_date"), 0)
+	if err == nil {
+		// The end date is inclusive, so the search runs until the day after it
+		req.End, err = dayStart(c.Query("end_date"), 1)
+	}
+	if err != nil {
+		data["Error"] = fmt.Sprintf("Invalid date: %v.", err)
+		c.HTML(http.StatusBadRequest, "metric.html", data)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	history, err := app.GRPCClient.GetMetricHistory(ctx, &pb.GetMetricHistoryRequest{MetricName: metricName, Limit: detailEntries})
+	if err != nil {
+		log.Printf("GetMetricHistory RPC failed: %v", err)
+		data["Error"] = fmt.Sprintf("Failed to fetch the history: %v", err)
+		c.HTML(http.StatusInternalServerError, "metric.html", data)
+		return
+	}
+	data["History"] = history.Events
+
+	notes, err := app.GRPCClient.SearchNotes(ctx, req)
+	if err != nil {
+		log.Printf("SearchNotes RPC failed: %v", err)
+		data["Error"] = fmt.Sprintf("Failed to search the notes: %v", err)
+		c.HTML(http.StatusBadRequest, "metric.html", data)
+		return
+	}
+	data["Notes"] = notes.Events
+
+	c.HTML(http.StatusOK, "metric.html", data)
+}
+
+// getRollups handles GET requests to display archived daily values
+func (app *WebApp) getRollups(c *gin.Context) {
+	filter := gin.H{
+		"MetricName": c.Query("metric_name"),
+		"StartDate":  c.Query("start_date"),
+		"EndDate":    c.Query("end_date"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.GetDailyRollupsRequest{
+		MetricName: c.Query("metric_name"),
+		StartDate:  c.Query("start_date"),
+		EndDate:    c.Query("end_date"),
+	}
+
+	resp, err := app.GRPCClient.GetDailyRollups(ctx, req)
+	if err != nil {
+		log.Printf("GetDailyRollups RPC failed: %v", err)
+		c.HTML(http.StatusBadRequest, "rollups.html", gin.H{
+			"Filter": filter,
+			"Error":  fmt.Sprintf("Failed to fetch rollups: %v", err),
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "rollups.html", gin.H{
+		"Filter":  filter,
+		"Rollups": resp.Rollups,
+	})
+}
+
+// aggregateGroup is a group of an aggregate in the JSON form of /api/aggregate
+type aggregateGroup struct {
+	MetricName  string             `json:"metric_name,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Tags        string             `json:"tags,omitempty"`
+	Bucket      string             `json:"bucket,omitempty"`
+	Count       int64              `json:"count"`
+	Sum         float64            `json:"sum"`
+	Avg         float64            `json:"avg"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Percentiles map[string]float64 `json:"percentiles,omitempty"` // Keyed by percentile, e.g. "90"
+}
+
+// getAggregate handles GET requests to /api/aggregate, answering with the
+// groups of the QueryAggregate RPC as JSON. Its query parameters carry the
+// fields of the request; lists such as group_by and percentiles can be
+// repeated or separated by commas.
+func (app *WebApp) getAggregate(c *gin.Context) {
+	req := &pb.QueryAggregateRequest{
+		MetricNames: queryList(c, "metric_name"),
+		Type:        c.Query("type"),
+		Tags:        c.Query("tags"),
+		Start:       c.Query("start"),
+		End:         c.Query("end"),
+		Field:       c.Query("field"),
+		GroupBy:     queryList(c, "group_by"),
+		Bucket:      c.Query("bucket"),
+	}
+	for _, p := range queryList(c, "percentiles") {
+		value, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid percentile %q", p)})
+			return
+		}
+		req.Percentiles = append(req.Percentiles, value)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := app.GRPCClient.QueryAggregate(ctx, req)
+	if err != nil {
+		log.Printf("QueryAggregate RPC failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to aggregate: %v", err)})
+		return
+	}
+
+	groups := make([]aggregateGroup, 0, len(resp.Groups))
+	for _, g := range resp.Groups {
+		group := aggregateGroup{
+			MetricName: g.MetricName,
+			Type:       g.Type,
+			Tags:       g.Tags,
+			Bucket:     g.Bucket,
+			Count:      g.Count,
+			Sum:        g.Sum,
+			Avg:        g.Avg,
+			Min:        g.Min,
+			Max:        g.Max,
+		}
+		for i, value := range g.Percentiles {
+			if group.Percentiles == nil {
+				group.Percentiles = make(map[string]float64)
+			}
+			group.Percentiles[strconv.FormatFloat(req.Percentiles[i], 'f', -1, 64)] = value
+		}
+		groups = append(groups, group)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"groups": groups})
+}
+
+// queryList returns the values of a query parameter that may be repeated or
+// hold several values separated by commas
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// getTrash handles GET requests to display the deleted metrics
+func (app *WebApp) getTrash(c *gin.Context) {
+	app.renderTrash(c, http.StatusOK, gin.H{})
+}
+
+// restoreMetric handles POST requests to move a metric out of the trash
+func (app *WebApp) restoreMetric(c *gin.Context) {
+	metricName := c.PostForm("metric_name")
+	if metricName == "" {
+		app.renderTrash(c, http.StatusBadRequest, gin.H{"Error": "Metric name is required for restore."})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := app.GRPCClient.RestoreMetric(ctx, &pb.RestoreMetricRequest{MetricName: metricName})
+	if err != nil {
+		log.Printf("RestoreMetric RPC failed: %v", err)
+		app.renderTrash(c, http.StatusInternalServerError, gin.H{"Error": fmt.Sprintf("Failed to restore metric: %v", err)})
+		return
+	}
+
+	if !resp.Success {
+		app.renderTrash(c, http.StatusBadRequest, gin.H{"Error": resp.Message})
+		return
+	}
+
+	app.renderTrash(c, http.StatusOK, gin.H{"Message": "Metric restored successfully."})
+}
+
+// purgeMetric handles POST requests to permanently remove a metric from the trash
+func (app *WebApp) purgeMetric(c *gin.Context) {
+	metricName := c.PostForm("metric_name")
+	if metricName == "" {
+		app.renderTrash(c, http.StatusBadRequest, gin.H{"Error": "Metric name is required for purge."})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := app.GRPCClient.PurgeMetric(ctx, &pb.PurgeMetricRequest{MetricName: metricName})
+	if err != nil {
+		log.Printf("PurgeMetric RPC failed: %v", err)
+		app.renderTrash(c, http.StatusInternalServerError, gin.H{"Error": fmt.Sprintf("Failed to purge metric: %v", err)})
+		return
+	}
+
+	if !resp.Success {
+		app.renderTrash(c, http.StatusBadRequest, gin.H{"Error": resp.Message})
+		return
+	}
+
+	app.renderTrash(c, http.StatusOK, gin.H{"Message": "Metric purged permanently."})
+}
+
+// undo handles POST requests to revert the most recent change made in this browser
+func (app *WebApp) undo(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := app.GRPCClient.Undo(ctx, &pb.UndoRequest{ClientId: clientID(c)})
+	if err != nil {
+		log.Printf("Undo RPC failed: %v", err)
+		metrics, _ := app.fetchMetrics(c)
+		c.HTML(http.StatusInternalServerError, "index.html", gin.H{
+			"Metrics": metrics,
+			"Error":   fmt.Sprintf("Failed to undo: %v", err),
+		})
+		return
+	}
+
+	metrics, err := app.fetchMetrics(c)
+	if err != nil {
+		// Error already handled in fetchMetrics
+		return
+	}
+
+	if !resp.Success {
+		c.HTML(http.StatusBadRequest, "index.html", gin.H{
+			"Metrics": metrics,
+			"Error":   resp.Message,
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "index.html", gin.H{
+		"Metrics": metrics,
+		"Message": resp.Message,
+		"Redo":    true,
+	})
+}
+
+// redo handles POST requests to apply the change undone last in this browser again
+func (app *WebApp) redo(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := app.GRPCClient.Redo(ctx, &pb.RedoRequest{ClientId: clientID(c)})
+	if err != nil {
+		log.Printf("Redo RPC failed: %v", err)
+		metrics, _ := app.fetchMetrics(c)
+		c.HTML(http.StatusInternalServerError, "index.html", gin.H{
+			"Metrics": metrics,
+			"Error":   fmt.Sprintf("Failed to redo: %v", err),
+		})
+		return
+	}
+
+	metrics, err := app.fetchMetrics(c)
+	if err != nil {
+		// Error already handled in fetchMetrics
+		return
+	}
+
+	if !resp.Success {
+		c.HTML(http.StatusBadRequest, "index.html", gin.H{
+			"Metrics": metrics,
+			"Error":   resp.Message,
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "index.html", gin.H{
+		"Metrics": metrics,
+		"Message": resp.Message,
+		"Undo":    true,
 	})
 }
 
-// fetchMetrics is a helper function to retrieve metrics via gRPC and handle errors
+// getLinks handles GET requests to display the links between metrics
+func (app *WebApp) getLinks(c *gin.Context) {
+	app.renderLinks(c, http.StatusOK, gin.H{})
+}
+
+// setLink handles POST requests to add a link or change its multiplier
+func (app *WebApp) setLink(c *gin.Context) {
+	source := c.PostForm("source")
+	target := c.PostForm("target")
+	multiplier, err := strconv.ParseFloat(strings.TrimSpace(c.PostForm("multiplier")), 64)
+	if source == "" || target == "" || err != nil {
+		app.renderLinks(c, http.StatusBadRequest, gin.H{"Error": "A source, a target and a numeric multiplier are required."})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	link := &pb.Link{Source: source, Target: target, Multiplier: multiplier}
+	resp, err := app.GRPCClient.SetLink(ctx, &pb.SetLinkRequest{Link: link})
+	if err != nil {
+		log.Printf("SetLink RPC failed: %v", err)
+		app.renderLinks(c, http.StatusInternalServerError, gin.H{"Error": fmt.Sprintf("Failed to save link: %v", err)})
+		return
+	}
+
+	if !resp.Success {
+		app.renderLinks(c, http.StatusBadRequest, gin.H{"Error": resp.Message})
+		return
+	}
+
+	app.renderLinks(c, http.StatusOK, gin.H{"Message": resp.Message})
+}
+
+// deleteLink handles POST requests to remove a link
+func (app *WebApp) deleteLink(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := app.GRPCClient.DeleteLink(ctx, &pb.DeleteLinkRequest{Source: c.PostForm("source"), Target: c.PostForm("target")})
+	if err != nil {
+		log.Printf("DeleteLink RPC failed: %v", err)
+		app.renderLinks(c, http.StatusInternalServerError, gin.H{"Error": fmt.Sprintf("Failed to delete link: %v", err)})
+		return
+	}
+
+	if !resp.Success {
+		app.renderLinks(c, http.StatusBadRequest, gin.H{"Error": resp.Message})
+		return
+	}
+
+	app.renderLinks(c, http.StatusOK, gin.H{"Message": resp.Message})
+}
+
+// renderLinks renders the links page with the current links, the metrics
+// that can be linked and the given data
+func (app *WebApp) renderLinks(c *gin.Context, status int, data gin.H) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	links, err := app.GRPCClient.GetLinks(ctx, &pb.GetLinksRequest{})
+	if err != nil {
+		log.Printf("GetLinks RPC failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "links.html", gin.H{
+			"Error": fmt.Sprintf("Failed to fetch links: %v", err),
+		})
+		return
+	}
+	metrics, err := app.GRPCClient.GetMetrics(ctx, &pb.GetMetricsRequest{})
+	if err != nil {
+		log.Printf("GetMetrics RPC failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "links.html", gin.H{
+			"Error": fmt.Sprintf("Failed to fetch metrics: %v", err),
+		})
+		return
+	}
+
+	// Derived metrics can be neither side of a link
+	var linkable []string
+	for _, m := range metrics.Metrics {
+		if m.Kind != pb.KindDerived {
+			linkable = append(linkable, m.MetricName)
+		}
+	}
+
+	data["Links"] = links.Links
+	data["Metrics"] = linkable
+	c.HTML(status, "links.html", data)
+}
+
+// renderTrash renders the trash page with the current deleted metrics and the given data
+func (app *WebApp) renderTrash(c *gin.Context, status int, data gin.H) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := app.GRPCClient.ListDeletedMetrics(ctx, &pb.ListDeletedMetricsRequest{})
+	if err != nil {
+		log.Printf("ListDeletedMetrics RPC failed: %v", err)
+		c.HTML(http.StatusInternalServerError, "trash.html", gin.H{
+			"Error": fmt.Sprintf("Failed to fetch the trash: %v", err),
+		})
+		return
+	}
+
+	data["Metrics"] = resp.Metrics
+	c.HTML(status, "trash.html", data)
+}
+
+// occurredAtFromForm converts the optional datetime-local "occurred_at" form
+// field into the RFC3339 timestamp expected by the gRPC API. The field holds
+// the browser's wall clock time, so it is read with the UTC offset the page
+// sends in "tz_offset" (minutes, as returned by getTimezoneOffset) and only
+// falls back to the server's zone when that is missing.
+func occurredAtFromForm(c *gin.Context) (string, error) {
+	value := c.PostForm("occurred_at")
+	if value == "" {
+		return "", nil
+	}
+
+	location := time.Local
+	if offset := c.PostForm("tz_offset"); offset != "" {
+		minutes, err := strconv.Atoi(offset)
+		if err != nil {
+			return "", err
+		}
+		// getTimezoneOffset is positive west of UTC
+		location = time.FixedZone("", -minutes*60)
+	}
+
+	occurredAt, err := time.ParseInLocation("2006-01-02T15:04", value, location)
+	if err != nil {
+		return "", err
+	}
+	return occurredAt.Format(time.RFC3339), nil
+}
+
+// rejectEntry checks the value an entry would leave a metric at against the
+// metric's kind and constraints before the entry is sent. next computes that value
+// from the current one. It reports whether the entry was rejected, in which
+// case the response has been written.
+func (app *WebApp) rejectEntry(c *gin.Context, metricName string, next func(value float64) float64) bool {
+	metrics, err := app.fetchMetrics(c)
+	if err != nil {
+		// Error already handled in fetchMetrics
+		return true
+	}
+
+	for _, metric := range metrics {
+		if metric.MetricName != metricName {
+			continue
+		}
+		value := next(metric.Value)
+		if metric.Kind == pb.KindCounter && value < metric.Value {
+			c.HTML(http.StatusBadRequest, "index.html", gin.H{
+				"Metrics": metrics,
+				"Error":   fmt.Sprintf("Metric %s is a counter and cannot go down until it is reset.", metricName),
+			})
+			return true
+		}
+		if err := fromPBConstraints(metric.Constraints).Check(value); err != nil {
+			c.HTML(http.StatusBadRequest, "index.html", gin.H{
+				"Metrics": metrics,
+				"Error":   fmt.Sprintf("Metric %s %v.", metricName, err),
+			})
+			return true
+		}
+	}
+	// Unknown metrics are left to the server to report
+	return false
+}
+
+// fromPBConstraints converts the constraints of a metric so entries are checked
+// by the same code the server runs; nil yields the defaults
+func fromPBConstraints(c *pb.Constraints) db.Constraints {
+	if c == nil {
+		return db.Constraints{}
+	}
+	return db.Constraints{
+		Min:           c.Min,
+		Max:           c.Max,
+		Integer:       c.IntegerOnly,
+		Step:          c.Step,
+		AllowNegative: c.AllowNegative,
+	}
+}
+
+// stars returns the ratings a rating metric offers as stars, from its minimum
+// (at least 1) to its maximum
+func stars(c *pb.Constraints) []float64 {
+	lowest, highest := math.Max(1, c.GetMin()), 5.0
+	if c.GetMax() > 0 {
+		highest = c.GetMax()
+	}
+	var ratings []float64
+	for rating := lowest; rating <= highest; rating++ {
+		ratings = append(ratings, rating)
+	}
+	return ratings
+}
+
+// goalMet reports whether value reaches an at_least goal or stays within an
+// at_most one, the way the server decides it
+func goalMet(g *pb.Goal, value float64) bool {
+	return db.Goal{Direction: db.GoalDirection(g.GetDirection()), Target: g.GetTarget()}.Met(value)
+}
+
+// percent turns the progress ratio of a goal into the width of its progress
+// bar, full once the target is reached
+func percent(ratio float64) float64 {
+	return math.Min(math.Max(ratio, 0), 1) * 100
+}
+
+// tagList splits the tags of a metric into their key=value pairs
+func tagList(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
+
+// dayStart returns the RFC3339 start of the day days after the YYYY-MM-DD
+// date in the zone of the server, or an empty string for an empty date
+func dayStart(date string, days int) (string, error) {
+	if date == "" {
+		return "", nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return "", err
+	}
+	return day.AddDate(0, 0, days).Format(time.RFC3339), nil
+}
+
+// formatTime renders an RFC3339 time of an event in the zone of the server
+func formatTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// formatEvent describes how an event changed the value of metric, such as
+// +1, -2 or = 45m, and falls back to the operation for other events
+func formatEvent(metric *pb.Metric, e *pb.MetricEvent) string {
+	switch e.Operation {
+	case "increment":
+		return "+" + pb.FormatValue(metric.Kind, e.Delta, metric.Constraints)
+	case "decrement":
+		return "-" + pb.FormatValue(metric.Kind, -e.Delta, metric.Constraints)
+	case "update":
+		return "= " + pb.FormatValue(metric.Kind, e.Value, metric.Constraints)
+	}
+	return e.Operation
+}
+
+// fetchMetrics is a helper function to retrieve metrics via gRPC and handle
+// errors. The tags query parameter filters the metrics by their tags.
 func (app *WebApp) fetchMetrics(c *gin.Context) ([]*pb.Metric, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	resp, err := app.GRPCClient.GetMetrics(ctx, &pb.GetMetricsRequest{})
+	resp, err := app.GRPCClient.GetMetrics(ctx, &pb.GetMetricsRequest{Tags: c.Query("tags")})
 	if err != nil {
 		log.Printf("GetMetrics RPC failed: %v", err)
 		c.HTML(http.StatusInternalServerError, "index.html", gin.H{
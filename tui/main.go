@@ -14,7 +14,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -23,6 +22,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/qjs/quanti-tea/server/db"
 	pb "github.com/qjs/quanti-tea/server/proto" // Adjust the import path as necessary
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -58,28 +58,91 @@ var (
 	errorMessageStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#FF0000")).
 				Render
+
+	goalMetStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#04B575")).
+			Render
+
+	goalMissedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF0000")).
+			Render
 )
 
+// goalBarWidth is the number of cells of a goal's progress bar
+const goalBarWidth = 20
+
 // =============================================================
 // Data Structures
 // =============================================================
 
 // Metric represents a single metric.
 type Metric struct {
-	MetricName string
-	Type       string
-	Unit       string
-	Value      float64
-	ResetDaily bool
+	MetricName  string
+	Type        string
+	Unit        string
+	Kind        string
+	Value       float64
+	ResetPolicy string
+	Constraints *pb.Constraints
+	Goal        *pb.Goal
+	RestDays    string
+	Tags        string
+	Formula     string
+	Streak      *pb.Streak
 }
 
 // Implement the list.Item interface for Metric
-func (m Metric) Title() string { return m.MetricName }
+func (m Metric) Title() string {
+	if m.Streak == nil || m.Streak.Longest == 0 {
+		return m.MetricName
+	}
+	return fmt.Sprintf("%s  🔥 %d (best %d)", m.MetricName, m.Streak.Current, m.Streak.Longest)
+}
 func (m Metric) Description() string {
-	return fmt.Sprintf("Type: %s | Unit: %s | Value: %.2f, Reset Daily: %t", m.Type, m.Unit, m.Value, m.ResetDaily)
+	desc := fmt.Sprintf("Type: %s | Unit: %s | %s: %s, Reset: %s", m.Type, m.Unit, m.Kind, m.formatValue(), m.ResetPolicy)
+	if m.Kind == pb.KindDerived {
+		desc = fmt.Sprintf("Type: %s | Unit: %s | %s: %s = %s", m.Type, m.Unit, m.Kind, m.formatValue(), m.Formula)
+	}
+	if m.Tags != "" {
+		desc += " | " + m.Tags
+	}
+	if m.Goal != nil {
+		desc += "\n" + m.goalBar()
+	}
+	return desc
 }
 func (m Metric) FilterValue() string { return m.MetricName }
 
+// goalBar renders the progress of the metric toward its goal in the current
+// period, green once an at_least goal is reached and red once an at_most goal
+// is broken
+func (m Metric) goalBar() string {
+	ratio := m.Goal.Ratio(m.Value)
+	filled := int(min(max(ratio, 0), 1) * goalBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", goalBarWidth-filled)
+
+	direction := strings.ReplaceAll(m.Goal.Direction, "_", " ")
+	target := pb.FormatValue(m.Kind, m.Goal.Target, m.Constraints)
+	label := fmt.Sprintf("%s %s/%s %s", bar, pb.FormatValue(m.Kind, m.Value, m.Constraints), target, direction)
+	goal := fromPBGoal(m.Goal)
+	switch {
+	case goal.Direction == db.GoalAtLeast && goal.Met(m.Value):
+		return goalMetStyle(label)
+	case goal.Direction == db.GoalAtMost && !goal.Met(m.Value):
+		return goalMissedStyle(label)
+	}
+	return label
+}
+
+// formatValue renders the value for the kind of the metric, gauges keep two
+// decimals
+func (m Metric) formatValue() string {
+	if m.Kind == pb.KindGauge || m.Kind == pb.KindCounter || m.Kind == pb.KindDerived {
+		return fmt.Sprintf("%.2f", m.Value)
+	}
+	return pb.FormatValue(m.Kind, m.Value, m.Constraints)
+}
+
 // =============================================================
 // Key Bindings
 // =============================================================
@@ -91,8 +154,14 @@ type keyMap struct {
 	Inc  key.Binding
 	Dec  key.Binding
 	Upd  key.Binding
+	Tog  key.Binding
+	Goal key.Binding
 	Ref  key.Binding
 	Del  key.Binding
+	Tra  key.Binding
+	Res  key.Binding
+	Undo key.Binding
+	Redo key.Binding
 }
 
 func newKeyMap() *keyMap {
@@ -117,6 +186,14 @@ func newKeyMap() *keyMap {
 			key.WithKeys("u"),
 			key.WithHelp("u", "update metric"),
 		),
+		Tog: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "toggle yes/no metric"),
+		),
+		Goal: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "set goal"),
+		),
 		Ref: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh metrics"),
@@ -125,6 +202,22 @@ func newKeyMap() *keyMap {
 			key.WithKeys("x"),
 			key.WithHelp("x", "delete metrics"),
 		),
+		Tra: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "toggle trash"),
+		),
+		Res: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "restore metric"),
+		),
+		Undo: key.NewBinding(
+			key.WithKeys("ctrl+z"),
+			key.WithHelp("ctrl+z", "undo"),
+		),
+		Redo: key.NewBinding(
+			key.WithKeys("ctrl+y"),
+			key.WithHelp("ctrl+y", "redo"),
+		),
 	}
 }
 
@@ -139,9 +232,12 @@ type model struct {
 	input        textinput.Model         // Text input for user input
 	status       string                  // Status message
 	client       pb.MetricsServiceClient // gRPC client
+	clientID     string                  // Id sent with every mutation, so undo only reverts those of this session
 	keys         *keyMap                 // Key bindings
 	quitting     bool                    // Quit flag
 	action       string                  // Current action: add, inc, dec, upd
+	trash        bool                    // Whether the trash is shown instead of the metrics
+	detail       *metricDetail           // Metric shown instead of the list, nil when the list is shown
 	selected     int                     // Selected metric index
 	lastUpdated  time.Time               // Last update timestamp
 	delegateKeys *delegateKeyMap
@@ -152,7 +248,7 @@ type model struct {
 // =============================================================
 
 // initialModel initializes the TUI model.
-func initialModel(client pb.MetricsServiceClient) model {
+func initialModel(client pb.MetricsServiceClient, clientID string) model {
 	// Initialize key bindings
 	keys := newKeyMap()
 	delegateKeys := newDelegateKeyMap()
@@ -178,8 +274,14 @@ func initialModel(client pb.MetricsServiceClient) model {
 			keys.Dec,
 			keys.Inc,
 			keys.Upd,
+			keys.Tog,
+			keys.Goal,
 			keys.Ref,
 			keys.Del,
+			keys.Tra,
+			keys.Res,
+			keys.Undo,
+			keys.Redo,
 		}
 	}
 
@@ -187,7 +289,7 @@ func initialModel(client pb.MetricsServiceClient) model {
 	ti := textinput.New()
 	ti.Placeholder = ""
 	ti.Focus()
-	ti.CharLimit = 32
+	ti.CharLimit = 256
 	ti.Width = 20
 
 	return model{
@@ -195,6 +297,7 @@ func initialModel(client pb.MetricsServiceClient) model {
 		input:        ti,
 		status:       "Welcome! Press 'a' to add a metric.",
 		client:       client,
+		clientID:     clientID,
 		keys:         keys,
 		quitting:     false,
 		action:       "",
@@ -258,7 +361,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.metrics = msg.metrics
 		m.list.SetItems(toListItems(m.metrics))
 		m.lastUpdated = msg.lastUpdate
-		m.status = fmt.Sprintf("Metrics updated at %s", m.lastUpdated.Format(time.RFC1123))
+		if m.trash {
+			m.status = fmt.Sprintf("Trash updated at %s", m.lastUpdated.Format(time.RFC1123))
+		} else {
+			m.status = fmt.Sprintf("Metrics updated at %s", m.lastUpdated.Format(time.RFC1123))
+		}
 		return m, nil
 
 	case errMsg:
@@ -266,26 +373,116 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status = fmt.Sprintf("Error: %v", msg.err)
 		return m, nil
 
+	case editMetricMsg:
+		// Sent by the list delegate when the edit key is pressed on a metric
+		if m.trash {
+			m.status = "Restore the metric before editing it."
+			return m, nil
+		}
+		m.action = "edit"
+		if msg.metric.Kind == pb.KindDerived {
+			m.input.Placeholder = "Name,Type,Unit,derived,Formula[,(Y/N) keep old name as alias]"
+			m.input.SetValue(fmt.Sprintf("%s,%s,%s,%s,%s", msg.metric.MetricName, msg.metric.Type, msg.metric.Unit, msg.metric.Kind, msg.metric.Formula))
+			m.input.CursorEnd()
+			m.input.Focus()
+			m.status = fmt.Sprintf("Edit '%s' as Name,Type,Unit,derived,Formula[,KeepAlias (Y/N)]:", msg.metric.MetricName)
+			return m, nil
+		}
+		m.input.Placeholder = "Name,Type,Unit,Kind,Reset[,key=value tags][,(Y/N) keep old name as alias]"
+		value := fmt.Sprintf("%s,%s,%s,%s,%s", msg.metric.MetricName, msg.metric.Type, msg.metric.Unit, msg.metric.Kind, msg.metric.ResetPolicy)
+		if msg.metric.Tags != "" {
+			value += "," + msg.metric.Tags
+		}
+		m.input.SetValue(value)
+		m.input.CursorEnd()
+		m.input.Focus()
+		m.status = fmt.Sprintf("Edit '%s' as Name,Type,Unit,Kind,Reset[,key=value tags][,KeepAlias (Y/N)]:", msg.metric.MetricName)
+		return m, nil
+
+	case showDetailMsg:
+		m.status = fmt.Sprintf("Loading '%s'...", msg.metric.MetricName)
+		return m, m.fetchDetail(msg.metric)
+
+	case *metricDetail:
+		m.detail = msg
+		m.status = "Press enter or esc to go back to the list."
+		return m, nil
+
 	case actionCompletedMsg:
 		// Update status based on the completed action
 		m.status = fmt.Sprintf("Action '%s' completed successfully.", msg.action)
 		// Trigger fetchMetrics to refresh the list
 		return m, m.fetchMetrics()
 
+	case undoMsg:
+		m.status = msg.message
+		return m, m.fetchMetrics()
+
 	case tea.KeyMsg:
+		if m.detail != nil {
+			switch {
+			case key.Matches(msg, m.keys.Quit):
+				m.quitting = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEnter || msg.Type == tea.KeyEsc:
+				m.detail = nil
+				m.status = "Refreshing metrics..."
+				return m, m.fetchMetrics()
+			}
+			return m, nil
+		}
 		if m.action == "" {
 			switch {
 			case key.Matches(msg, m.keys.Quit):
 				m.quitting = true
 				return m, tea.Quit
 
+			case key.Matches(msg, m.keys.Tra):
+				m.trash = !m.trash
+				if m.trash {
+					m.status = "Loading the trash..."
+				} else {
+					m.status = "Refreshing metrics..."
+				}
+				return m, m.fetchMetrics()
+
+			case m.trash && key.Matches(msg, m.keys.Add, m.keys.Inc, m.keys.Dec, m.keys.Upd, m.keys.Tog, m.keys.Goal):
+				m.status = "Not available in the trash, press 't' to go back to the metrics."
+				return m, nil
+
+			case m.trash && key.Matches(msg, m.keys.Del):
+				if len(m.metrics) == 0 {
+					m.status = "The trash is empty."
+					return m, nil
+				}
+				m.action = "confirm_purge"
+				selectedMetric := m.metrics[m.list.Index()]
+				m.input.Placeholder = fmt.Sprintf("Purge %s (Y/N)?", selectedMetric.MetricName)
+				m.input.SetValue("")
+				m.input.Focus()
+				m.status = fmt.Sprintf("Permanently delete '%s'? This cannot be undone. (Y/N)", selectedMetric.MetricName)
+				return m, nil
+
+			case key.Matches(msg, m.keys.Res):
+				if !m.trash {
+					m.status = "Press 't' to open the trash first."
+					return m, nil
+				}
+				if len(m.metrics) == 0 {
+					m.status = "The trash is empty."
+					return m, nil
+				}
+				selectedMetric := m.metrics[m.list.Index()]
+				m.status = fmt.Sprintf("Restoring metric '%s'...", selectedMetric.MetricName)
+				return m, m.restoreMetric(selectedMetric.MetricName)
+
 			case key.Matches(msg, m.keys.Add):
 				if m.action != "" {
 					m.status = "Finish the current action first."
 					return m, nil
 				}
 				m.action = "add"
-				m.input.Placeholder = "Name,Type,Unit,(Y/N) reset daily"
+				m.input.Placeholder = "Name,Type,Unit[,Kind: gauge, counter, boolean, rating or duration][,Reset: none, hourly, daily, weekly[:day], monthly or cron:expr][,key=value tags] or Name,Type,Unit,derived,Formula"
 				m.input.SetValue("")
 				m.input.Focus()
 				m.status = "Enter Metric Name and Type (comma separated):"
@@ -301,7 +498,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.input.Placeholder = fmt.Sprintf("Delete %s (Y/N)?", selectedMetric.MetricName)
 				m.input.SetValue("")
 				m.input.Focus()
-				m.status = fmt.Sprintf("Are you sure you want to delete '%s'? (Y/N)", selectedMetric.MetricName)
+				m.status = fmt.Sprintf("Move '%s' to the trash? (Y/N)", selectedMetric.MetricName)
 				return m, nil
 
 			case key.Matches(msg, m.keys.Inc):
@@ -309,12 +506,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.status = "No metrics available to increment."
 					return m, nil
 				}
-				m.action = "inc"
 				selectedMetric := m.metrics[m.list.Index()]
+				if selectedMetric.Kind == pb.KindDerived {
+					m.status = derivedStatus(selectedMetric)
+					return m, nil
+				}
+				m.action = "inc"
 				m.input.Placeholder = fmt.Sprintf("Increment '%s' by", selectedMetric.MetricName)
 				m.input.SetValue("")
 				m.input.Focus()
-				m.status = fmt.Sprintf("Enter increment value for '%s':", selectedMetric.MetricName)
+				m.status = fmt.Sprintf("Enter increment value for '%s', optionally with a unit like 250ml (and '@ YYYY-MM-DD [HH:MM]' to backdate and '# note'):", selectedMetric.MetricName)
 				return m, nil
 
 			case key.Matches(msg, m.keys.Dec):
@@ -322,12 +523,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.status = "No metrics available to decrement."
 					return m, nil
 				}
-				m.action = "dec"
 				selectedMetric := m.metrics[m.list.Index()]
+				if selectedMetric.Kind == pb.KindCounter {
+					m.status = fmt.Sprintf("'%s' is a counter and only goes up until it is reset.", selectedMetric.MetricName)
+					return m, nil
+				}
+				if selectedMetric.Kind == pb.KindDerived {
+					m.status = derivedStatus(selectedMetric)
+					return m, nil
+				}
+				m.action = "dec"
 				m.input.Placeholder = fmt.Sprintf("Decrement '%s' by", selectedMetric.MetricName)
 				m.input.SetValue("")
 				m.input.Focus()
-				m.status = fmt.Sprintf("Enter decrement value for '%s':", selectedMetric.MetricName)
+				m.status = fmt.Sprintf("Enter decrement value for '%s', optionally with a unit like 250ml (and '@ YYYY-MM-DD [HH:MM]' to backdate and '# note'):", selectedMetric.MetricName)
 				return m, nil
 
 			case key.Matches(msg, m.keys.Upd):
@@ -335,17 +544,64 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.status = "No metrics available to update."
 					return m, nil
 				}
-				m.action = "upd"
 				selectedMetric := m.metrics[m.list.Index()]
+				if selectedMetric.Kind == pb.KindDerived {
+					m.status = derivedStatus(selectedMetric)
+					return m, nil
+				}
+				m.action = "upd"
 				m.input.Placeholder = fmt.Sprintf("Update '%s' to", selectedMetric.MetricName)
 				m.input.SetValue("")
 				m.input.Focus()
-				m.status = fmt.Sprintf("Enter new value for '%s':", selectedMetric.MetricName)
+				m.status = fmt.Sprintf("Enter %s for '%s' (optionally '@ YYYY-MM-DD [HH:MM]' to backdate and '# note'):", entryHint(selectedMetric), selectedMetric.MetricName)
+				return m, nil
+
+			case key.Matches(msg, m.keys.Goal):
+				if len(m.metrics) == 0 {
+					m.status = "No metrics available to set a goal for."
+					return m, nil
+				}
+				m.action = "goal"
+				selectedMetric := m.metrics[m.list.Index()]
+				m.input.Placeholder = "at least 8, at most 2 or none [rest sat,sun]"
+				m.input.SetValue(goalInput(selectedMetric))
+				m.input.CursorEnd()
+				m.input.Focus()
+				m.status = fmt.Sprintf("Enter the goal for each period of '%s' as 'at least TARGET', 'at most TARGET' or 'none', optionally followed by 'rest DAYS' that do not break a streak:", selectedMetric.MetricName)
 				return m, nil
 
+			case key.Matches(msg, m.keys.Tog):
+				if len(m.metrics) == 0 {
+					m.status = "No metrics available to toggle."
+					return m, nil
+				}
+				selectedMetric := m.metrics[m.list.Index()]
+				if selectedMetric.Kind == pb.KindDerived {
+					m.status = derivedStatus(selectedMetric)
+					return m, nil
+				}
+				if selectedMetric.Kind != pb.KindBoolean {
+					m.status = fmt.Sprintf("'%s' is not a yes/no metric, press 'u' to update it.", selectedMetric.MetricName)
+					return m, nil
+				}
+				value := 1.0
+				if selectedMetric.Value != 0 {
+					value = 0
+				}
+				m.status = fmt.Sprintf("Setting '%s' to %s...", selectedMetric.MetricName, pb.FormatValue(pb.KindBoolean, value, nil))
+				return m, m.updateMetric(selectedMetric.MetricName, value, "", "", "")
+
 			case key.Matches(msg, m.keys.Ref):
 				m.status = "Refreshing metrics..."
 				return m, m.fetchMetrics()
+
+			case key.Matches(msg, m.keys.Undo):
+				m.status = "Undoing..."
+				return m, m.undo()
+
+			case key.Matches(msg, m.keys.Redo):
+				m.status = "Redoing..."
+				return m, m.redo()
 			}
 		}
 	}
@@ -369,8 +625,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				switch m.action {
 				case "add":
 					parts := strings.Split(input, ",")
-					if len(parts) < 3 || len(parts) > 4 {
-						m.status = "Invalid format. Use 'Name,Type,Unit[,ResetDaily (Y/N)]'."
+					if len(parts) < 3 {
+						m.status = "Invalid format. Use 'Name,Type,Unit[,Reset]'."
 						m.action = ""
 						m.input.Blur()
 						return m, nil
@@ -378,6 +634,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					name := strings.TrimSpace(parts[0])
 					typ := strings.TrimSpace(parts[1])
 					unit := strings.TrimSpace(parts[2])
+					kind, rest := splitKind(parts[3:])
 
 					if name == "" || typ == "" {
 						m.status = "Name and Type cannot be empty."
@@ -386,24 +643,84 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return m, nil
 					}
 
-					// Default the resetDaily flag to false
-					resetDaily := false
+					// The formula of a derived metric may contain commas
+					// (max(a, b)) and comparisons, so it takes the rest of the input
+					if kind == pb.KindDerived {
+						cmd = m.addMetric(name, typ, unit, kind, "", "", strings.Join(rest, ","))
+						break
+					}
 
-					// Check if the optional ResetDaily flag (4th parameter) is provided
-					if len(parts) == 4 {
-						resetInput := strings.TrimSpace(parts[3])
-						if resetInput == "Y" || resetInput == "y" {
-							resetDaily = true
-						} else if resetInput != "N" && resetInput != "n" {
-							m.status = "Invalid ResetDaily flag. Use 'Y' or 'N'."
-							m.action = ""
-							m.input.Blur()
-							return m, nil
+					// The optional reset policy may contain commas (cron:0 6,18 * * *),
+					// so it takes the rest of the input
+					rest, tags := splitTags(rest)
+					cmd = m.addMetric(name, typ, unit, kind, parseResetPolicy(strings.Join(rest, ",")), tags, "")
+
+				case "edit":
+					parts := strings.Split(input, ",")
+					if len(parts) < 4 {
+						m.status = "Invalid format. Use 'Name,Type,Unit,Kind,Reset[,KeepAlias (Y/N)]'."
+						m.action = ""
+						m.input.Blur()
+						return m, nil
+					}
+					for i := range parts {
+						parts[i] = strings.TrimSpace(parts[i])
+					}
+					if parts[0] == "" || parts[1] == "" {
+						m.status = "Name and Type cannot be empty."
+						m.action = ""
+						m.input.Blur()
+						return m, nil
+					}
+
+					selectedMetric := m.metrics[m.list.Index()]
+					kind, resetParts := splitKind(parts[3:])
+					if kind == pb.KindDerived {
+						// A trailing Y/N is the KeepAlias flag, everything between
+						// the kind and it is the formula
+						keepAlias := false
+						if len(resetParts) > 1 {
+							if flag, ok := parseYesNo(resetParts[len(resetParts)-1]); ok {
+								keepAlias = flag
+								resetParts = resetParts[:len(resetParts)-1]
+							}
 						}
+						cmd = m.editMetric(selectedMetric.MetricName, parts[0], parts[1], parts[2], kind, "", selectedMetric.Tags, strings.Join(resetParts, ","), keepAlias)
+						break
 					}
 
-					// Call the modified addMetric with the resetDaily flag
-					cmd = m.addMetric(name, typ, unit, resetDaily)
+					// A trailing Y/N is the KeepAlias flag, everything between the
+					// kind and it but the tags is the reset policy
+					resetParts, tags := splitTags(resetParts)
+					keepAlias := false
+					if len(resetParts) > 1 {
+						if flag, ok := parseYesNo(resetParts[len(resetParts)-1]); ok {
+							keepAlias = flag
+							resetParts = resetParts[:len(resetParts)-1]
+						}
+					}
+					reset := parseResetPolicy(strings.Join(resetParts, ","))
+					if reset == "" {
+						m.status = "Reset cannot be empty. Use 'none' to never reset."
+						m.action = ""
+						m.input.Blur()
+						return m, nil
+					}
+
+					cmd = m.editMetric(selectedMetric.MetricName, parts[0], parts[1], parts[2], kind, reset, tags, "", keepAlias)
+
+				case "goal":
+					selectedMetric := m.metrics[m.list.Index()]
+					// The server checks the rest days
+					goalPart, restDays, _ := strings.Cut(input, " rest ")
+					goal, err := parseGoal(selectedMetric.Kind, goalPart)
+					if err != nil {
+						m.status = fmt.Sprintf("Invalid goal: %v", err)
+						m.action = ""
+						m.input.Blur()
+						return m, nil
+					}
+					cmd = m.setGoal(selectedMetric.MetricName, goal, strings.TrimSpace(restDays))
 
 				case "confirm_del":
 					val := strings.TrimSpace(strings.ToLower(input))
@@ -426,38 +743,77 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.status = "Please enter 'Y' or 'N'."
 						return m, nil
 					}
+				case "confirm_purge":
+					val := strings.TrimSpace(strings.ToLower(input))
+					selectedMetric := m.metrics[m.list.Index()]
+					if val == "y" || val == "yes" {
+						cmd := m.purgeMetric(selectedMetric.MetricName)
+						m.action = ""
+						m.input.Blur()
+						m.status = fmt.Sprintf("Purging metric '%s'...", selectedMetric.MetricName)
+						return m, cmd
+					} else if val == "n" || val == "no" {
+						m.action = ""
+						m.input.Blur()
+						m.status = fmt.Sprintf("Purge of metric '%s' canceled.", selectedMetric.MetricName)
+						return m, nil
+					} else {
+						m.status = "Please enter 'Y' or 'N'."
+						return m, nil
+					}
 				case "inc":
-					value, err := strconv.ParseFloat(input, 64)
+					selectedMetric := m.metrics[m.list.Index()]
+					value, unit, occurredAt, note, err := parseEntry(selectedMetric.Kind, input)
 					if err != nil {
-						m.status = "Invalid increment value."
+						m.status = fmt.Sprintf("Invalid increment value: %v", err)
 						m.action = ""
 						m.input.Blur()
 						return m, nil
 					}
-					selectedMetric := m.metrics[m.list.Index()]
-					cmd = m.incrementMetric(selectedMetric.MetricName, value)
+					if err := checkEntry(selectedMetric, selectedMetric.Value+value, unit, occurredAt); err != nil {
+						m.status = fmt.Sprintf("Invalid increment value: %v", err)
+						m.action = ""
+						m.input.Blur()
+						return m, nil
+					}
+					cmd = m.incrementMetric(selectedMetric.MetricName, value, unit, occurredAt, note)
 
 				case "dec":
-					value, err := strconv.ParseFloat(input, 64)
+					selectedMetric := m.metrics[m.list.Index()]
+					value, unit, occurredAt, note, err := parseEntry(selectedMetric.Kind, input)
 					if err != nil {
-						m.status = "Invalid decrement value."
+						m.status = fmt.Sprintf("Invalid decrement value: %v", err)
 						m.action = ""
 						m.input.Blur()
 						return m, nil
 					}
-					selectedMetric := m.metrics[m.list.Index()]
-					cmd = m.decrementMetric(selectedMetric.MetricName, value)
+					if err := checkEntry(selectedMetric, selectedMetric.Value-value, unit, occurredAt); err != nil {
+						m.status = fmt.Sprintf("Invalid decrement value: %v", err)
+						m.action = ""
+						m.input.Blur()
+						return m, nil
+					}
+					cmd = m.decrementMetric(selectedMetric.MetricName, value, unit, occurredAt, note)
 
 				case "upd":
-					value, err := strconv.ParseFloat(input, 64)
+					selectedMetric := m.metrics[m.list.Index()]
+					value, unit, occurredAt, note, err := parseEntry(selectedMetric.Kind, input)
 					if err != nil {
-						m.status = "Invalid update value."
+						m.status = fmt.Sprintf("Invalid update value: %v", err)
 						m.action = ""
 						m.input.Blur()
 						return m, nil
 					}
-					selectedMetric := m.metrics[m.list.Index()]
-					cmd = m.updateMetric(selectedMetric.MetricName, value)
+					// A value in another unit is checked once the server converted it
+					if unit == "" {
+						if err := fromPBConstraints(selectedMetric.Constraints).Check(value); err != nil {
+							m.status = fmt.Sprintf("Invalid update value: %v", err)
+							m.action = ""
+							m.input.Blur()
+							return m, nil
+						}
+					}
+					cmd = m.updateMetric(selectedMetric.MetricName, value, unit, occurredAt, note)
 				}
 
 				m.action = ""
@@ -496,11 +852,21 @@ func (m model) View() string {
 	var sb strings.Builder
 
 	// Header
-	sb.WriteString(titleStyle.Render("Quanti-Tea Metrics\n"))
+	if m.detail != nil {
+		sb.WriteString(titleStyle.Render("Quanti-Tea " + m.detail.metric.MetricName + "\n"))
+	} else if m.trash {
+		sb.WriteString(titleStyle.Render("Quanti-Tea Trash\n"))
+	} else {
+		sb.WriteString(titleStyle.Render("Quanti-Tea Metrics\n"))
+	}
 	//sb.WriteString(titleStyle.Render("======================\n"))
 
 	// Metrics List
-	if len(m.metrics) == 0 {
+	if m.detail != nil {
+		sb.WriteString(m.detail.View())
+	} else if len(m.metrics) == 0 && m.trash {
+		sb.WriteString("The trash is empty.\n")
+	} else if len(m.metrics) == 0 {
 		sb.WriteString("No metrics available.\n")
 	} else {
 		sb.WriteString(m.list.View())
@@ -525,10 +891,242 @@ func (m model) View() string {
 	return appStyle.Render(sb.String())
 }
 
+// View renders the detail view of a metric: its description, its most
+// recent entries and its most recent notes.
+func (d *metricDetail) View() string {
+	var sb strings.Builder
+	sb.WriteString(d.metric.Description() + "\n\n")
+
+	sb.WriteString("Recent entries:\n")
+	if len(d.history) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, e := range d.history {
+		sb.WriteString("  " + formatEvent(d.metric, e) + "\n")
+	}
+
+	sb.WriteString("\nRecent notes:\n")
+	if len(d.notes) == 0 {
+		sb.WriteString("  none, add one to an entry with '# note'\n")
+	}
+	for _, e := range d.notes {
+		sb.WriteString("  " + formatEvent(d.metric, e) + "\n")
+	}
+	return sb.String()
+}
+
 // =============================================================
 // Helper Functions
 // =============================================================
 
+// formatEvent renders an event of the history of metric on one line, with
+// the linked metric it was fanned out from, its note if it has one and a mark
+// if it was undone
+func formatEvent(metric Metric, e *pb.MetricEvent) string {
+	at := e.OccurredAt
+	if t, err := time.Parse(time.RFC3339, e.OccurredAt); err == nil {
+		at = t.Local().Format("2006-01-02 15:04")
+	}
+
+	change := e.Operation
+	switch e.Operation {
+	case "increment":
+		change = "+" + pb.FormatValue(metric.Kind, e.Delta, metric.Constraints)
+	case "decrement":
+		change = "-" + pb.FormatValue(metric.Kind, -e.Delta, metric.Constraints)
+	case "update":
+		change = "= " + pb.FormatValue(metric.Kind, e.Value, metric.Constraints)
+	}
+
+	line := fmt.Sprintf("%s  %-10s", at, change)
+	if e.SourceMetric != "" {
+		line += "  from " + e.SourceMetric
+	}
+	if e.Reverted {
+		line += "  (undone)"
+	}
+	if e.Note != "" {
+		line += "  " + e.Note
+	}
+	return line
+}
+
+// entryTimeLayouts are the accepted formats for the optional backdating suffix
+var entryTimeLayouts = []string{"2006-01-02 15:04", "2006-01-02"}
+
+// parseEntry parses "VALUE [UNIT] [@ YYYY-MM-DD [HH:MM]] [# NOTE]" and returns
+// the value, its unit, which is empty for the unit of the metric, the RFC3339
+// time of the entry, which is empty when no time was given, and the note. The
+// value is read the way the kind of the metric is entered, see pb.ParseAmount.
+// A date without a time is logged at noon.
+func parseEntry(kind, input string) (value float64, unit, occurredAt, note string, err error) {
+	input, note, _ = strings.Cut(input, "#")
+	note = strings.TrimSpace(note)
+	valuePart, timePart, backdated := strings.Cut(input, "@")
+
+	value, unit, err = pb.ParseAmount(kind, valuePart)
+	if err != nil {
+		return 0, "", "", "", err
+	}
+	if !backdated {
+		return value, unit, "", note, nil
+	}
+
+	timePart = strings.TrimSpace(timePart)
+	for _, layout := range entryTimeLayouts {
+		occurredAt, err := time.ParseInLocation(layout, timePart, time.Local)
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" {
+			occurredAt = occurredAt.Add(12 * time.Hour)
+		}
+		return value, unit, occurredAt.Format(time.RFC3339), note, nil
+	}
+	return 0, "", "", "", fmt.Errorf("%q is not a date, use YYYY-MM-DD [HH:MM]", timePart)
+}
+
+// entryHint describes what to type as the new value of metric
+func entryHint(metric Metric) string {
+	switch metric.Kind {
+	case pb.KindBoolean:
+		return "yes or no"
+	case pb.KindRating:
+		return fmt.Sprintf("a rating from %g to %g, or stars like ***", metric.Constraints.GetMin(), metric.Constraints.GetMax())
+	case pb.KindDuration:
+		return "a duration like 1h30m"
+	default:
+		return "the new value, optionally with a unit like 2 cups"
+	}
+}
+
+// derivedStatus tells that a derived metric cannot be changed by hand
+func derivedStatus(metric Metric) string {
+	return fmt.Sprintf("'%s' is derived from other metrics (%s) and cannot be changed by hand.", metric.MetricName, metric.Formula)
+}
+
+// splitKind takes the optional kind off the front of the fields following
+// the unit. Kind names never start a reset policy, so anything else is left
+// for the reset.
+func splitKind(parts []string) (kind string, rest []string) {
+	if len(parts) > 0 {
+		candidate := strings.ToLower(strings.TrimSpace(parts[0]))
+		for _, k := range pb.Kinds {
+			if candidate == k {
+				return k, parts[1:]
+			}
+		}
+	}
+	return "", parts
+}
+
+// splitTags takes the key=value fields out of the fields following the unit
+// and joins them into the tags sent to the server. Reset policies never
+// contain "=", so the other fields are left in order for the reset.
+func splitTags(parts []string) (rest []string, tags string) {
+	var pairs []string
+	for _, part := range parts {
+		if strings.Contains(part, "=") {
+			pairs = append(pairs, strings.TrimSpace(part))
+		} else {
+			rest = append(rest, part)
+		}
+	}
+	return rest, strings.Join(pairs, ",")
+}
+
+// parseGoal parses "at least TARGET", "at most TARGET" or "none" into the goal
+// sent to the server, where a goal without direction removes it. The target
+// is read the way values of the kind are entered, e.g. "at least 30m" for a
+// duration.
+func parseGoal(kind, input string) (*pb.Goal, error) {
+	fields := strings.Fields(strings.ToLower(input))
+	if len(fields) == 1 && fields[0] == "none" {
+		return &pb.Goal{}, nil
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("use 'at least TARGET', 'at most TARGET' or 'none'")
+	}
+
+	target, err := pb.ParseValue(kind, fields[len(fields)-1])
+	if err != nil {
+		return nil, err
+	}
+	// The server checks the direction
+	return &pb.Goal{Direction: strings.Join(fields[:len(fields)-1], "_"), Target: target}, nil
+}
+
+// goalInput prefills the goal prompt with the current goal and rest days of metric
+func goalInput(metric Metric) string {
+	if metric.Goal == nil {
+		return ""
+	}
+	input := fmt.Sprintf("%s %s", strings.ReplaceAll(metric.Goal.Direction, "_", " "), pb.FormatValue(metric.Kind, metric.Goal.Target, nil))
+	if metric.RestDays != "" {
+		input += " rest " + metric.RestDays
+	}
+	return input
+}
+
+// parseYesNo parses a Y/N answer
+func parseYesNo(input string) (value bool, ok bool) {
+	switch strings.ToLower(input) {
+	case "y", "yes":
+		return true, true
+	case "n", "no":
+		return false, true
+	}
+	return false, false
+}
+
+// checkEntry validates the value an increment or decrement would leave metric
+// at against its kind and constraints. A backdated entry applies to an
+// archived value the TUI does not know, and an entry in another unit is
+// converted by the server, so only the server can check those.
+func checkEntry(metric Metric, value float64, unit, occurredAt string) error {
+	if unit != "" || occurredAt != "" {
+		return nil
+	}
+	if metric.Kind == pb.KindCounter && value < metric.Value {
+		return fmt.Errorf("a counter cannot go down until it is reset")
+	}
+	return fromPBConstraints(metric.Constraints).Check(value)
+}
+
+// fromPBGoal converts the goal of a metric; nil yields no goal
+func fromPBGoal(g *pb.Goal) db.Goal {
+	return db.Goal{Direction: db.GoalDirection(g.GetDirection()), Target: g.GetTarget()}
+}
+
+// fromPBConstraints converts the constraints of a metric so entries are checked
+// by the same code the server runs; nil yields the defaults
+func fromPBConstraints(c *pb.Constraints) db.Constraints {
+	if c == nil {
+		return db.Constraints{}
+	}
+	return db.Constraints{
+		Min:           c.Min,
+		Max:           c.Max,
+		Integer:       c.IntegerOnly,
+		Step:          c.Step,
+		AllowNegative: c.AllowNegative,
+	}
+}
+
+// parseResetPolicy accepts a reset policy or the Y/N reset daily flag of
+// earlier versions and returns the policy to send to the server. The server
+// validates the policy itself.
+func parseResetPolicy(input string) string {
+	input = strings.TrimSpace(input)
+	if resetDaily, ok := parseYesNo(input); ok {
+		if resetDaily {
+			return "daily"
+		}
+		return "none"
+	}
+	return input
+}
+
 // toListItems converts a slice of Metric to a slice of list.Item.
 func toListItems(metrics []Metric) []list.Item {
 	items := make([]list.Item, len(metrics))
@@ -542,25 +1140,43 @@ func toListItems(metrics []Metric) []list.Item {
 // RPC Commands
 // =============================================================
 
-// fetchMetrics retrieves the list of metrics from the server.
+// fetchMetrics retrieves the list of metrics, or of deleted metrics when the
+// trash is shown, from the server.
 func (m model) fetchMetrics() tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		resp, err := m.client.GetMetrics(ctx, &pb.GetMetricsRequest{})
-		if err != nil {
-			return errMsg{err}
+		var pbMetrics []*pb.Metric
+		if m.trash {
+			resp, err := m.client.ListDeletedMetrics(ctx, &pb.ListDeletedMetricsRequest{})
+			if err != nil {
+				return errMsg{err}
+			}
+			pbMetrics = resp.Metrics
+		} else {
+			resp, err := m.client.GetMetrics(ctx, &pb.GetMetricsRequest{})
+			if err != nil {
+				return errMsg{err}
+			}
+			pbMetrics = resp.Metrics
 		}
 
 		metrics := []Metric{}
-		for _, metric := range resp.Metrics {
+		for _, metric := range pbMetrics {
 			metrics = append(metrics, Metric{
-				MetricName: metric.MetricName,
-				Type:       metric.Type,
-				Unit:       metric.Unit,
-				Value:      metric.Value,
-				ResetDaily: metric.ResetDaily,
+				MetricName:  metric.MetricName,
+				Type:        metric.Type,
+				Unit:        metric.Unit,
+				Kind:        metric.Kind,
+				Value:       metric.Value,
+				ResetPolicy: metric.ResetPolicy,
+				Constraints: metric.Constraints,
+				Goal:        metric.Goal,
+				RestDays:    metric.RestDays,
+				Tags:        metric.Tags,
+				Formula:     metric.Formula,
+				Streak:      metric.Streak,
 			})
 		}
 
@@ -577,16 +1193,19 @@ type actionCompletedMsg struct {
 }
 
 // addMetric sends a request to add a new metric.
-func (m model) addMetric(name, typ, unit string, resetDaily bool) tea.Cmd {
+func (m model) addMetric(name, typ, unit, kind, resetPolicy, tags, formula string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
 		req := &pb.AddMetricRequest{
-			MetricName: name,
-			Type:       typ,
-			Unit:       unit,
-			ResetDaily: resetDaily,
+			MetricName:  name,
+			Type:        typ,
+			Unit:        unit,
+			Kind:        kind,
+			ResetPolicy: resetPolicy,
+			Tags:        tags,
+			Formula:     formula,
 		}
 
 		resp, err := m.client.AddMetric(ctx, req)
@@ -626,7 +1245,167 @@ func (m model) delMetric(name string) tea.Cmd {
 	}
 }
 
-func (m model) incrementMetric(name string, value float64) tea.Cmd {
+// editMetricMsg is sent by the list delegate to start editing a metric.
+type editMetricMsg struct {
+	metric Metric
+}
+
+// showDetailMsg is sent by the list delegate to open the detail view of a metric.
+type showDetailMsg struct {
+	metric Metric
+}
+
+// detailEntries is the number of recent entries and notes the detail view shows
+const detailEntries = 10
+
+// metricDetail is what the detail view shows about a metric
+type metricDetail struct {
+	metric  Metric
+	history []*pb.MetricEvent // Most recent events, newest first
+	notes   []*pb.MetricEvent // Most recent entries with a note, newest first
+}
+
+// fetchDetail retrieves the recent history and notes of a metric.
+func (m model) fetchDetail(metric Metric) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		history, err := m.client.GetMetricHistory(ctx, &pb.GetMetricHistoryRequest{MetricName: metric.MetricName, Limit: detailEntries})
+		if err != nil {
+			return errMsg{err}
+		}
+		notes, err := m.client.SearchNotes(ctx, &pb.SearchNotesRequest{MetricName: metric.MetricName, Limit: detailEntries})
+		if err != nil {
+			return errMsg{err}
+		}
+		return &metricDetail{metric: metric, history: history.Events, notes: notes.Events}
+	}
+}
+
+func (m model) editMetric(name, newName, typ, unit, kind, resetPolicy, tags, formula string, keepAlias bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req := &pb.EditMetricRequest{
+			MetricName:  name,
+			NewName:     newName,
+			Type:        typ,
+			Unit:        unit,
+			Kind:        kind,
+			ResetPolicy: resetPolicy,
+			Tags:        &tags,
+			Formula:     formula,
+			KeepAlias:   keepAlias,
+		}
+
+		resp, err := m.client.EditMetric(ctx, req)
+		if err != nil {
+			return errMsg{err}
+		}
+
+		if !resp.Success {
+			return errMsg{fmt.Errorf(resp.Message)}
+		}
+
+		return actionCompletedMsg{action: "edit"}
+	}
+}
+
+// setGoal replaces the goal and the rest days of a metric, an empty goal removes it
+func (m model) setGoal(name string, goal *pb.Goal, restDays string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := m.client.EditMetric(ctx, &pb.EditMetricRequest{MetricName: name, Goal: goal, RestDays: &restDays})
+		if err != nil {
+			return errMsg{err}
+		}
+
+		if !resp.Success {
+			return errMsg{fmt.Errorf(resp.Message)}
+		}
+
+		return actionCompletedMsg{action: "goal"}
+	}
+}
+
+func (m model) restoreMetric(name string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := m.client.RestoreMetric(ctx, &pb.RestoreMetricRequest{MetricName: name})
+		if err != nil {
+			return errMsg{err}
+		}
+
+		if !resp.Success {
+			return errMsg{fmt.Errorf(resp.Message)}
+		}
+
+		return actionCompletedMsg{action: "restore"}
+	}
+}
+
+func (m model) purgeMetric(name string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := m.client.PurgeMetric(ctx, &pb.PurgeMetricRequest{MetricName: name})
+		if err != nil {
+			return errMsg{err}
+		}
+
+		if !resp.Success {
+			return errMsg{fmt.Errorf(resp.Message)}
+		}
+
+		return actionCompletedMsg{action: "purge"}
+	}
+}
+
+// undoMsg carries the outcome of an undo or redo
+type undoMsg struct {
+	message string
+}
+
+// undo reverts the most recent mutation made in this session
+func (m model) undo() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := m.client.Undo(ctx, &pb.UndoRequest{ClientId: m.clientID})
+		if err != nil {
+			return errMsg{err}
+		}
+
+		// The message also says why nothing was undone, if so
+		return undoMsg{message: resp.Message}
+	}
+}
+
+// redo applies the most recently undone mutation of this session again
+func (m model) redo() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := m.client.Redo(ctx, &pb.RedoRequest{ClientId: m.clientID})
+		if err != nil {
+			return errMsg{err}
+		}
+
+		// The message also says why nothing was redone, if so
+		return undoMsg{message: resp.Message}
+	}
+}
+
+func (m model) incrementMetric(name string, value float64, unit, occurredAt, note string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -634,6 +1413,9 @@ func (m model) incrementMetric(name string, value float64) tea.Cmd {
 		req := &pb.IncrementMetricRequest{
 			MetricName: name,
 			Increment:  value,
+			Unit:       unit,
+			OccurredAt: occurredAt,
+			Note:       note,
 		}
 		resp, err := m.client.IncrementMetric(ctx, req)
 
@@ -649,7 +1431,7 @@ func (m model) incrementMetric(name string, value float64) tea.Cmd {
 		return actionCompletedMsg{action: "increment"}
 	}
 }
-func (m model) decrementMetric(name string, value float64) tea.Cmd {
+func (m model) decrementMetric(name string, value float64, unit, occurredAt, note string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -657,6 +1439,9 @@ func (m model) decrementMetric(name string, value float64) tea.Cmd {
 		req := &pb.DecrementMetricRequest{
 			MetricName: name,
 			Decrement:  value,
+			Unit:       unit,
+			OccurredAt: occurredAt,
+			Note:       note,
 		}
 		resp, err := m.client.DecrementMetric(ctx, req)
 
@@ -674,7 +1459,7 @@ func (m model) decrementMetric(name string, value float64) tea.Cmd {
 }
 
 // updateMetric sends a request to update a metric's value.
-func (m model) updateMetric(name string, newValue float64) tea.Cmd {
+func (m model) updateMetric(name string, newValue float64, unit, occurredAt, note string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -682,6 +1467,9 @@ func (m model) updateMetric(name string, newValue float64) tea.Cmd {
 		req := &pb.UpdateMetricRequest{
 			MetricName: name,
 			NewValue:   newValue,
+			Unit:       unit,
+			OccurredAt: occurredAt,
+			Note:       note,
 		}
 
 		resp, err := m.client.UpdateMetric(ctx, req)
@@ -700,14 +1488,12 @@ func (m model) updateMetric(name string, newValue float64) tea.Cmd {
 
 func newItemDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 	d := list.NewDefaultDelegate()
+	d.SetHeight(3) // Title, description and the progress toward a goal
 	d.Styles.SelectedTitle = selectedTitleStyle
 	d.Styles.SelectedDesc = selectedDescStyle
 	d.UpdateFunc = func(msg tea.Msg, m *list.Model) tea.Cmd {
-		var title string
-
-		if i, ok := m.SelectedItem().(Metric); ok {
-			title = i.Title()
-		} else {
+		selected, ok := m.SelectedItem().(Metric)
+		if !ok {
 			return nil
 		}
 
@@ -715,14 +1501,17 @@ func newItemDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 		case tea.KeyMsg:
 			switch {
 			case key.Matches(msg, keys.choose):
-				return m.NewStatusMessage(statusMessageStyle("You chose " + title))
+				return func() tea.Msg { return showDetailMsg{metric: selected} }
+
+			case key.Matches(msg, keys.edit):
+				return func() tea.Msg { return editMetricMsg{metric: selected} }
 			}
 		}
 
 		return nil
 	}
 
-	help := []key.Binding{keys.choose}
+	help := []key.Binding{keys.choose, keys.edit}
 
 	d.ShortHelpFunc = func() []key.Binding {
 		return help
@@ -737,6 +1526,7 @@ func newItemDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 
 type delegateKeyMap struct {
 	choose key.Binding
+	edit   key.Binding
 }
 
 // Additional short help entries. This satisfies the help.KeyMap interface and
@@ -744,6 +1534,7 @@ type delegateKeyMap struct {
 func (d delegateKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		d.choose,
+		d.edit,
 	}
 }
 
@@ -753,6 +1544,7 @@ func (d delegateKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{
 			d.choose,
+			d.edit,
 		},
 	}
 }
@@ -761,7 +1553,11 @@ func newDelegateKeyMap() *delegateKeyMap {
 	return &delegateKeyMap{
 		choose: key.NewBinding(
 			key.WithKeys("enter"),
-			key.WithHelp("enter", "choose"),
+			key.WithHelp("enter", "details"),
+		),
+		edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit metric"),
 		),
 	}
 }
@@ -779,8 +1575,12 @@ func main() {
 	log.SetOutput(os.Stdout)
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	// Establish gRPC connection
-	conn, err := grpc.NewClient(*serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// Establish gRPC connection, tagging every call with the id of this session
+	clientID := pb.NewClientID()
+	conn, err := grpc.NewClient(*serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(pb.WithClientID(ctx, clientID), method, req, reply, cc, opts...)
+		}))
 	if err != nil {
 		log.Fatalf("Failed to connect to gRPC server: %v", err)
 	}
@@ -789,7 +1589,7 @@ func main() {
 	// Initialize gRPC client
 	client := pb.NewMetricsServiceClient(conn)
 
-	if _, err := tea.NewProgram(initialModel(client), tea.WithAltScreen()).Run(); err != nil {
+	if _, err := tea.NewProgram(initialModel(client, clientID), tea.WithAltScreen()).Run(); err != nil {
 		fmt.Println("Error running program:", err)
 		os.Exit(1)
 	}